@@ -0,0 +1,29 @@
+package main
+
+import (
+	"centralized/node"
+	"common/cluster"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Once every node has released the lock, the coordinator reports no holders and an empty queue with Node.GetState
+func TestGetStateAfterTheWorkload(t *testing.T) {
+	c := cluster.Start(t, 3, "-requests", "3")
+
+	// The coordinator records the holder of the lock
+	waitForState(c, 0, func(state node.State) bool { return len(state.Holders) == 1 })
+	c.WaitFor(0, "Time taken", time.Minute)
+
+	for i := 0; i < 3; i++ {
+		state := waitForState(c, i, func(state node.State) bool { return !state.Waiting && !state.InCS })
+		if state.ID != i || state.Coordinator != 0 || len(state.Network) != 2 || state.Clock == 0 {
+			t.Fatalf("unexpected state of node %d: %+v", i, state)
+		}
+	}
+	state := waitForState(c, 0, func(state node.State) bool { return len(state.Holders) == 0 && !slices.Contains(state.Finished, false) })
+	if len(state.Queue) != 0 || len(state.Finished) != 3 {
+		t.Fatalf("unexpected state of the coordinator: %+v", state)
+	}
+}
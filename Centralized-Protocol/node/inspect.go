@@ -0,0 +1,37 @@
+package node
+
+import (
+	"common/inspect"
+	"fmt"
+)
+
+// Function to fill in the part of the state shown by the console and the dashboard
+func summarize(state *State) {
+	state.Phase = phase(state.InCS, state.Waiting)
+	state.Status = statusLines(*state)
+	state.Queues = queueLines(*state)
+}
+
+// Function to describe the request of a node
+func statusLines(state State) []string {
+	return []string{fmt.Sprintf("Coordinator: node %d, requesting: %t, waiting for the lock: %t", state.Coordinator, state.Request, state.Waiting)}
+}
+
+// Function to describe the queue and the holders of the lock kept by the coordinator
+func queueLines(state State) []string {
+	if state.Coordinator != state.ID {
+		return []string{fmt.Sprintf("The queue is kept by the coordinator node %d", state.Coordinator)}
+	}
+	return []string{fmt.Sprintf("Queue: %v, holders: %v of %d", state.Queue, state.Holders, state.K)}
+}
+
+// Function to get what the node is doing with the critical section
+func phase(inCS bool, requesting bool) inspect.Phase {
+	switch {
+	case inCS:
+		return inspect.IN_CS
+	case requesting:
+		return inspect.REQUESTING
+	}
+	return inspect.IDLE
+}
@@ -0,0 +1,13 @@
+package node
+
+type Message struct {
+	Type string // Request, Grant, Release
+	ID int
+	IP string
+	ReqTime int
+	Clock int
+	NumRequests int
+	K int // Number of nodes that can hold the lock at the same time
+	Coordinator int // Coordinator known to the sender, set on DENY replies and when asking if a coordinator crashed
+	Secret string // Shared secret of the admin service, only set on admin calls
+}
@@ -0,0 +1,573 @@
+package node
+
+import (
+	"common/address"
+	"common/admin"
+	"common/inspect"
+	"common/safety"
+	"container/heap"
+	"fmt"
+	"net/rpc"
+	"os"
+	"sync"
+	"time"
+)
+
+type Node struct {
+	ID int
+	IP string
+	Bind string // Address the node listens on, IP if empty
+	Bootstrap string // Address of the bootstrap node
+	Coordinator int // ID of the node acting as the lock server
+	Holders map[int]bool // Nodes holding the lock. Only used by the coordinator
+	K int // Number of nodes that can hold the lock at the same time
+	Queue *PriorityQueue // Nodes waiting for the lock in order of arrival. Only used by the coordinator
+	Recovering bool // If the coordinator is rebuilding its state after taking over from a crashed coordinator
+	Waiting bool // If the node has requested the lock and has not been granted it yet
+	InCS bool // If the node is executing the critical section
+	Clock int // Lamport clock
+	Request bool // If the node should request for the critical section
+	ReqTime int // Request timestamp
+	Network map[int]string // Map of the network
+	Finished []bool // If the node has finished
+	Safety safety.Checker // Only used by the bootstrap node
+	Hold chan bool // Closed to leave a critical section requested from the console, nil for the requests of the workload
+	Holding bool // If the node waits in the critical section until it is released from the console
+	Gate inspect.Gate // Holds back the messages from other nodes while the node is paused and keeps track of the messages in flight
+	Lock sync.Mutex
+}
+
+const (
+	HEARTBEAT = 2 * time.Second // Interval at which a waiting node checks that the coordinator is alive
+	PINGS = 3 // Number of pings the coordinator has to miss before the node suspects that it crashed
+	MAX_ATTEMPTS = 10 // Number of times a message is sent to the coordinator before the node gives up
+	ACK = "ACK"
+	DENY = "DENY"
+	REQUEST = "REQUEST"
+	GRANT = "GRANT"
+	RELEASE = "RELEASE"
+	HELD = "HELD"
+	WAITING = "WAITING"
+	IDLE = "IDLE"
+)
+
+// Delays that slow the protocol down so that its output can be followed
+var (
+	MessageDelay = 1 * time.Second // Time a node waits before handling a message
+	CSDuration = 2 * time.Second // Time a node spends in the critical section
+)
+
+// Function to start the RPC server
+func (n *Node) StartRPCServer() {
+	rpc.Register(n)
+	rpc.RegisterName(admin.SERVICE, &Admin{n: n})
+
+	listener, err := address.Listen(n.listenAddress())
+	if err != nil {
+		fmt.Printf("[NODE-%d] could not start listening: %s\n", n.ID, err)
+		os.Exit(1)
+	}
+	defer listener.Close()
+
+	fmt.Printf("[NODE-%d] Node is running on %s\n", n.ID, n.IP)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			fmt.Printf("[NODE-%d] accept error: %s\n", n.ID, err)
+			continue
+		}
+		go rpc.ServeConn(conn)
+	}
+}
+
+func (n *Node) startRequestProcess(message Message, reply *Message) error {
+	if n.Request {
+		n.Lock.Lock()
+		n.Clock++
+		n.ReqTime = n.Clock
+		n.Waiting = true
+		n.Lock.Unlock()
+
+		go n.monitorCoordinator()
+		n.sendToCoordinator(REQUEST)
+	}
+	return nil
+}
+
+// Dummy critical section function
+func (n *Node) CriticalSection() {
+	// Notify Bootstrap node when entering the critical section
+	_, err := CallByRPC(n.Bootstrap, "Node.NotifyEntered", Message{ID: n.ID})
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+	}
+
+	// Simulate entering the critical section
+	fmt.Printf("[NODE-%d] Entering the critical section\n", n.ID)
+	n.stayInCriticalSection()
+	fmt.Printf("[NODE-%d] Completed the critical section\n", n.ID)
+
+	// Notify Bootstrap node when the critical section is completed
+	n.Clock++
+	_, err = CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID})
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+	}
+}
+
+func (n *Node) NotifyFinished(message Message, reply *Message) error {
+	n.notifyExited(message.ID)
+	if message.ID < len(n.Finished) {
+		n.Finished[message.ID] = true
+	}
+	return nil
+}
+
+// Handle the different types of messages
+func (n *Node) ReceiveMessage(message Message, reply *Message) error {
+	n.Gate.Deliver(message.ID, message.Type, "", MessageDelay)
+	n.Lock.Lock()
+	n.Clock = max(n.Clock, message.Clock) + 1
+	n.Lock.Unlock()
+
+	switch message.Type {
+	case REQUEST:
+		if !n.isCoordinator() {
+			fmt.Printf("[NODE-%d] Received a request from node %d but the node is not the coordinator\n", n.ID, message.ID)
+			*reply = n.deny()
+			return nil
+		}
+
+		n.Lock.Lock()
+		if !n.Holders[message.ID] && !n.queued(message.ID) {
+			heap.Push(n.Queue, Item{ID: message.ID, TimeStamp: n.Clock})
+		}
+		fmt.Printf("[NODE-%d] Received a request from node %d. New Queue: %v\n", n.ID, message.ID, n.Queue)
+		n.grantNext()
+		n.Lock.Unlock()
+
+	case RELEASE:
+		if !n.isCoordinator() {
+			*reply = n.deny()
+			return nil
+		}
+
+		n.Lock.Lock()
+		fmt.Printf("[NODE-%d] Received a release from node %d\n", n.ID, message.ID)
+		delete(n.Holders, message.ID)
+		n.grantNext()
+		n.Lock.Unlock()
+
+	case GRANT:
+		n.Lock.Lock()
+		if !n.Waiting {
+			n.Lock.Unlock()
+			break
+		}
+		fmt.Printf("[NODE-%d] Received a grant from node %d\n", n.ID, message.ID)
+		n.Waiting = false
+		n.InCS = true
+		n.Lock.Unlock()
+
+		go n.useCriticalSection()
+	}
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Function to deny a message meant for the coordinator, telling the sender which node the node thinks is the coordinator
+func (n *Node) deny() Message {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	return Message{Type: DENY, ID: n.ID, Coordinator: n.Coordinator}
+}
+
+// Function to grant the lock to the nodes at the head of the queue while fewer than k nodes hold it.
+// Must be called with the lock held.
+func (n *Node) grantNext() {
+	for !n.Recovering && len(n.Holders) < n.K && n.Queue.Len() > 0 {
+		holder := heap.Pop(n.Queue).(Item).ID
+		n.Holders[holder] = true
+		n.Clock++
+		message := Message{Type: GRANT, ID: n.ID, IP: n.IP, Clock: n.Clock}
+		fmt.Printf("[NODE-%d] Granting the lock to node %d. New Queue: %v\n", n.ID, holder, n.Queue)
+
+		go func() {
+			_, err := CallByRPC(n.address(holder), "Node.ReceiveMessage", message)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while sending a grant to node %d: %s\n", n.ID, holder, err)
+
+				// The holder is unreachable, so the lock is given to the next node instead
+				n.Lock.Lock()
+				if n.Holders[holder] {
+					delete(n.Holders, holder)
+					n.grantNext()
+				}
+				n.Lock.Unlock()
+			}
+		}()
+	}
+}
+
+// Function to execute the critical section and release the lock afterwards
+func (n *Node) useCriticalSection() {
+	n.CriticalSection()
+
+	n.Lock.Lock()
+	n.InCS = false
+	n.Request = false
+	n.Lock.Unlock()
+
+	n.sendToCoordinator(RELEASE)
+}
+
+// Function to send a request or a release to the coordinator, electing a new coordinator whenever a majority of
+// the nodes confirms that the current one crashed. A node that denies the message points the node to the coordinator
+// it knows of. The node gives up after MAX_ATTEMPTS failed attempts instead of retrying forever.
+func (n *Node) sendToCoordinator(msgType string) bool {
+	for attempt := 1; ; attempt++ {
+		n.Lock.Lock()
+		coordinator := n.Coordinator
+		n.Clock++
+		message := Message{Type: msgType, ID: n.ID, IP: n.IP, ReqTime: n.ReqTime, Clock: n.Clock}
+		n.Lock.Unlock()
+
+		fmt.Printf("[NODE-%d] Sending a %s message to the coordinator node %d\n", n.ID, msgType, coordinator)
+		reply, err := CallByRPC(n.address(coordinator), "Node.ReceiveMessage", message)
+		if err == nil && reply.Type != DENY {
+			return true
+		}
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while sending a %s message to the coordinator node %d: %s\n", n.ID, msgType, coordinator, err)
+		} else {
+			fmt.Printf("[NODE-%d] Coordinator node %d denied the %s message, it knows node %d as the coordinator\n", n.ID, coordinator, msgType, reply.Coordinator)
+		}
+		if attempt == MAX_ATTEMPTS {
+			n.giveUp(msgType)
+			return false
+		}
+
+		if err != nil {
+			if n.coordinatorFailed(coordinator) {
+				continue
+			}
+		} else if reply.Coordinator != coordinator && reply.Coordinator != n.ID {
+			// Follow the coordinator that the denying node knows of, which is checked on the next attempt
+			n.Lock.Lock()
+			if n.Coordinator == coordinator {
+				n.Coordinator = reply.Coordinator
+			}
+			n.Lock.Unlock()
+			continue
+		}
+		// The coordinator is not confirmed to have crashed or has not finished taking over yet
+		time.Sleep(HEARTBEAT)
+	}
+}
+
+// Function to give up on a message that could not be delivered to the coordinator
+func (n *Node) giveUp(msgType string) {
+	fmt.Printf("[NODE-%d] Gave up on the %s message after %d attempts to reach the coordinator\n", n.ID, msgType, MAX_ATTEMPTS)
+	if msgType != REQUEST {
+		return
+	}
+
+	n.Lock.Lock()
+	n.Waiting = false
+	n.Request = false
+	n.Lock.Unlock()
+
+	// Let the bootstrap node finish the run without waiting for the critical section of the node
+	_, err := CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID})
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+	}
+}
+
+// Function to periodically check that the coordinator is alive while waiting for a grant
+func (n *Node) monitorCoordinator() {
+	for {
+		time.Sleep(HEARTBEAT)
+
+		n.Lock.Lock()
+		waiting := n.Waiting
+		coordinator := n.Coordinator
+		n.Lock.Unlock()
+
+		if !waiting {
+			return
+		}
+		if coordinator == n.ID {
+			continue
+		}
+
+		_, err := CallByRPC(n.address(coordinator), "Node.Ping", Message{ID: n.ID})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Coordinator node %d is not responding: %s\n", n.ID, coordinator, err)
+			if n.coordinatorFailed(coordinator) {
+				n.sendToCoordinator(REQUEST)
+			}
+		}
+	}
+}
+
+// Function to elect a new coordinator after the given coordinator crashed. Every node elects the live node
+// with the lowest ID, so all the nodes agree on the new coordinator without exchanging messages. Returns false
+// if the crash could not be confirmed, in which case the coordinator is kept.
+func (n *Node) coordinatorFailed(crashed int) bool {
+	n.Lock.Lock()
+	handled := n.Coordinator != crashed
+	n.Lock.Unlock()
+	if handled {
+		return true
+	}
+	if !n.confirmCrash(crashed) {
+		return false
+	}
+
+	n.Lock.Lock()
+	if n.Coordinator != crashed {
+		// The crash was handled while it was being confirmed
+		n.Lock.Unlock()
+		return true
+	}
+
+	delete(n.Network, crashed)
+	n.Coordinator = n.ID
+	for i := range n.Network {
+		n.Coordinator = min(n.Coordinator, i)
+	}
+	fmt.Printf("[NODE-%d] Coordinator node %d crashed. Node %d is the new coordinator\n", n.ID, crashed, n.Coordinator)
+
+	if n.Coordinator != n.ID {
+		n.Lock.Unlock()
+		return true
+	}
+	n.Recovering = true
+	n.Holders = make(map[int]bool)
+	n.Queue = &PriorityQueue{}
+	n.Lock.Unlock()
+
+	n.recoverState()
+	return true
+}
+
+// Function to confirm that a coordinator crashed. A single failed call is not enough, since a slow coordinator
+// would be replaced while it still grants the lock. The coordinator has to miss PINGS pings in a row, and a
+// majority of all the nodes, counting the coordinator, must be unable to reach it either.
+func (n *Node) confirmCrash(crashed int) bool {
+	for i := 0; i < PINGS; i++ {
+		_, err := CallByRPC(n.address(crashed), "Node.Ping", Message{ID: n.ID})
+		if err == nil {
+			fmt.Printf("[NODE-%d] Coordinator node %d answered a ping, so it has not crashed\n", n.ID, crashed)
+			return false
+		}
+		time.Sleep(HEARTBEAT / PINGS)
+	}
+
+	n.Lock.Lock()
+	network := make(map[int]string)
+	for i := range n.Network {
+		network[i] = n.Network[i]
+	}
+	n.Lock.Unlock()
+
+	votes := 1
+	for i := range network {
+		if i == crashed {
+			continue
+		}
+		reply, err := CallByRPC(network[i], "Node.Suspect", Message{ID: n.ID, Coordinator: crashed})
+		if err == nil && reply.Type == ACK {
+			votes++
+		}
+	}
+	nodes := len(network) + 1
+	if votes <= nodes / 2 {
+		fmt.Printf("[NODE-%d] Only %d of the %d nodes cannot reach coordinator node %d, so it is kept\n", n.ID, votes, nodes, crashed)
+		return false
+	}
+	return true
+}
+
+// Function to tell a node that suspects the coordinator crashed whether this node cannot reach it either
+func (n *Node) Suspect(message Message, reply *Message) error {
+	n.Lock.Lock()
+	_, known := n.Network[message.Coordinator]
+	n.Lock.Unlock()
+
+	*reply = Message{Type: ACK, ID: n.ID}
+	if message.Coordinator == n.ID {
+		*reply = Message{Type: DENY, ID: n.ID}
+	} else if known {
+		_, err := CallByRPC(n.address(message.Coordinator), "Node.Ping", Message{ID: n.ID})
+		if err == nil {
+			*reply = Message{Type: DENY, ID: n.ID}
+		}
+	}
+	return nil
+}
+
+// Function to rebuild the holder and the queue of the lock from the state reported by every node
+func (n *Node) recoverState() {
+	n.Lock.Lock()
+	network := make(map[int]string)
+	for i := range n.Network {
+		network[i] = n.Network[i]
+	}
+	n.Lock.Unlock()
+
+	states := map[int]Message{}
+	for i := range network {
+		state, err := CallByRPC(network[i], "Node.ReportState", Message{ID: n.ID})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while recovering the state of node %d: %s\n", n.ID, i, err)
+			continue
+		}
+		states[i] = state
+	}
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	states[n.ID] = n.state()
+	for i, state := range states {
+		switch state.Type {
+		case HELD:
+			n.Holders[i] = true
+		case WAITING:
+			if !n.queued(i) {
+				heap.Push(n.Queue, Item{ID: i, TimeStamp: state.ReqTime})
+			}
+		}
+	}
+	n.Recovering = false
+	fmt.Printf("[NODE-%d] Recovered the lock state. Holders: %v, Queue: %v\n", n.ID, n.Holders, n.Queue)
+	n.grantNext()
+}
+
+// Function to report whether the node holds, waits for or does not need the lock
+func (n *Node) ReportState(message Message, reply *Message) error {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	// Only a newly elected coordinator asks for the state, so the old coordinator has crashed
+	if n.Coordinator != message.ID && n.Coordinator != n.ID {
+		fmt.Printf("[NODE-%d] Node %d took over as the coordinator\n", n.ID, message.ID)
+		delete(n.Network, n.Coordinator)
+		n.Coordinator = message.ID
+	}
+	*reply = n.state()
+	return nil
+}
+
+// Must be called with the lock held
+func (n *Node) state() Message {
+	state := Message{Type: IDLE, ID: n.ID, ReqTime: n.ReqTime}
+	if n.InCS {
+		state.Type = HELD
+	} else if n.Waiting {
+		state.Type = WAITING
+	}
+	return state
+}
+
+// Function to check if the node is the coordinator, taking over if the current coordinator has crashed
+func (n *Node) isCoordinator() bool {
+	n.Lock.Lock()
+	coordinator := n.Coordinator
+	n.Lock.Unlock()
+
+	if coordinator == n.ID {
+		return true
+	}
+
+	_, err := CallByRPC(n.address(coordinator), "Node.Ping", Message{ID: n.ID})
+	if err != nil {
+		n.coordinatorFailed(coordinator)
+	}
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	return n.Coordinator == n.ID && !n.Recovering
+}
+
+// Heartbeat used to detect a crashed coordinator
+func (n *Node) Ping(message Message, reply *Message) error {
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Must be called with the lock held
+func (n *Node) queued(ID int) bool {
+	for _, item := range *n.Queue {
+		if item.ID == ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Function to get the address of a node, including the node itself
+func (n *Node) address(ID int) string {
+	if ID == n.ID {
+		return n.IP
+	}
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	return n.Network[ID]
+}
+
+// Function to add a new node to the network
+func (n *Node) addNode(message Message, reply *Message) error {
+	n.Lock.Lock()
+	n.Network[message.ID] = message.IP
+	n.Lock.Unlock()
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Function to decide whether the node requests for the critical section or not
+func (n *Node) setRequesting(message Message, reply *Message) error {
+	n.Lock.Lock()
+	n.K = max(message.K, 1)
+	n.Lock.Unlock()
+
+	n.Request = n.ID < message.NumRequests
+	if n.Request {
+		fmt.Printf("[NODE-%d] Node will request for the critical section\n", n.ID)
+	} else {
+		fmt.Printf("[NODE-%d] Node will not request for the critical section\n", n.ID)
+	}
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Utility function to call RPC methods
+func CallByRPC(IP string, method string, message Message) (Message, error) {
+	if admin.IsAdmin(method) {
+		message.Secret = admin.Secret
+	}
+	conn, err := address.Dial(IP, 0)
+	if err != nil {
+		return Message{}, fmt.Errorf("error in dialing: %s", err)
+	}
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	var reply Message
+	err = client.Call(method, message, &reply)
+	if err != nil {
+		return Message{}, fmt.Errorf("error in calling %s: %s", method, err)
+	}
+	return reply, nil
+}
+
+// Function to get the address the node listens on
+func (n *Node) listenAddress() string {
+	if n.Bind != "" {
+		return n.Bind
+	}
+	return n.IP
+}
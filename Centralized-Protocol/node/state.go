@@ -0,0 +1,51 @@
+package node
+
+import (
+	"common/inspect"
+	"maps"
+	"slices"
+	"sort"
+)
+
+// What a node knows about the network and the critical section, returned by Node.GetState
+type State struct {
+	inspect.Snapshot // Part of the state shown by the console and the dashboard, the same in every protocol
+	Bootstrap string // Address of the bootstrap node
+	Request bool // If the node should request for the critical section
+	ReqTime int // Request timestamp
+	Waiting bool // If the node has requested the lock and has not been granted it yet
+	InCS bool // If the node is executing the critical section
+	Coordinator int // ID of the node acting as the lock server
+	K int // Number of nodes that can hold the lock at the same time
+	Recovering bool // If the coordinator is rebuilding its state after taking over from a crashed coordinator
+	Queue []Item // Nodes waiting for the lock in order of priority. Only known to the coordinator
+	Holders []int // Nodes holding the lock, in order of their IDs. Only known to the coordinator
+	Network map[int]string // Addresses of the other nodes
+	Finished []bool // If each node has finished, as far as the node knows
+}
+
+// Function to report what the node knows about the network and the critical section. It does not change the
+// node, so tests, dashboards and the console can call it at any time.
+func (n *Node) GetState(message Message, reply *State) error {
+	*reply = n.nodeState()
+	return nil
+}
+
+// Function to take a copy of the state of the node. Not to be confused with state, which only reports the lock
+// state of the node to a new coordinator.
+func (n *Node) nodeState() State {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	state := State{Snapshot: inspect.Snapshot{ID: n.ID, IP: n.IP, Protocol: "centralized", Clock: n.Clock, Paused: n.Gate.Paused(), InFlight: n.Gate.Messages()}, Bootstrap: n.Bootstrap, Request: n.Request, ReqTime: n.ReqTime, Waiting: n.Waiting, InCS: n.InCS, Coordinator: n.Coordinator, K: n.K, Recovering: n.Recovering, Queue: []Item{}, Holders: []int{}, Network: maps.Clone(n.Network), Finished: slices.Clone(n.Finished)}
+	if n.Coordinator == n.ID {
+		state.Queue = append(state.Queue, *n.Queue...)
+		sort.Slice(state.Queue, func(i, j int) bool { return PriorityQueue(state.Queue).Less(i, j) })
+		for ID := range n.Holders {
+			state.Holders = append(state.Holders, ID)
+		}
+		slices.Sort(state.Holders)
+	}
+	summarize(&state)
+	return state
+}
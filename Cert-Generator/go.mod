@@ -0,0 +1,3 @@
+module cert_generator
+
+go 1.23.2
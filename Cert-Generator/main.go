@@ -0,0 +1,217 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Prefix of the common name in the certificate of a node, followed by its ID
+const CERT_PREFIX = "node-"
+
+// Roles of the certificates, stored as their organizational unit. The coordinator and the operator tools may
+// make admin calls to the nodes.
+const (
+	COORDINATOR_ROLE = "coordinator"
+	NODE_ROLE = "node"
+	OPERATOR_ROLE = "operator"
+	STORAGE_ROLE = "storage"
+)
+
+// How long the generated certificates are valid
+const VALIDITY = 365 * 24 * time.Hour
+
+// Tool to generate a local CA and a certificate for every node, for mutual TLS between the nodes
+func main() {
+	numNodes := flag.Int("nodes", 10, "number of node certificates to generate, for the IDs 0 to nodes - 1")
+	dir := flag.String("out", "certs", "directory to write the certificates and keys to")
+	hosts := flag.String("hosts", "127.0.0.1,::1,localhost", "comma-separated IP addresses and host names the nodes run on")
+	flag.Parse()
+
+	err := os.MkdirAll(*dir, 0700)
+	if err != nil {
+		fmt.Printf("Error occurred while creating %s: %s\n", *dir, err)
+		os.Exit(1)
+	}
+
+	caCert, caKey, err := loadCA(*dir)
+	if os.IsNotExist(err) {
+		caCert, caKey, err = createCA(*dir)
+		if err == nil {
+			fmt.Printf("Created a new CA in %s\n", filepath.Join(*dir, "ca.crt"))
+		}
+	}
+	if err != nil {
+		fmt.Printf("Error occurred while setting up the CA: %s\n", err)
+		os.Exit(1)
+	}
+
+	for i := 0; i < *numNodes; i++ {
+		name := fmt.Sprintf("%s%d", CERT_PREFIX, i)
+		role := NODE_ROLE
+		if i == 0 {
+			// The bootstrap node coordinates the other nodes
+			role = COORDINATOR_ROLE
+		}
+		err := createNodeCert(*dir, name, role, strings.Split(*hosts, ","), caCert, caKey)
+		if err != nil {
+			fmt.Printf("Error occurred while creating the certificate of %s: %s\n", name, err)
+			os.Exit(1)
+		}
+		err = createSigningKey(*dir, name)
+		if err != nil {
+			fmt.Printf("Error occurred while creating the signing key of %s: %s\n", name, err)
+			os.Exit(1)
+		}
+	}
+	err = createNodeCert(*dir, OPERATOR_ROLE, OPERATOR_ROLE, nil, caCert, caKey)
+	if err != nil {
+		fmt.Printf("Error occurred while creating the operator certificate: %s\n", err)
+		os.Exit(1)
+	}
+	// The fenced storage server accepts the connections of the nodes on the same hosts
+	err = createNodeCert(*dir, STORAGE_ROLE, STORAGE_ROLE, strings.Split(*hosts, ","), caCert, caKey)
+	if err != nil {
+		fmt.Printf("Error occurred while creating the storage server certificate: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Created the certificates and signing keys of %d nodes, an operator certificate and a storage server certificate in %s\n", *numNodes, *dir)
+}
+
+// Function to load the CA of an earlier run, so that certificates for more nodes can be added to the network
+func loadCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPEM, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(filepath.Join(dir, "ca.key"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return nil, nil, fmt.Errorf("invalid CA in %s", dir)
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// Function to create a self-signed CA
+func createCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject: pkix.Name{CommonName: "DS-Assignment-2 local CA"},
+		NotBefore: time.Now().Add(-time.Minute),
+		NotAfter: time.Now().Add(VALIDITY),
+		KeyUsage: x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, writeKeyPair(dir, "ca", der, key)
+}
+
+// Function to create the certificate of a node or an operator tool, valid both for accepting and for making connections
+func createNodeCert(dir string, name string, role string, hosts []string, caCert *x509.Certificate, caKey *ecdsa.PrivateKey) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber(),
+		Subject: pkix.Name{CommonName: name, OrganizationalUnit: []string{role}},
+		NotBefore: time.Now().Add(-time.Minute),
+		NotAfter: time.Now().Add(VALIDITY),
+		KeyUsage: x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, host := range hosts {
+		host = strings.TrimSpace(host)
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else if host != "" {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return err
+	}
+	return writeKeyPair(dir, name, der, key)
+}
+
+// Function to write a certificate and its private key as PEM files
+func writeKeyPair(dir string, name string, der []byte, key *ecdsa.PrivateKey) error {
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(dir, name + ".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name + ".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+}
+
+// Function to create the Ed25519 key a node signs its messages with, used by the voting protocol
+func createSigningKey(dir string, name string) error {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(dir, name + ".ed25519.pub"), pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0644)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, name + ".ed25519"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0600)
+}
+
+// Function to get a random serial number for a certificate
+func serialNumber() *big.Int {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		panic(err)
+	}
+	return serial
+}
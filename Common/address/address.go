@@ -0,0 +1,91 @@
+// Package address derives the addresses of the nodes from one configured address, so that the nodes, their HTTP
+// API and the launcher agree on them without assuming that the network runs on 127.0.0.1.
+package address
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Address of the bootstrap node when none is configured. Node i listens on the same host with the port increased by i.
+const DEFAULT = "127.0.0.1:8000"
+
+// Function to get the address a number of ports after a host:port address, like the address of node i from the
+// address of node 0. Unix domain sockets have no port, so their addresses cannot be derived.
+func Offset(addr string, offset int) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("cannot derive an address from %s: %s", addr, err)
+	}
+	number, err := strconv.Atoi(port)
+	if err != nil {
+		return "", fmt.Errorf("cannot derive an address from %s: invalid port %q", addr, port)
+	}
+	return WithPort(host, number + offset), nil
+}
+
+// Function to get the address of a port on a host
+func WithPort(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// Prefix of the address of a node that listens on a Unix domain socket, followed by the path of the socket
+const UNIX_PREFIX = "unix:"
+
+// Function to get the network and the address to dial for the address of a node
+func Split(addr string) (string, string) {
+	if path, ok := strings.CutPrefix(addr, UNIX_PREFIX); ok {
+		return "unix", path
+	}
+	return "tcp", addr
+}
+
+// Function to listen on the address of a node. A socket left behind by an earlier run at the same path is removed first.
+func Listen(addr string) (net.Listener, error) {
+	network, path := Split(addr)
+	if network == "unix" {
+		info, err := os.Lstat(path)
+		if err == nil && info.Mode() & os.ModeSocket != 0 {
+			os.Remove(path)
+		}
+	}
+	return net.Listen(network, path)
+}
+
+// Function to connect to the address of a node, without a time limit if the timeout is 0
+func Dial(addr string, timeout time.Duration) (net.Conn, error) {
+	network, path := Split(addr)
+	return net.DialTimeout(network, path, timeout)
+}
+
+// Function to check if an address lets a node listen on every interface, so that the other nodes cannot use it
+func IsWildcard(addr string) bool {
+	network, path := Split(addr)
+	if network == "unix" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(path)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return host == "" || (ip != nil && ip.IsUnspecified())
+}
+
+// Function to get the name the certificate of a node must be valid for. Nodes on Unix domain sockets present
+// the certificate for localhost.
+func ServerName(addr string) string {
+	network, path := Split(addr)
+	if network == "unix" {
+		return "localhost"
+	}
+	host, _, err := net.SplitHostPort(path)
+	if err != nil {
+		return path
+	}
+	return host
+}
@@ -0,0 +1,35 @@
+package address
+
+import "testing"
+
+func TestOffset(t *testing.T) {
+	cases := map[string]string{
+		"127.0.0.1:8000": "127.0.0.1:8003",
+		"node.example:7000": "node.example:7003",
+		"[::1]:8000": "[::1]:8003",
+		":8000": ":8003",
+	}
+	for addr, want := range cases {
+		got, err := Offset(addr, 3)
+		if err != nil || got != want {
+			t.Errorf("Offset(%q, 3) = %q, %v, want %q", addr, got, err, want)
+		}
+	}
+	if _, err := Offset("unix:/tmp/node-0.sock", 3); err == nil {
+		t.Errorf("Offset of a Unix domain socket did not fail")
+	}
+}
+
+func TestServerName(t *testing.T) {
+	cases := map[string]string{
+		"127.0.0.1:8000": "127.0.0.1",
+		"node.example:7000": "node.example",
+		"[::1]:8000": "::1",
+		"unix:/tmp/node-0.sock": "localhost",
+	}
+	for addr, want := range cases {
+		if got := ServerName(addr); got != want {
+			t.Errorf("ServerName(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
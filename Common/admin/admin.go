@@ -0,0 +1,94 @@
+// Package admin holds the access rules of the admin service every protocol registers next to its protocol messages:
+// the shared secret the calls must carry and the roles in the certificates of the callers that may make them.
+package admin
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Name of the RPC service of the administrative calls, which change the membership and the workload of the nodes
+// or let operator tools watch and pause them
+const SERVICE = "Admin"
+
+// Prefix of the common name in the certificate of a node, followed by its ID
+const CERT_PREFIX = "node-"
+
+// Roles in the certificates of the nodes and the operator tools
+const (
+	COORDINATOR_ROLE = "coordinator" // Bootstrap node
+	NODE_ROLE = "node"
+	OPERATOR_ROLE = "operator"
+)
+
+// Shared secret every admin call must carry, set from the ADMIN_SECRET environment variable. Empty to not check it.
+var Secret string
+
+// Identity of a caller, taken from its verified certificate
+type Identity struct {
+	ID int // -1 for an operator tool
+	Role string // Organizational unit of the certificate
+}
+
+func (p Identity) String() string {
+	if p.Role == OPERATOR_ROLE {
+		return "an operator"
+	}
+	return fmt.Sprintf("node %d", p.ID)
+}
+
+// Function to get the identity of a node or an operator tool from its verified certificate. Certificates
+// without a role are treated as the certificates of plain nodes.
+func Peer(state tls.ConnectionState) (Identity, error) {
+	if len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return Identity{}, fmt.Errorf("no verified certificate")
+	}
+	subject := state.VerifiedChains[0][0].Subject
+	role := NODE_ROLE
+	if len(subject.OrganizationalUnit) > 0 {
+		role = subject.OrganizationalUnit[0]
+	}
+	if role == OPERATOR_ROLE {
+		return Identity{ID: -1, Role: role}, nil
+	}
+	ID, err := strconv.Atoi(strings.TrimPrefix(subject.CommonName, CERT_PREFIX))
+	if err != nil || !strings.HasPrefix(subject.CommonName, CERT_PREFIX) {
+		return Identity{}, fmt.Errorf("%q is not the certificate of a node", subject.CommonName)
+	}
+	return Identity{ID: ID, Role: role}, nil
+}
+
+// Function to check the shared secret of an admin call handled by the node with the given ID
+func CheckSecret(ID int, method string, secret string) error {
+	if Secret == "" {
+		return nil
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(Secret)) != 1 {
+		fmt.Printf("[NODE-%d] Rejected %s.%s: wrong admin secret\n", ID, SERVICE, method)
+		return fmt.Errorf("%s.%s needs the admin secret", SERVICE, method)
+	}
+	return nil
+}
+
+// Function to check that the role in the certificate of the caller allows an admin call. The self-service calls
+// are the ones a node with the node role may make about itself, so that it can join the network, and ID is the
+// node the call is about.
+func CheckRole(peer Identity, method string, ID int, selfService map[string]bool) error {
+	switch {
+	case peer.Role == COORDINATOR_ROLE || peer.Role == OPERATOR_ROLE:
+		return nil
+	case selfService[method] && peer.Role == NODE_ROLE && ID == peer.ID:
+		return nil
+	case selfService[method]:
+		return fmt.Errorf("node %d may only call %s about itself", peer.ID, method)
+	}
+	return fmt.Errorf("%s may only be called by the coordinator or an operator, not by a caller with the %q role", method, peer.Role)
+}
+
+// Function to check if a method belongs to the admin service
+func IsAdmin(method string) bool {
+	return strings.HasPrefix(method, SERVICE + ".")
+}
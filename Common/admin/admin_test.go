@@ -0,0 +1,45 @@
+package admin
+
+import "testing"
+
+func TestCheckRole(t *testing.T) {
+	selfService := map[string]bool{"Admin.AddNode": true}
+	cases := []struct {
+		peer Identity
+		method string
+		ID int
+		allowed bool
+	}{
+		{Identity{ID: 0, Role: COORDINATOR_ROLE}, "Admin.SetRequesting", 3, true},
+		{Identity{ID: -1, Role: OPERATOR_ROLE}, "Admin.Pause", 3, true},
+		{Identity{ID: 3, Role: NODE_ROLE}, "Admin.AddNode", 3, true},
+		{Identity{ID: 3, Role: NODE_ROLE}, "Admin.AddNode", 4, false},
+		{Identity{ID: 3, Role: NODE_ROLE}, "Admin.SetRequesting", 3, false},
+	}
+	for _, c := range cases {
+		err := CheckRole(c.peer, c.method, c.ID, selfService)
+		if (err == nil) != c.allowed {
+			t.Errorf("CheckRole(%v, %s, %d) = %v, want allowed %v", c.peer, c.method, c.ID, err, c.allowed)
+		}
+	}
+}
+
+func TestCheckSecret(t *testing.T) {
+	if err := CheckSecret(0, "Pause", ""); err != nil {
+		t.Errorf("a call without a secret was rejected while no secret is set: %v", err)
+	}
+	Secret = "change-me"
+	defer func() { Secret = "" }()
+	if err := CheckSecret(0, "Pause", "change-me"); err != nil {
+		t.Errorf("a call with the right secret was rejected: %v", err)
+	}
+	if err := CheckSecret(0, "Pause", "wrong"); err == nil {
+		t.Errorf("a call with the wrong secret was accepted")
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	if !IsAdmin("Admin.AddNode") || IsAdmin("Node.ReceiveMessage") || IsAdmin("Administrator.AddNode") {
+		t.Errorf("IsAdmin does not match the methods of the admin service only")
+	}
+}
@@ -0,0 +1,194 @@
+// Package cluster runs a network of nodes for the tests of the protocols. Every node serves the default RPC server,
+// so each node runs as its own process, built once per test binary by Main.
+package cluster
+
+import (
+	"bytes"
+	"common/address"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/rpc"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Binary of the protocol under test, built by Main
+var binary string
+
+// Function to run the tests of a protocol. The protocol in the current directory is built once before the tests
+// and removed after them. The TestMain of every protocol calls it.
+func Main(m *testing.M) {
+	dir, err := os.MkdirTemp("", "cluster-test")
+	if err != nil {
+		fmt.Println("Error occurred while building the node:", err)
+		os.Exit(1)
+	}
+	binary, err = Build(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		fmt.Println("Error occurred while building the node:", err)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// Function to build the protocol in the current directory into a directory, with extra flags for go build such
+// as -race. Returns the path of the binary.
+func Build(dir string, flags ...string) (string, error) {
+	path := filepath.Join(dir, "node")
+	args := append([]string{"build", "-o", path}, flags...)
+	out, err := exec.Command("go", append(args, ".")...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%s\n%s", err, out)
+	}
+	return path, nil
+}
+
+// Network of nodes run as separate processes. The nodes listen on Unix domain sockets in a temporary directory,
+// so tests never clash over ports.
+type Cluster struct {
+	T *testing.T
+	Dir string
+	Peers []string // Address of every node by ID
+	Logs []string // File the output of every node is written to, by ID
+	consoles []io.WriteCloser
+	processes []*exec.Cmd
+}
+
+// Function to start n nodes of the protocol under test with a fixed peer list. Node 0 is the bootstrap node and
+// gets the extra arguments. The nodes inherit the environment of the test, so a test can pick the transport with t.Setenv.
+func Start(t *testing.T, n int, args ...string) *Cluster {
+	return StartBinary(t, binary, n, args...)
+}
+
+// Function to start n nodes of a binary built with Build, like Start
+func StartBinary(t *testing.T, binary string, n int, args ...string) *Cluster {
+	c := &Cluster{T: t, Dir: t.TempDir()}
+	pairs := []string{}
+	for i := 0; i < n; i++ {
+		c.Peers = append(c.Peers, address.UNIX_PREFIX + filepath.Join(c.Dir, fmt.Sprintf("node-%d.sock", i)))
+		c.Logs = append(c.Logs, filepath.Join(c.Dir, fmt.Sprintf("node-%d.log", i)))
+		pairs = append(pairs, fmt.Sprintf("%d=%s", i, c.Peers[i]))
+	}
+
+	// The bootstrap node starts last so that it finds every other node listening
+	for i := n - 1; i >= 0; i-- {
+		nodeArgs := []string{"-id", fmt.Sprint(i), "-peers", strings.Join(pairs, ","), "-log", c.Logs[i], "-console", "-message-delay", "50ms", "-cs-duration", "300ms"}
+		if i == 0 {
+			nodeArgs = append(nodeArgs, "-nodes", fmt.Sprint(n))
+			nodeArgs = append(nodeArgs, args...)
+		}
+		cmd := exec.Command(binary, nodeArgs...)
+		cmd.Dir = c.Dir
+		console, err := cmd.StdinPipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = cmd.Start()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.consoles = append([]io.WriteCloser{console}, c.consoles...)
+		c.processes = append([]*exec.Cmd{cmd}, c.processes...)
+		t.Cleanup(func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		})
+	}
+	return c
+}
+
+// Function to wait until the output of a node contains a text, failing the test after the timeout
+func (c *Cluster) WaitFor(i int, text string, timeout time.Duration) string {
+	deadline := time.Now().Add(timeout)
+	for {
+		out, _ := os.ReadFile(c.Logs[i])
+		if strings.Contains(string(out), text) {
+			return string(out)
+		}
+		if time.Now().After(deadline) {
+			c.T.Fatalf("node %d did not print %q within %v, its output was:\n%s", i, text, timeout, out)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// Function to crash a node, so that the other nodes stop hearing from it
+func (c *Cluster) Kill(i int) {
+	c.processes[i].Process.Kill()
+}
+
+// Function to type a command into the console of a node
+func (c *Cluster) Command(i int, line string) {
+	_, err := io.WriteString(c.consoles[i], line + "\n")
+	if err != nil {
+		c.T.Fatal(err)
+	}
+}
+
+// Function to call a method of a node over net/rpc, which every node serves whatever its transport
+func (c *Cluster) Call(i int, method string, args any, reply any) error {
+	conn, err := address.Dial(c.Peers[i], time.Second)
+	if err != nil {
+		return err
+	}
+	client := rpc.NewClient(conn)
+	defer client.Close()
+	return client.Call(method, args, reply)
+}
+
+// Function to send a request with a JSON body to the HTTP API of a node and decode the JSON answer. The nodes only
+// serve the API if the test sets HTTP_PORT, and then listen on a socket next to their own. Returns the HTTP status.
+func (c *Cluster) HTTP(i int, method string, path string, body any, reply any) int {
+	socket := strings.TrimPrefix(c.Peers[i], address.UNIX_PREFIX) + ".http"
+	client := http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+	}}}
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.T.Fatal(err)
+	}
+	req, err := http.NewRequest(method, "http://node" + path, bytes.NewReader(data))
+	if err != nil {
+		c.T.Fatal(err)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		c.T.Fatalf("HTTP API of node %d: %s", i, err)
+	}
+	defer res.Body.Close()
+	if reply != nil {
+		err = json.NewDecoder(res.Body).Decode(reply)
+		if err != nil {
+			c.T.Fatalf("answer of the HTTP API of node %d: %s", i, err)
+		}
+	}
+	return res.StatusCode
+}
+
+// Function to wait until the state a node reports with Node.GetState satisfies a condition, failing the test
+// after the timeout. The arguments are the message type of the protocol.
+func WaitForState[State any](c *Cluster, i int, args any, timeout time.Duration, condition func(State) bool) State {
+	deadline := time.Now().Add(timeout)
+	for {
+		var state State
+		err := c.Call(i, "Node.GetState", args, &state)
+		if err == nil && condition(state) {
+			return state
+		}
+		if time.Now().After(deadline) {
+			c.T.Fatalf("the state of node %d did not change as expected within %v: %+v (%v)", i, timeout, state, err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
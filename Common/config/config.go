@@ -0,0 +1,423 @@
+// Package config reads the configuration of a node from an optional YAML or JSON file, the command-line flags and
+// the environment. It is shared by every protocol, and each protocol only offers the flags it takes.
+package config
+
+import (
+	"common/address"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Roles of a node in the run
+const (
+	BOOTSTRAP_ROLE = "bootstrap" // Hands out the workload, starts the requests and collects the results
+	NODE_ROLE = "node" // Only takes part in the protocol
+)
+
+// Flags only some protocols take. A protocol lists the ones it takes when it loads its configuration, and the
+// others are not offered.
+const (
+	K = "k"
+	READERS = "readers"
+	POLICY = "policy"
+	SESSIONS = "sessions"
+	TIMEOUT = "timeout"
+	TTL = "ttl"
+	RESOURCES = "resources"
+	PER_REQUEST = "per-request"
+	TRACE_DIR = "trace-dir"
+	TLS_DIR = "tls-dir"
+	SIGNING_DIR = "signing-dir"
+	TRANSPORT = "transport"
+	STORAGE = "storage"
+	HTTP_PORT = "http-port"
+	LEASE_SKEW_MARGIN = "lease-skew-margin"
+)
+
+// Environment variables the settings were read from before they had flags. They still set the defaults, so that
+// the launcher and the tests can pass them on to every node.
+var environment = map[string]string{
+	TRACE_DIR: "TRACE_DIR",
+	TLS_DIR: "TLS_DIR",
+	SIGNING_DIR: "SIGNING_DIR",
+	TRANSPORT: "TRANSPORT",
+	STORAGE: "STORAGE_ADDR",
+	HTTP_PORT: "HTTP_PORT",
+	LEASE_SKEW_MARGIN: "LEASE_SKEW_MARGIN",
+	"admin-secret": "ADMIN_SECRET",
+}
+
+// Configuration of a node, read from an optional YAML or JSON file and from the command-line flags, which take
+// precedence over the file. Without a file or flags, the node behaves as before: it takes the next free ID from
+// nodes-list.json, and the bootstrap node asks for the workload on the console.
+type Config struct {
+	ID int `json:"id" yaml:"id"` // -1 to take the next free ID from the registry
+	Listen string `json:"listen" yaml:"listen"` // Address the node listens on, the advertised address by default
+	Advertise string `json:"advertise" yaml:"advertise"` // Address the other nodes reach the node on, stored in the registry
+	Bootstrap string `json:"bootstrap" yaml:"bootstrap"` // Address of the bootstrap node, taken from the peers or the registry by default
+	Registry string `json:"registry" yaml:"registry"` // File the nodes register their addresses in
+	Peers Peers `json:"peers" yaml:"peers"` // Fixed addresses of all the nodes by ID, used instead of the registry
+	Role string `json:"role" yaml:"role"` // bootstrap or node, by default the node with ID 0 is the bootstrap node
+	Nodes int `json:"nodes" yaml:"nodes"` // Number of nodes the bootstrap node waits for before it starts the workload on its own, 0 to ask on the console
+	Workload Workload `json:"workload" yaml:"workload"`
+	MessageDelay Duration `json:"message_delay" yaml:"message_delay"` // Time a node waits before handling a message
+	CSDuration Duration `json:"cs_duration" yaml:"cs_duration"` // Time a node spends in the critical section
+	Log string `json:"log" yaml:"log"` // File the output is written to, the console by default
+	Console bool `json:"console" yaml:"console"` // If the node reads operator commands from the console once it is running
+	TraceDir string `json:"trace_dir" yaml:"trace_dir"` // Directory the trace is written to, no trace by default
+	TLSDir string `json:"tls_dir" yaml:"tls_dir"` // Directory with the CA and the certificate of the node, mutual TLS is off by default
+	SigningDir string `json:"signing_dir" yaml:"signing_dir"` // Directory with the keys the votes are signed with, no signatures by default
+	Transport string `json:"transport" yaml:"transport"` // rpc or grpc, the same on every node
+	AdminSecret string `json:"admin_secret" yaml:"admin_secret"` // Secret the admin calls must carry, none by default
+	Storage string `json:"storage" yaml:"storage"` // Address of the fenced storage server written to in the critical section, empty for none
+	HTTPPort int `json:"http_port" yaml:"http_port"` // Node i serves the HTTP API on port HTTPPort + i, 0 to turn it off
+	SkewMargin Duration `json:"lease_skew_margin" yaml:"lease_skew_margin"` // Extra time a node waits before treating the lease of another node as expired
+}
+
+// Workload the bootstrap node hands out when it does not ask for it on the console
+type Workload struct {
+	Requests int `json:"requests" yaml:"requests"` // Number of nodes that request for the critical section
+	K int `json:"k" yaml:"k"`
+	Readers int `json:"readers" yaml:"readers"`
+	Policy string `json:"policy" yaml:"policy"` // fair or writer
+	Sessions int `json:"sessions" yaml:"sessions"`
+	Timeout int `json:"timeout" yaml:"timeout"`
+	TTL int `json:"ttl" yaml:"ttl"`
+	Resources int `json:"resources" yaml:"resources"`
+	PerRequest int `json:"per_request" yaml:"per_request"`
+}
+
+// Function to get the configuration of a node that was given nothing, with the delays of the protocol
+func Default(messageDelay time.Duration, csDuration time.Duration) Config {
+	return Config{
+		ID: -1,
+		Registry: "nodes-list.json",
+		Peers: Peers{},
+		Workload: Workload{K: 1, Policy: "fair", Resources: 1, PerRequest: 1},
+		MessageDelay: Duration(messageDelay),
+		CSDuration: Duration(csDuration),
+	}
+}
+
+// Function to read the configuration of the node from the environment, the file given with -config and the
+// command-line arguments, each taking precedence over the one before. Only the optional flags in options are offered.
+func Load(args []string, defaults Config, options ...string) (Config, error) {
+	offered := map[string]bool{}
+	for _, option := range options {
+		offered[option] = true
+	}
+
+	cfg := defaults
+	envFlags, _ := cfg.flagSet(offered)
+	for name, variable := range environment {
+		value := os.Getenv(variable)
+		if value == "" || envFlags.Lookup(name) == nil {
+			continue
+		}
+		err := envFlags.Set(name, value)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid %s %q: %s", variable, value, err)
+		}
+	}
+	defaults = cfg
+
+	flags, path := cfg.flagSet(offered)
+	err := flags.Parse(args)
+	if err != nil {
+		return cfg, err
+	}
+
+	if *path != "" {
+		fileCfg := defaults
+		err = fileCfg.readFile(*path)
+		if err != nil {
+			return cfg, err
+		}
+		// Flags take precedence over the file
+		fileFlags, _ := fileCfg.flagSet(offered)
+		flags.Visit(func(f *flag.Flag) {
+			if f.Name != "config" {
+				fileFlags.Set(f.Name, f.Value.String())
+			}
+		})
+		cfg = fileCfg
+	}
+
+	if cfg.Role != "" && cfg.Role != BOOTSTRAP_ROLE && cfg.Role != NODE_ROLE {
+		return cfg, fmt.Errorf("unknown role %q, expected %s or %s", cfg.Role, BOOTSTRAP_ROLE, NODE_ROLE)
+	}
+	if len(cfg.Peers) > 0 && cfg.ID < 0 {
+		return cfg, fmt.Errorf("a node with a fixed peer list needs an ID")
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) flagSet(offered map[string]bool) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	path := flags.String("config", "", "YAML or JSON file to read the configuration from")
+	flags.IntVar(&cfg.ID, "id", cfg.ID, "ID of the node, -1 to take the next free ID from the registry")
+	flags.StringVar(&cfg.Listen, "listen", cfg.Listen, "address to listen on, host:port or unix:<path>, the advertised address by default")
+	flags.StringVar(&cfg.Advertise, "advertise", cfg.Advertise, "address the other nodes reach the node on, the listen address or the address of the bootstrap node with the port increased by the ID by default")
+	flags.StringVar(&cfg.Bootstrap, "bootstrap", cfg.Bootstrap, "address of the bootstrap node, taken from the peers or the registry by default")
+	flags.StringVar(&cfg.Registry, "registry", cfg.Registry, "file the nodes register their addresses in")
+	flags.Var(&cfg.Peers, "peers", "fixed addresses of all the nodes, as ID=address pairs separated by commas, instead of the registry")
+	flags.StringVar(&cfg.Role, "role", cfg.Role, "bootstrap or node, by default the node with ID 0 is the bootstrap node")
+	flags.IntVar(&cfg.Nodes, "nodes", cfg.Nodes, "bootstrap node: number of nodes to wait for before starting the workload, 0 to ask on the console")
+	flags.IntVar(&cfg.Workload.Requests, "requests", cfg.Workload.Requests, "bootstrap node: number of nodes that request for the critical section")
+	if offered[K] {
+		flags.IntVar(&cfg.Workload.K, K, cfg.Workload.K, "bootstrap node: number of nodes that can be in the critical section at the same time")
+	}
+	if offered[READERS] {
+		flags.IntVar(&cfg.Workload.Readers, READERS, cfg.Workload.Readers, "bootstrap node: number of the requesting nodes that request for shared (read) access")
+	}
+	if offered[POLICY] {
+		flags.StringVar(&cfg.Workload.Policy, POLICY, cfg.Workload.Policy, "bootstrap node: fair or writer")
+	}
+	if offered[SESSIONS] {
+		flags.IntVar(&cfg.Workload.Sessions, SESSIONS, cfg.Workload.Sessions, "bootstrap node: number of sessions the requesting nodes are split into, 0 for none")
+	}
+	if offered[TIMEOUT] {
+		flags.IntVar(&cfg.Workload.Timeout, TIMEOUT, cfg.Workload.Timeout, "bootstrap node: seconds a node waits for the lock before giving up, 0 to wait forever")
+	}
+	if offered[TTL] {
+		flags.IntVar(&cfg.Workload.TTL, TTL, cfg.Workload.TTL, "bootstrap node: seconds a lease lasts without being renewed, 0 to turn leases off")
+	}
+	if offered[RESOURCES] {
+		flags.IntVar(&cfg.Workload.Resources, RESOURCES, cfg.Workload.Resources, "bootstrap node: number of resources the requesting nodes are spread over")
+	}
+	if offered[PER_REQUEST] {
+		flags.IntVar(&cfg.Workload.PerRequest, PER_REQUEST, cfg.Workload.PerRequest, "bootstrap node: number of resources each request takes at once")
+	}
+	flags.TextVar(&cfg.MessageDelay, "message-delay", cfg.MessageDelay, "time a node waits before handling a message")
+	flags.TextVar(&cfg.CSDuration, "cs-duration", cfg.CSDuration, "time a node spends in the critical section")
+	flags.StringVar(&cfg.Log, "log", cfg.Log, "file to write the output to, the console by default")
+	flags.BoolVar(&cfg.Console, "console", cfg.Console, "read operator commands such as request, release and status from the console")
+	if offered[TRACE_DIR] {
+		flags.StringVar(&cfg.TraceDir, TRACE_DIR, cfg.TraceDir, "directory to write the trace to, none by default (TRACE_DIR)")
+	}
+	if offered[TLS_DIR] {
+		flags.StringVar(&cfg.TLSDir, TLS_DIR, cfg.TLSDir, "directory with ca.crt and the certificate of the node, for mutual TLS (TLS_DIR)")
+	}
+	if offered[SIGNING_DIR] {
+		flags.StringVar(&cfg.SigningDir, SIGNING_DIR, cfg.SigningDir, "directory with the keys the votes are signed with, no signatures by default (SIGNING_DIR)")
+	}
+	if offered[TRANSPORT] {
+		flags.StringVar(&cfg.Transport, TRANSPORT, cfg.Transport, "rpc or grpc, the same on every node (TRANSPORT)")
+	}
+	flags.StringVar(&cfg.AdminSecret, "admin-secret", cfg.AdminSecret, "secret the admin calls must carry, none by default (ADMIN_SECRET)")
+	if offered[STORAGE] {
+		flags.StringVar(&cfg.Storage, STORAGE, cfg.Storage, "address of the fenced storage server writers write to, none by default (STORAGE_ADDR)")
+	}
+	if offered[HTTP_PORT] {
+		flags.IntVar(&cfg.HTTPPort, HTTP_PORT, cfg.HTTPPort, "node i serves the HTTP API on port http-port + i, 0 to turn it off (HTTP_PORT)")
+	}
+	if offered[LEASE_SKEW_MARGIN] {
+		flags.TextVar(&cfg.SkewMargin, LEASE_SKEW_MARGIN, cfg.SkewMargin, "extra time a node waits before treating the lease of another node as expired (LEASE_SKEW_MARGIN)")
+	}
+	return flags, path
+}
+
+// Function to read a configuration file, as JSON if its name ends in .json and as YAML otherwise
+func (cfg *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(data, cfg)
+	} else {
+		err = yaml.Unmarshal(data, cfg)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration in %s: %s", path, err)
+	}
+	return nil
+}
+
+// Function to send the output of the node to the log file, if one is configured
+func (cfg Config) OpenLog() error {
+	if cfg.Log == "" {
+		return nil
+	}
+	file, err := os.OpenFile(cfg.Log, os.O_CREATE | os.O_WRONLY | os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	os.Stdout = file
+	os.Stderr = file
+	return nil
+}
+
+// Function to get the nodes already in the network, from the fixed peer list or from the registry
+func (cfg Config) NodesList() map[int]string {
+	if len(cfg.Peers) > 0 {
+		nodesList := make(map[int]string)
+		for ID, IP := range cfg.Peers {
+			nodesList[ID] = IP
+		}
+		return nodesList
+	}
+	return ReadNodesList(cfg.Registry)
+}
+
+// Function to decide the ID of the node, the address the other nodes reach it on and the address it listens on
+func (cfg Config) Identity(nodesList map[int]string) (int, string, string, error) {
+	ID := cfg.ID
+	if ID < 0 {
+		ID = len(nodesList)
+	}
+	if cfg.Role == BOOTSTRAP_ROLE && ID != 0 {
+		return ID, "", "", fmt.Errorf("the bootstrap node must have ID 0, not %d", ID)
+	}
+	IP := cfg.Advertise
+	if IP == "" {
+		IP = cfg.Peers[ID]
+	}
+	if IP == "" && cfg.Listen != "" {
+		if address.IsWildcard(cfg.Listen) {
+			return ID, "", "", fmt.Errorf("listening on %s needs an address to advertise", cfg.Listen)
+		}
+		IP = cfg.Listen
+	}
+	if IP == "" {
+		// Node i listens on the host of the bootstrap node with the port increased by i
+		var err error
+		IP, err = address.Offset(cfg.bootstrapBase(nodesList), ID)
+		if err != nil {
+			return ID, "", "", fmt.Errorf("%s, so the node needs an address to advertise", err)
+		}
+	}
+	return ID, IP, cfg.Listen, nil
+}
+
+// Function to find the address of the bootstrap node, which is the node with ID 0
+func (cfg Config) BootstrapAddress(ID int, IP string, nodesList map[int]string) string {
+	if cfg.Bootstrap == "" && ID == 0 {
+		return IP
+	}
+	return cfg.bootstrapBase(nodesList)
+}
+
+// Function to get the configured address of the bootstrap node, from the flags, the peers or the registry, and
+// the default address if none is configured
+func (cfg Config) bootstrapBase(nodesList map[int]string) string {
+	if cfg.Bootstrap != "" {
+		return cfg.Bootstrap
+	}
+	if addr, ok := nodesList[0]; ok {
+		return addr
+	}
+	return address.DEFAULT
+}
+
+// Function to check if the node hands out the workload and starts the requests
+func (cfg Config) IsBootstrap(ID int) bool {
+	return cfg.Role == BOOTSTRAP_ROLE || (cfg.Role == "" && ID == 0)
+}
+
+// Function to wait until the configured number of nodes is in the network and listening
+func (cfg Config) WaitForNodes() map[int]string {
+	for {
+		nodesList := cfg.NodesList()
+		if len(nodesList) >= cfg.Nodes && reachable(nodesList) {
+			return nodesList
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func reachable(nodesList map[int]string) bool {
+	for _, IP := range nodesList {
+		conn, err := address.Dial(IP, time.Second)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+	}
+	return true
+}
+
+// Function to read the nodes in the network from the registry
+func ReadNodesList(path string) map[int]string {
+	jsonFile, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("Error opening %s file: %s\n", path, err)
+	}
+	defer jsonFile.Close()
+
+	byteValue, _ := ioutil.ReadAll(jsonFile)
+
+	var nodesList map[int]string
+
+	json.Unmarshal(byteValue, &nodesList) // Puts the byte value into the nodesList map
+
+	return nodesList
+}
+
+// Function to write the nodes in the network to the registry
+func WriteNodesList(path string, nodesList map[int]string) error {
+	jsonData, err := json.Marshal(nodesList)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, jsonData, os.ModePerm)
+}
+
+// Fixed addresses of the nodes by ID, written as ID=address pairs separated by commas on the command line
+type Peers map[int]string
+
+func (p *Peers) String() string {
+	IDs := []int{}
+	for ID := range *p {
+		IDs = append(IDs, ID)
+	}
+	sort.Ints(IDs)
+	pairs := []string{}
+	for _, ID := range IDs {
+		pairs = append(pairs, fmt.Sprintf("%d=%s", ID, (*p)[ID]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (p *Peers) Set(value string) error {
+	peers := Peers{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		ID, IP, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		number, err := strconv.Atoi(ID)
+		if !ok || err != nil || IP == "" {
+			return fmt.Errorf("%q is not an ID=address pair", pair)
+		}
+		peers[number] = IP
+	}
+	*p = peers
+	return nil
+}
+
+// Duration written like 1s or 500ms in the configuration
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(value)
+	return nil
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadPrecedence(t *testing.T) {
+	t.Setenv("TRANSPORT", "grpc")
+	t.Setenv("HTTP_PORT", "9000")
+	t.Setenv("TLS_DIR", "/env/certs")
+	path := filepath.Join(t.TempDir(), "node.yaml")
+	err := os.WriteFile(path, []byte("http_port: 9100\ntls_dir: /file/certs\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load([]string{"-config", path, "-tls-dir", "/flag/certs"}, Default(time.Second, time.Second), TLS_DIR, TRANSPORT, HTTP_PORT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Transport != "grpc" || cfg.HTTPPort != 9100 || cfg.TLSDir != "/flag/certs" {
+		t.Errorf("got transport %q, HTTP port %d and TLS directory %q, want the environment, the file and the flag", cfg.Transport, cfg.HTTPPort, cfg.TLSDir)
+	}
+}
+
+func TestLoadOptions(t *testing.T) {
+	t.Setenv("STORAGE_ADDR", "127.0.0.1:9000")
+	cfg, err := Load(nil, Default(time.Second, time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Storage != "" {
+		t.Errorf("a protocol without storage read STORAGE_ADDR as %q", cfg.Storage)
+	}
+	if _, err := Load([]string{"-readers", "1"}, Default(time.Second, time.Second), K); err == nil {
+		t.Errorf("a flag the protocol does not take was accepted")
+	}
+}
@@ -0,0 +1,139 @@
+package inspect
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Message received from another node that waits at the gate of a node
+type waiting struct {
+	InFlight
+	Release chan bool // Closed once a held back message may be handled
+}
+
+// Holds back the messages from other nodes while the node is paused, and keeps track of the messages in flight.
+// Every protocol embeds one in its node, so that the console and the dashboard can pause and step any protocol.
+type Gate struct {
+	Lock sync.Mutex
+	Closed bool // If the messages from other nodes are held back
+	waiting []*waiting // Messages received and not handled yet, in order of arrival
+}
+
+// Function to hold a message from another node back while the node is paused and delay it by the message delay
+// of the protocol. Returns once the message may be handled. The resource is empty in the protocols with a single
+// critical section.
+func (g *Gate) Deliver(from int, msgType string, resource string, delay time.Duration) {
+	g.Lock.Lock()
+	message := &waiting{InFlight: InFlight{From: from, Type: msgType, Resource: resource, Since: time.Now(), Held: g.Closed}, Release: make(chan bool)}
+	g.waiting = append(g.waiting, message)
+	held := message.Held
+	g.Lock.Unlock()
+
+	if held {
+		<-message.Release
+	}
+	time.Sleep(delay)
+
+	g.Lock.Lock()
+	for i := range g.waiting {
+		if g.waiting[i] == message {
+			g.waiting = append(g.waiting[:i], g.waiting[i+1:]...)
+			break
+		}
+	}
+	g.Lock.Unlock()
+}
+
+// Function to pause the node. Returns false if the node is already paused.
+func (g *Gate) Pause() bool {
+	g.Lock.Lock()
+	defer g.Lock.Unlock()
+
+	if g.Closed {
+		return false
+	}
+	g.Closed = true
+	return true
+}
+
+// Function to resume the node and hand it the messages that were held back. Returns false if the node is not paused.
+func (g *Gate) Resume() bool {
+	g.Lock.Lock()
+	defer g.Lock.Unlock()
+
+	if !g.Closed {
+		return false
+	}
+	g.Closed = false
+	for _, message := range g.waiting {
+		if message.Held {
+			message.Held = false
+			close(message.Release)
+		}
+	}
+	return true
+}
+
+// Function to hand the node the oldest message that is held back, while the node stays paused.
+// Returns false if no message is held back.
+func (g *Gate) Step() (InFlight, bool) {
+	g.Lock.Lock()
+	defer g.Lock.Unlock()
+
+	for _, message := range g.waiting {
+		if message.Held {
+			message.Held = false
+			close(message.Release)
+			return message.InFlight, true
+		}
+	}
+	return InFlight{}, false
+}
+
+// Function to check if the node is paused
+func (g *Gate) Paused() bool {
+	g.Lock.Lock()
+	defer g.Lock.Unlock()
+	return g.Closed
+}
+
+// Function to get the messages in flight, in order of arrival
+func (g *Gate) Messages() []InFlight {
+	g.Lock.Lock()
+	defer g.Lock.Unlock()
+
+	messages := []InFlight{}
+	for _, message := range g.waiting {
+		messages = append(messages, message.InFlight)
+	}
+	return messages
+}
+
+// Function to pause the node with the given ID from the console or an operator tool
+func (g *Gate) PauseNode(ID int) {
+	if g.Pause() {
+		fmt.Printf("[NODE-%d] Paused, messages from other nodes are held back\n", ID)
+	} else {
+		fmt.Printf("[NODE-%d] Already paused\n", ID)
+	}
+}
+
+// Function to resume the node with the given ID from the console or an operator tool
+func (g *Gate) ResumeNode(ID int) {
+	if g.Resume() {
+		fmt.Printf("[NODE-%d] Resumed\n", ID)
+	} else {
+		fmt.Printf("[NODE-%d] Not paused\n", ID)
+	}
+}
+
+// Function to hand the node with the given ID the oldest message that is held back from the console or an operator tool
+func (g *Gate) StepNode(ID int) {
+	message, ok := g.Step()
+	if !ok {
+		fmt.Printf("[NODE-%d] No messages are held back\n", ID)
+		return
+	}
+	fmt.Printf("[NODE-%d] Delivering the %s message from node %d\n", ID, message.Type, message.From)
+}
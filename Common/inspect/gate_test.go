@@ -0,0 +1,62 @@
+package inspect
+
+import (
+	"testing"
+	"time"
+)
+
+// Function to wait until the gate holds a number of messages, failing the test after a second
+func waitForMessages(t *testing.T, g *Gate, count int) []InFlight {
+	deadline := time.Now().Add(time.Second)
+	for {
+		messages := g.Messages()
+		if len(messages) == count {
+			return messages
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the gate holds %d messages, want %d: %+v", len(messages), count, messages)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGateHoldsMessagesBackWhilePaused(t *testing.T) {
+	var g Gate
+	if !g.Pause() || g.Pause() || !g.Paused() {
+		t.Fatalf("the gate did not pause exactly once")
+	}
+
+	delivered := make(chan string, 2)
+	for i, msgType := range []string{"REQUEST", "RELEASE"} {
+		go func() {
+			g.Deliver(1, msgType, "resource-0", 0)
+			delivered <- msgType
+		}()
+		waitForMessages(t, &g, i + 1)
+	}
+	messages := g.Messages()
+	if messages[0].Type != "REQUEST" || !messages[0].Held || messages[0].Resource != "resource-0" {
+		t.Fatalf("unexpected messages in flight: %+v", messages)
+	}
+
+	// Stepping hands over the oldest message only
+	message, ok := g.Step()
+	if !ok || message.Type != "REQUEST" {
+		t.Fatalf("Step handed over %+v, %v", message, ok)
+	}
+	if got := <-delivered; got != "REQUEST" {
+		t.Fatalf("%s was delivered before the REQUEST", got)
+	}
+	waitForMessages(t, &g, 1)
+
+	if !g.Resume() || g.Resume() || g.Paused() {
+		t.Fatalf("the gate did not resume exactly once")
+	}
+	if got := <-delivered; got != "RELEASE" {
+		t.Fatalf("%s was delivered instead of the RELEASE", got)
+	}
+	waitForMessages(t, &g, 0)
+	if _, ok := g.Step(); ok {
+		t.Fatalf("Step handed over a message while none is held back")
+	}
+}
@@ -0,0 +1,41 @@
+// Package inspect defines the part of the state of a node that is the same in every protocol. Every protocol
+// embeds it in the State it returns from Node.GetState, so that the operator console and the dashboard show any
+// protocol from the same typed snapshot.
+package inspect
+
+import (
+	"time"
+)
+
+// What a node is doing with the critical section
+type Phase string
+
+const (
+	IDLE Phase = "idle"
+	REQUESTING Phase = "requesting" // Waiting for the critical section
+	IN_CS Phase = "in CS"
+)
+
+// State of a node shared by every protocol
+type Snapshot struct {
+	ID int
+	IP string
+	Protocol string
+	Clock int
+	Paused bool // If the messages from other nodes are held back
+	Phase Phase // In the critical section if the node holds any resource, requesting if it waits for any
+	Token bool // If the node holds a token
+	Status []string // Requests and locks of the node, one line each
+	Queues []string // Queues kept by the node, one line each
+	Votes []string // Votes or replies held and received by the node, one line each
+	InFlight []InFlight // Messages received and not handled yet, in order of arrival
+}
+
+// Message received by a node from another node that has not been handled yet
+type InFlight struct {
+	From int
+	Type string
+	Resource string // Empty in the protocols with a single critical section
+	Since time.Time // When the message arrived
+	Held bool // If the message is held back because the node is paused
+}
@@ -0,0 +1,125 @@
+// Package mux lets a node that uses the gRPC transport serve net/rpc on the same address, so that tools and tests
+// that only speak net/rpc, like the dashboard and Node.GetState, can still reach it.
+package mux
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// First bytes every HTTP/2 client, and so every gRPC client, sends on a new connection
+	PREFACE = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+	// Protocol gRPC clients ask for with ALPN during the TLS handshake
+	H2 = "h2"
+	// How long a new connection may take to show which protocol it speaks
+	SNIFF_TIMEOUT = 10 * time.Second
+)
+
+// Connections of one protocol, handed out by Split
+type Listener struct {
+	addr net.Addr
+	conns chan net.Conn
+	closed chan struct{}
+	once sync.Once
+}
+
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *Listener) Close() error {
+	l.once.Do(func() { close(l.closed) })
+	return nil
+}
+
+func (l *Listener) Addr() net.Addr {
+	return l.addr
+}
+
+// Function to split the connections of a listener between gRPC and net/rpc. With TLS, the handshake is done here
+// and gRPC clients are recognised by the h2 protocol they ask for, so the gRPC server gets connections that are
+// already secured. Without TLS, gRPC clients are recognised by the HTTP/2 preface they send first.
+func Split(listener net.Listener, config *tls.Config) (*Listener, *Listener) {
+	grpcConns := &Listener{addr: listener.Addr(), conns: make(chan net.Conn), closed: make(chan struct{})}
+	rpcConns := &Listener{addr: listener.Addr(), conns: make(chan net.Conn), closed: make(chan struct{})}
+	if config != nil {
+		config = config.Clone()
+		config.NextProtos = []string{H2}
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				grpcConns.Close()
+				rpcConns.Close()
+				return
+			}
+			go func() {
+				conn, isGRPC, err := sniff(conn, config)
+				if err != nil {
+					conn.Close()
+					return
+				}
+				target := rpcConns
+				if isGRPC {
+					target = grpcConns
+				}
+				select {
+				case target.conns <- conn:
+				case <-target.closed:
+					conn.Close()
+				}
+			}()
+		}
+	}()
+	return grpcConns, rpcConns
+}
+
+// Function to find out if a new connection comes from a gRPC client
+func sniff(conn net.Conn, config *tls.Config) (net.Conn, bool, error) {
+	conn.SetDeadline(time.Now().Add(SNIFF_TIMEOUT))
+	defer conn.SetDeadline(time.Time{})
+
+	if config != nil {
+		tlsConn := tls.Server(conn, config)
+		err := tlsConn.Handshake()
+		if err != nil {
+			return conn, false, err
+		}
+		return tlsConn, tlsConn.ConnectionState().NegotiatedProtocol == H2, nil
+	}
+
+	// Read the first bytes one at a time, since a net/rpc client may send fewer bytes than the preface
+	reader := bufio.NewReaderSize(conn, len(PREFACE))
+	buffered := &bufferedConn{Conn: conn, reader: reader}
+	for i := 1; i <= len(PREFACE); i++ {
+		peeked, err := reader.Peek(i)
+		if err != nil {
+			return buffered, false, err
+		}
+		if peeked[i - 1] != PREFACE[i - 1] {
+			return buffered, false, nil
+		}
+	}
+	return buffered, true, nil
+}
+
+// Connection whose first bytes were already read into a buffer
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
@@ -0,0 +1,53 @@
+package mux
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// Function to send data on a new connection and read it back on the listener it was handed to
+func receive(t *testing.T, addr string, l *Listener, data string) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, err = io.WriteString(conn, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			accepted <- conn
+		}
+	}()
+	select {
+	case server := <-accepted:
+		defer server.Close()
+		buf := make([]byte, len(data))
+		_, err := io.ReadFull(server, buf)
+		if err != nil || string(buf) != data {
+			t.Fatalf("read %q (%v) instead of %q", buf, err, data)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("%q was not handed to the expected listener", data)
+	}
+}
+
+func TestSplitByPreface(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	grpcConns, rpcConns := Split(listener, nil)
+
+	receive(t, listener.Addr().String(), grpcConns, PREFACE + "settings")
+	receive(t, listener.Addr().String(), rpcConns, "gob")
+	receive(t, listener.Addr().String(), rpcConns, "PRI * HTTP/1.1")
+}
@@ -0,0 +1,86 @@
+// Package safety checks on the bootstrap node that the critical section of every resource is never shared unsafely.
+// It is shared by every protocol that reports its entries and exits to the bootstrap node.
+package safety
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Group of the writers. Writers only share the critical section with each other, at most k at a time. Protocols
+// without reader-writer modes report every node as a writer.
+const WRITER = ""
+
+// Keeps track of the nodes inside the critical section of every resource to check that at most k writers are
+// inside it at the same time and that only requests of the same group share it otherwise
+type Checker struct {
+	Holders map[string]map[int]string // Nodes currently inside the critical section of each resource and their group
+	Violations int // Number of times the critical section was shared unsafely
+	Groups bool // If a reader or a member of a session has entered the critical section
+	Lock sync.Mutex
+}
+
+// Function to record that a node entered the critical section of a resource. Returns an error describing the
+// violation if the nodes inside it share it unsafely.
+func (c *Checker) Enter(k int, resource string, ID int, group string) error {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+
+	if c.Holders == nil {
+		c.Holders = make(map[string]map[int]string)
+	}
+	if c.Holders[resource] == nil {
+		c.Holders[resource] = make(map[int]string)
+	}
+	holders := c.Holders[resource]
+	holders[ID] = group
+	c.Groups = c.Groups || group != WRITER
+
+	writers := 0
+	groups := make(map[string]bool)
+	for _, g := range holders {
+		if g == WRITER {
+			writers++
+		} else {
+			groups[g] = true
+		}
+	}
+	if writers <= k && (writers == 0 || writers == len(holders)) && len(groups) <= 1 {
+		return nil
+	}
+
+	c.Violations++
+	IDs := make([]int, 0, len(holders))
+	for i := range holders {
+		IDs = append(IDs, i)
+	}
+	sort.Ints(IDs)
+	where := "the critical section"
+	if resource != "" {
+		where += " of " + resource
+	}
+	return fmt.Errorf("nodes %v are in %s at the same time (k = %d, %d writers)", IDs, where, k, writers)
+}
+
+// Function to record that a node left the critical section of a resource
+func (c *Checker) Exit(resource string, ID int) {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+	delete(c.Holders[resource], ID)
+}
+
+// Function to describe the result of the safety check
+func (c *Checker) Result(k int) string {
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+
+	switch {
+	case c.Violations > 0:
+		return fmt.Sprintf("Safety check failed: the critical section was shared unsafely %d times", c.Violations)
+	case c.Groups:
+		return fmt.Sprintf("Safety check passed: at most %d writers and no requests of different groups were in the critical section at the same time", k)
+	default:
+		return fmt.Sprintf("Safety check passed: at most %d nodes were in the critical section at the same time", k)
+	}
+}
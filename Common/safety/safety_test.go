@@ -0,0 +1,56 @@
+package safety
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWritersUpToK(t *testing.T) {
+	var c Checker
+	if err := c.Enter(2, "resource-0", 1, WRITER); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Enter(2, "resource-0", 2, WRITER); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Enter(2, "resource-0", 3, WRITER); err == nil {
+		t.Fatal("a third writer was let in with k = 2")
+	}
+	c.Exit("resource-0", 3)
+	if err := c.Enter(2, "resource-1", 3, WRITER); err != nil {
+		t.Fatalf("resources are checked separately: %s", err)
+	}
+	if !strings.HasPrefix(c.Result(2), "Safety check failed") {
+		t.Fatalf("unexpected result %q", c.Result(2))
+	}
+}
+
+func TestGroupsDoNotMix(t *testing.T) {
+	var c Checker
+	if err := c.Enter(1, "", 1, "READ"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Enter(1, "", 2, "READ"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Enter(1, "", 3, WRITER); err == nil {
+		t.Fatal("a writer was let in alongside readers")
+	}
+	c.Exit("", 3)
+	if err := c.Enter(1, "", 3, "SESSION-a"); err == nil {
+		t.Fatal("a session was let in alongside readers")
+	}
+}
+
+func TestResult(t *testing.T) {
+	var c Checker
+	c.Enter(1, "", 1, WRITER)
+	c.Exit("", 1)
+	if got := c.Result(1); got != "Safety check passed: at most 1 nodes were in the critical section at the same time" {
+		t.Fatalf("unexpected result %q", got)
+	}
+	c.Enter(1, "", 2, "READ")
+	if got := c.Result(1); !strings.Contains(got, "at most 1 writers and no requests of different groups") {
+		t.Fatalf("unexpected result %q", got)
+	}
+}
@@ -0,0 +1,36 @@
+// Package storage holds the messages of the fenced storage server and the client every protocol uses to write to
+// it, so that the nodes and the server always agree on them.
+package storage
+
+import (
+	"fmt"
+	"net/rpc"
+)
+
+// Write sent by a node holding the lock on a resource
+type Write struct {
+	Resource string
+	Token int64 // Fencing token of the writer's entry into the critical section
+	Node int
+	Data string
+}
+
+// Reply of the fenced storage server
+type Reply struct {
+	Accepted bool // False if a write with a larger fencing token was already accepted
+	Highest int64 // Largest fencing token the server has seen for the resource
+}
+
+// Function to send a write to the fenced storage server over a client the node dialed the same way as its peers.
+// Returns false if the server rejected the token as stale.
+func Send(client *rpc.Client, write Write) (bool, error) {
+	var reply Reply
+	err := client.Call("Storage.Write", write, &reply)
+	if err != nil {
+		return false, fmt.Errorf("error in calling Storage.Write: %s", err)
+	}
+	if !reply.Accepted {
+		fmt.Printf("[NODE-%d] The storage server rejected the fencing token %d for %s, a newer holder already wrote with %d\n", write.Node, write.Token, write.Resource, reply.Highest)
+	}
+	return reply.Accepted, nil
+}
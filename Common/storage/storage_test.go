@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"testing"
+)
+
+// Server that refuses every token below the largest one it has seen, like the fenced storage server
+type Server struct {
+	Highest int64
+}
+
+func (s *Server) Write(write Write, reply *Reply) error {
+	if write.Token == 0 {
+		return errors.New("no token")
+	}
+	accepted := write.Token >= s.Highest
+	s.Highest = max(write.Token, s.Highest)
+	*reply = Reply{Accepted: accepted, Highest: s.Highest}
+	return nil
+}
+
+func TestSend(t *testing.T) {
+	server := rpc.NewServer()
+	server.RegisterName("Storage", &Server{Highest: 5})
+	conn, peer := net.Pipe()
+	go server.ServeConn(peer)
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	cases := []struct {
+		token int64
+		accepted bool
+		fails bool
+	}{
+		{5, true, false},
+		{6, true, false},
+		{5, false, false}, // A write with token N is refused once N + 1 was seen
+		{0, false, true},
+	}
+	for _, c := range cases {
+		accepted, err := Send(client, Write{Resource: "resource-0", Token: c.token, Node: 1})
+		if accepted != c.accepted || (err != nil) != c.fails {
+			t.Errorf("Send with token %d = %t, %v, want %t and failing %t", c.token, accepted, err, c.accepted, c.fails)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package trace records what a node does, one JSON object per line, so that Trace-Checker and Visualizer can
+// replay a run. It is shared by every protocol that can record a trace.
+package trace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	WAIT = "WAIT"
+	ACQUIRED = "ACQUIRED"
+	RELEASED = "RELEASED"
+	CANCELLED = "CANCELLED"
+	ARRIVED = "ARRIVED"
+	HANDLED = "HANDLED"
+)
+
+// Event in the trace of a node, written as one JSON object per line
+type Event struct {
+	Time int64 `json:"time"` // Unix time in nanoseconds
+	Node int `json:"node"`
+	Resource string `json:"resource"`
+	Event string `json:"event"` // WAIT, ACQUIRED, RELEASED, CANCELLED, ARRIVED or HANDLED
+	Message *Message `json:"message,omitempty"` // Only set for ARRIVED and HANDLED
+}
+
+// Message from another node in the trace of the node that received it
+type Message struct {
+	From int `json:"from"`
+	Type string `json:"type"`
+	Clock int `json:"clock"` // Logical clock of the sender when the message was sent
+	ReqTime int `json:"reqTime"` // Timestamp of the request or the token the message belongs to
+}
+
+// Writes the trace of a node, nothing is recorded if no file is open
+type Tracer struct {
+	ID int // ID of the node
+	File *os.File
+	Off bool // If recording was stopped from the console
+	Lock sync.Mutex
+}
+
+// Function to start recording the trace of node ID in dir/node-<ID>.jsonl
+func (t *Tracer) Open(dir string, ID int) error {
+	if dir == "" {
+		return nil
+	}
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filepath.Join(dir, fmt.Sprintf("node-%d.jsonl", ID)))
+	if err != nil {
+		return err
+	}
+	t.Lock.Lock()
+	t.ID = ID
+	t.File = file
+	t.Lock.Unlock()
+	return nil
+}
+
+// Function to resume recording, opening the trace in dir if no file is open yet. Returns the path of the trace.
+func (t *Tracer) Start(dir string, ID int) (string, error) {
+	t.Lock.Lock()
+	open := t.File != nil
+	t.Lock.Unlock()
+	if !open {
+		err := t.Open(dir, ID)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	t.Lock.Lock()
+	defer t.Lock.Unlock()
+	if t.File == nil {
+		return "", fmt.Errorf("no directory was given for the trace")
+	}
+	t.Off = false
+	return t.File.Name(), nil
+}
+
+// Function to stop recording until Start is called again
+func (t *Tracer) Stop() {
+	t.Lock.Lock()
+	t.Off = true
+	t.Lock.Unlock()
+}
+
+// Function to write an event to the trace, stamped with the current time
+func (t *Tracer) Record(event Event) {
+	t.Lock.Lock()
+	defer t.Lock.Unlock()
+
+	if t.File == nil || t.Off {
+		return
+	}
+	event.Time = time.Now().UnixNano()
+	data, _ := json.Marshal(event)
+	_, err := t.File.Write(append(data, '\n'))
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while writing the trace: %s\n", t.ID, err)
+	}
+}
+
+// Function to check if the trace is being recorded
+func (t *Tracer) Tracing() bool {
+	t.Lock.Lock()
+	defer t.Lock.Unlock()
+	return t.File != nil && !t.Off
+}
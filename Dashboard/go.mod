@@ -0,0 +1,12 @@
+module dashboard
+
+go 1.23.2
+
+require (
+	common v0.0.0
+	golang.org/x/term v0.28.0
+)
+
+require golang.org/x/sys v0.29.0 // indirect
+
+replace common => ../Common
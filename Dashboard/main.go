@@ -0,0 +1,392 @@
+package main
+
+import (
+	"common/address"
+	"common/inspect"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/rpc"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	CALL_TIMEOUT = 2 * time.Second // How long the dashboard waits for a node to answer
+)
+
+// Arguments of a call to a node. The nodes decode them into their own message type,
+// which has the same field.
+type Message struct {
+	Secret string
+}
+
+// Part of the reply of Node.GetState that is the same in every protocol. The rest of the State of the protocol
+// is skipped when the reply is decoded.
+type State struct {
+	inspect.Snapshot
+}
+
+// State of a node as last seen by the dashboard
+type View struct {
+	ID int
+	Address string
+	State State
+	Err error // Why the node could not be inspected, nil if it answered
+}
+
+// Dashboard of a network of nodes
+type Dashboard struct {
+	Registry string // File the nodes register their addresses in, used if Peers is empty
+	Peers map[int]string // Fixed addresses of the nodes by ID
+	Secret string // Shared secret of the admin service, empty if the nodes do not check it
+	TLS *tls.Config // TLS settings of the operator certificate, nil without mutual TLS
+	Color bool
+	Views []View // Nodes in the order of their IDs
+	Notice string // Result of the last key press
+	Updated time.Time
+	Lock sync.Mutex
+}
+
+// Tool to watch the state of every node of a network live, and to pause the nodes and deliver their messages one by one
+func main() {
+	registry := flag.String("registry", "nodes-list.json", "file the nodes register their addresses in")
+	peers := flag.String("peers", "", "fixed addresses of the nodes, as ID=address pairs separated by commas, instead of the registry")
+	interval := flag.Duration("interval", 500 * time.Millisecond, "how often the state of the nodes is refreshed")
+	once := flag.Bool("once", false, "print the state of the nodes once and exit")
+	tlsDir := flag.String("tls-dir", os.Getenv("TLS_DIR"), "directory with ca.crt and the operator certificate, for nodes with mutual TLS")
+	noColor := flag.Bool("no-color", false, "do not color the output")
+	flag.Parse()
+
+	d := &Dashboard{
+		Registry: *registry,
+		Peers: make(map[int]string),
+		Secret: os.Getenv("ADMIN_SECRET"),
+		Color: !*noColor && os.Getenv("NO_COLOR") == "",
+	}
+	for _, pair := range strings.Split(*peers, ",") {
+		if pair == "" {
+			continue
+		}
+		ID, addr, ok := strings.Cut(pair, "=")
+		number, err := strconv.Atoi(ID)
+		if !ok || err != nil {
+			fmt.Printf("[DASHBOARD] Invalid peer %q, expected ID=address\n", pair)
+			os.Exit(1)
+		}
+		d.Peers[number] = addr
+	}
+	if *tlsDir != "" {
+		config, err := operatorTLS(*tlsDir)
+		if err != nil {
+			fmt.Println("[DASHBOARD] Error occurred while loading the operator certificate:", err)
+			os.Exit(1)
+		}
+		d.TLS = config
+	}
+
+	if *once {
+		d.Color = false
+		d.refresh()
+		fmt.Println(strings.Join(d.render(), "\n"))
+		return
+	}
+
+	// Keys are read one at a time from a terminal, or line by line otherwise
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		state, err := term.MakeRaw(int(os.Stdin.Fd()))
+		if err != nil {
+			fmt.Println("[DASHBOARD] Error occurred while setting up the terminal:", err)
+			os.Exit(1)
+		}
+		defer term.Restore(int(os.Stdin.Fd()), state)
+	}
+	fmt.Print("\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\r\n")
+
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			_, err := os.Stdin.Read(buf)
+			if err != nil {
+				close(keys)
+				return
+			}
+			keys <- buf[0]
+		}
+	}()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		d.refresh()
+		d.draw()
+		select {
+		case <-ticker.C:
+		case <-sigChan:
+			return
+		case key, ok := <-keys:
+			if !ok || key == 'q' || key == 3 {
+				return
+			}
+			d.handleKey(key)
+		}
+	}
+}
+
+// Function to get the addresses of the nodes to watch
+func (d *Dashboard) nodes() map[int]string {
+	if len(d.Peers) > 0 {
+		return d.Peers
+	}
+	nodesList := make(map[int]string)
+	data, err := os.ReadFile(d.Registry)
+	if err != nil {
+		return nodesList
+	}
+	json.Unmarshal(data, &nodesList)
+	return nodesList
+}
+
+// Function to ask every node for its state
+func (d *Dashboard) refresh() {
+	nodesList := d.nodes()
+	views := make([]View, 0, len(nodesList))
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for ID, addr := range nodesList {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			view := View{ID: ID, Address: addr}
+			view.Err = d.call(addr, "Node.GetState", &view.State)
+			lock.Lock()
+			views = append(views, view)
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+	sort.Slice(views, func(i, j int) bool { return views[i].ID < views[j].ID })
+
+	d.Lock.Lock()
+	d.Views = views
+	d.Updated = time.Now()
+	d.Lock.Unlock()
+}
+
+// Function to handle a key pressed by the user
+func (d *Dashboard) handleKey(key byte) {
+	switch key {
+	case 'p':
+		d.Notice = fmt.Sprintf("Paused %d nodes", d.callAll("Admin.Pause"))
+	case 'r':
+		d.Notice = fmt.Sprintf("Resumed %d nodes", d.callAll("Admin.Resume"))
+	case 's':
+		d.Notice = d.step()
+	}
+}
+
+// Function to call a method on every node that answered the last refresh. Returns the number of nodes that succeeded.
+func (d *Dashboard) callAll(method string) int {
+	d.Lock.Lock()
+	views := d.Views
+	d.Lock.Unlock()
+
+	var succeeded sync.WaitGroup
+	count := 0
+	var lock sync.Mutex
+	for _, view := range views {
+		if view.Err != nil {
+			continue
+		}
+		succeeded.Add(1)
+		go func() {
+			defer succeeded.Done()
+			if d.call(view.Address, method, &Message{}) == nil {
+				lock.Lock()
+				count++
+				lock.Unlock()
+			}
+		}()
+	}
+	succeeded.Wait()
+	return count
+}
+
+// Function to deliver the message that has been held back the longest in the whole network
+func (d *Dashboard) step() string {
+	d.Lock.Lock()
+	views := d.Views
+	d.Lock.Unlock()
+
+	var oldest *inspect.InFlight
+	var to View
+	for _, view := range views {
+		for i, message := range view.State.InFlight {
+			if message.Held && (oldest == nil || message.Since.Before(oldest.Since)) {
+				oldest = &view.State.InFlight[i]
+				to = view
+			}
+		}
+	}
+	if oldest == nil {
+		return "No messages are held back, press p to pause the nodes first"
+	}
+	err := d.call(to.Address, "Admin.Step", &Message{})
+	if err != nil {
+		return fmt.Sprintf("Error occurred while delivering a message to node %d: %s", to.ID, err)
+	}
+	return fmt.Sprintf("Delivered the %s message from node %d to node %d", oldest.Type, oldest.From, to.ID)
+}
+
+// Function to call a method of the admin service of a node
+func (d *Dashboard) call(addr string, method string, reply any) error {
+	conn, err := address.Dial(addr, CALL_TIMEOUT)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(CALL_TIMEOUT))
+	if d.TLS != nil {
+		config := d.TLS.Clone()
+		config.ServerName = address.ServerName(addr)
+		conn = tls.Client(conn, config)
+	}
+	client := rpc.NewClient(conn)
+	defer client.Close()
+	return client.Call(method, Message{Secret: d.Secret}, reply)
+}
+
+// Function to draw the dashboard over the whole terminal
+func (d *Dashboard) draw() {
+	fmt.Print("\x1b[H\x1b[2J" + strings.Join(d.render(), "\x1b[K\r\n"))
+}
+
+// Function to render the state of the network as lines of text
+func (d *Dashboard) render() []string {
+	d.Lock.Lock()
+	defer d.Lock.Unlock()
+
+	protocol := "unknown protocol"
+	inCS, tokens, paused := []string{}, []string{}, []string{}
+	messages := []string{}
+	for _, view := range d.Views {
+		s := view.State
+		if view.Err != nil {
+			continue
+		}
+		protocol = s.Protocol
+		if s.Phase == inspect.IN_CS {
+			inCS = append(inCS, strconv.Itoa(s.ID))
+		}
+		if s.Token {
+			tokens = append(tokens, strconv.Itoa(s.ID))
+		}
+		if s.Paused {
+			paused = append(paused, strconv.Itoa(s.ID))
+		}
+	}
+
+	lines := []string{
+		d.paint(1, fmt.Sprintf("Dashboard of %d nodes running %s, updated at %s", len(d.Views), protocol, d.Updated.Format("15:04:05.000"))),
+		fmt.Sprintf("In the critical section: %s   Tokens: %s   Paused: %s", list(inCS), list(tokens), list(paused)),
+		"",
+	}
+	for _, view := range d.Views {
+		if view.Err != nil {
+			lines = append(lines, d.paint(31, fmt.Sprintf("NODE %d  %s  unreachable: %s", view.ID, view.Address, view.Err)))
+			continue
+		}
+		s := view.State
+		header := fmt.Sprintf("NODE %d  %s  clock %d  ", s.ID, s.IP, s.Clock)
+		switch s.Phase {
+		case inspect.IN_CS:
+			header += d.paint(32, "IN CS")
+		case inspect.REQUESTING:
+			header += d.paint(33, "REQUESTING")
+		default:
+			header += "idle"
+		}
+		if s.Token {
+			header += "  " + d.paint(36, "TOKEN")
+		}
+		if s.Paused {
+			header += "  " + d.paint(31, "PAUSED")
+		}
+		lines = append(lines, header)
+		for _, section := range [][]string{s.Status, s.Queues, s.Votes} {
+			for _, line := range section {
+				lines = append(lines, "    " + line)
+			}
+		}
+
+		for _, message := range s.InFlight {
+			text := fmt.Sprintf("node %d -> node %d  %s", message.From, s.ID, message.Type)
+			if message.Resource != "" {
+				text += " " + message.Resource
+			}
+			text += fmt.Sprintf("  for %.1fs", time.Since(message.Since).Seconds())
+			if message.Held {
+				text += "  " + d.paint(31, "held")
+			}
+			messages = append(messages, text)
+		}
+	}
+
+	lines = append(lines, "", d.paint(1, fmt.Sprintf("Messages in flight: %d", len(messages))))
+	for _, message := range messages {
+		lines = append(lines, "    " + message)
+	}
+	lines = append(lines, "", "Keys: p pause all nodes, s deliver the oldest held message, r resume all nodes, q quit")
+	if d.Notice != "" {
+		lines = append(lines, d.Notice)
+	}
+	return lines
+}
+
+// Function to color a text with an ANSI color code if colors are on
+func (d *Dashboard) paint(code int, text string) string {
+	if !d.Color {
+		return text
+	}
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", code, text)
+}
+
+// Function to count nodes and list their IDs, or none if there are no nodes
+func list(IDs []string) string {
+	switch len(IDs) {
+	case 0:
+		return "none"
+	case 1:
+		return "1 (node " + IDs[0] + ")"
+	}
+	return fmt.Sprintf("%d (nodes %s)", len(IDs), strings.Join(IDs, ", "))
+}
+
+// Function to load the TLS settings of the operator certificate, which the nodes accept for admin calls
+func operatorTLS(dir string) (*tls.Config, error) {
+	caPEM, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	ca := x509.NewCertPool()
+	ca.AppendCertsFromPEM(caPEM)
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, "operator.crt"), filepath.Join(dir, "operator.key"))
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: ca, MinVersion: tls.VersionTLS13}, nil
+}
@@ -1,71 +1,156 @@
 package main
 
 import (
-	"encoding/json"
+	"common/config"
+	"errors"
 	"fair_ring/node"
+	"fair_ring/utils"
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"os/signal"
-	"strconv"
 	"sync"
 	"syscall"
 	"time"
 )
 
 func main() {
+	cfg, err := utils.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Println("Error occurred while reading the configuration:", err)
+		os.Exit(1)
+	}
+	err = utils.Apply(cfg)
+	if err != nil {
+		fmt.Println("Error occurred while opening the log:", err)
+		os.Exit(1)
+	}
+
 	n := node.Node{
 		Lock: sync.Mutex{}, 
 		Request: false, 
 		Clock: 0, 
-		ReqTime: -1,
+		K: 1,
+		Requested: []string{node.ResourceName(0)},
+		Resources: make(map[string]*node.Resource),
 	}
 
-	var nodesList map[int]string = readNodesList()
+	// The transport is needed to reach the predecessor
+	if cfg.Transport != "" {
+		if cfg.Transport != node.RPC_TRANSPORT && cfg.Transport != node.GRPC_TRANSPORT {
+			fmt.Printf("Unknown transport %q, using %s\n", cfg.Transport, node.RPC_TRANSPORT)
+		} else {
+			node.Transport = cfg.Transport
+		}
+	}
+
+	var nodesList map[int]string = cfg.NodesList()
 
-	if len(nodesList) == 0 {
-		n.ID = 0 // Set as bootstrap node
-		n.IP = node.LOCALHOST + "8000"
+	// Without an ID in the configuration, the node takes the next free one, and the first node is the bootstrap node
+	n.ID, n.IP, n.Bind, err = cfg.Identity(nodesList)
+	if err != nil {
+		fmt.Println("Error occurred while choosing the ID of the node:", err)
+		os.Exit(1)
+	}
+	n.Bootstrap = cfg.BootstrapAddress(n.ID, n.IP, nodesList)
+	n.Members = cfg.NodesList // The registry is read again whenever the successor cannot be reached
+	if len(cfg.Peers) > 0 {
+		n.Successor = utils.Successor(cfg, n.ID) // The ring follows the order of the IDs in the fixed peer list
+	} else if len(nodesList) == 0 {
 		n.Successor = n.IP
 	} else {
-		n.ID = len(nodesList)
-		n.IP = node.LOCALHOST + strconv.Itoa(8000 + n.ID)	
 		n.Successor = nodesList[0] // Set the successor of the last node to the first node
+	}
 
+	// Node i presents the certificate of node-i
+	if cfg.TLSDir != "" {
+		err := n.LoadTLS(cfg.TLSDir)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while loading the certificates: %s\n", n.ID, err)
+			os.Exit(1)
+		}
+	}
+
+	if n.ID != 0 && len(cfg.Peers) == 0 {
 		message := node.Message{ID: n.ID, IP: n.IP}
 		
-		_, err := node.CallByRPC(nodesList[n.ID - 1], "Node.SetSuccessor", message)
+		_, err := node.CallByRPC(nodesList[n.ID - 1], "Admin.SetSuccessor", message)
 		if err != nil {
 			fmt.Println("Error occurred while setting the successor: ", err)
 		}
 	}
 
-	go n.StartRPCServer()
+	err = n.OpenTrace(cfg.TraceDir)
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while opening the trace: %s\n", n.ID, err)
+	}
 
-	nodesList[n.ID] = n.IP
+	n.Storage = cfg.Storage
+	n.SkewMargin = time.Duration(cfg.SkewMargin)
 
-	jsonData, err := json.Marshal(nodesList)
-	if err != nil {
-		fmt.Println("Error occurred while marshalling nodesList: ", err)
+	go n.StartRPCServer()
+
+	// Node i serves the HTTP API on port http-port + i of the host it listens on
+	if cfg.HTTPPort != 0 {
+		go n.StartHTTPServer(n.HTTPAddress(cfg.HTTPPort))
 	}
 
-	err = ioutil.WriteFile("nodes-list.json", jsonData, os.ModePerm)
+	// With a fixed peer list every node already knows the ring, so it only joins through the registry
+	if len(cfg.Peers) == 0 {
+		nodesList[n.ID] = n.IP
 
-	if err != nil {
-		fmt.Println("Error occurred while updating nodes-list.json: ", err)
+		err = config.WriteNodesList(cfg.Registry, nodesList)
+		if err != nil {
+			fmt.Printf("Error occurred while updating %s: %s\n", cfg.Registry, err)
+		}
 	}
 
 	var numRequests int
-	if n.ID == 0 {
+	var configured sync.WaitGroup
+	if cfg.IsBootstrap(n.ID) && cfg.Nodes > 0 {
+		// Hand out the workload from the configuration once enough nodes have joined
+		fmt.Printf("[NODE-%d] Waiting for %d nodes to join\n", n.ID, cfg.Nodes)
+		nodesList = cfg.WaitForNodes()
+		message := utils.WorkloadMessage(cfg.Workload)
+		numRequests = message.NumRequests
+		for i := range nodesList {
+			configured.Add(1)
+			go func(i int) {
+				defer configured.Done()
+				_, err := node.CallByRPC(nodesList[i], "Admin.SetRequesting", message)
+				if err != nil {
+					fmt.Printf("[NODE-%d] Error occurred while setting the request flag for node %d: %s\n", n.ID, i, err)
+				}
+			}(i)
+		}
+	} else if cfg.IsBootstrap(n.ID) {
 		fmt.Printf("[NODE-%d] Make sure all the nodes are up and running.\n", n.ID)
 		fmt.Printf("[NODE-%d] How many nodes should request for CS: \n", n.ID)
 		fmt.Scan(&numRequests)
-
-		nodesList = readNodesList()
-		message := node.Message{NumRequests: numRequests}
-		for i := 0; i < len(nodesList); i++ {
+		fmt.Printf("[NODE-%d] How many nodes can be in the critical section at the same time (k): \n", n.ID)
+		fmt.Scan(&n.K)
+		n.K = max(n.K, 1)
+		var timeout int
+		fmt.Printf("[NODE-%d] How many seconds should a node wait for the lock before giving up (0 to wait forever): \n", n.ID)
+		fmt.Scan(&timeout)
+		var ttl int
+		fmt.Printf("[NODE-%d] How many seconds should a lease last without being renewed (0 to turn leases off): \n", n.ID)
+		fmt.Scan(&ttl)
+		var numResources int
+		fmt.Printf("[NODE-%d] How many resources should the requesting nodes be spread over: \n", n.ID)
+		fmt.Scan(&numResources)
+		var resourcesPerRequest int
+		fmt.Printf("[NODE-%d] How many resources should each request take at once: \n", n.ID)
+		fmt.Scan(&resourcesPerRequest)
+
+		nodesList = cfg.NodesList()
+		message := node.Message{NumRequests: numRequests, K: n.K, Timeout: timeout, TTL: ttl, NumResources: numResources, ResourcesPerRequest: resourcesPerRequest}
+		for i := range nodesList {
 			go func(i int) {
-				_, err := node.CallByRPC(nodesList[i], "Node.SetRequesting", message)
+				_, err := node.CallByRPC(nodesList[i], "Admin.SetRequesting", message)
 				if err != nil {
 					fmt.Printf("[NODE-%d] Error occurred while setting the request flag for node %d: %s\n", n.ID, i, err)
 				}
@@ -74,9 +159,15 @@ func main() {
 	}
 	
 	// Start the token passing
-	if n.ID == 0 {
+	if cfg.IsBootstrap(n.ID) {
 		var answer string
 		go func() {
+			if cfg.Nodes > 0 {
+				// Every node must know its workload before the tokens start
+				configured.Wait()
+				n.StartTokenPassing()
+				return
+			}
 			fmt.Printf("[NODE-%d] Make sure that all the required nodes are up.\n", n.ID)
 			for {
 				fmt.Printf("[NODE-%d] Do you want to start the token passing? (y/n): ", n.ID)
@@ -87,12 +178,20 @@ func main() {
 				} else { 
 					fmt.Printf("[NODE-%d] Waiting for all nodes to be ready...\n", n.ID)
 				}
+				if cfg.Console {
+					go n.RunConsole(os.Stdin)
+				}
 			}
 		}()
+
+		// Caclculate the time taken
+		go calculateTimeTaken(&n, numRequests)
 	}
 
-	// Caclculate the time taken
-	go calculateTimeTaken(&n, numRequests)
+	// The bootstrap node reads the answers to its prompts from the console first
+	if cfg.Console && !(cfg.IsBootstrap(n.ID) && cfg.Nodes == 0) {
+		go n.RunConsole(os.Stdin)
+	}
 
 	// Handling when the node fails or is shut down
 	sigChan := make(chan os.Signal, 1)
@@ -104,47 +203,40 @@ func main() {
 		fmt.Println("Shutting down...")
 
 		// Remove the node from the list
-		nodesList = readNodesList()
+		if len(cfg.Peers) == 0 {
+			nodesList = config.ReadNodesList(cfg.Registry)
 
-		delete(nodesList, n.ID) // remove the element that left the network from the nodesList
+			delete(nodesList, n.ID) // remove the element that left the network from the nodesList
 
-		jsonData, err := json.Marshal(nodesList)
-		err = ioutil.WriteFile("nodes-list.json", jsonData, os.ModePerm)
-		if err != nil {
-			fmt.Println("Error occurred while updating nodes-list.json: ", err)
+			err := config.WriteNodesList(cfg.Registry, nodesList)
+			if err != nil {
+				fmt.Printf("Error occurred while updating %s: %s\n", cfg.Registry, err)
+			}
 		}
 		os.Exit(0)
 	}()
 	select{}
 }
 
-func readNodesList() map[int]string {
-	jsonFile, err := os.Open("nodes-list.json")
-	if err != nil {
-		fmt.Println("Error opening nodes-list.json file:", err)
-	}
-	defer jsonFile.Close()
-
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-
-	var nodesList map[int]string
-
-	json.Unmarshal(byteValue, &nodesList) // Puts the byte value into the nodesList map
-
-	return nodesList
-}
-
 // Calculate the time taken from the first node to request to the last node to exist the critical section
 func calculateTimeTaken(n *node.Node, numRequests int) {
 	startTime := time.Now()
 
 	if n.ID == 0 {
+		n.Lock.Lock()
 		n.Finished = make([]bool, numRequests)
+		n.Lock.Unlock()
 		for {
-			if all(n.Finished) {
+			// The nodes report that they finished concurrently, so the list is only read under the lock
+			n.Lock.Lock()
+			done := all(n.Finished)
+			n.Lock.Unlock()
+			if done {
 				fmt.Printf("Time taken for all nodes to exit the critical section: %v\n", time.Since(startTime))
+				n.PrintSafetyCheck()
 				break
 			}
+			time.Sleep(10 * time.Millisecond)
 		}
 	}
 }
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"common/cluster"
+	"fair_ring/node"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Once every node has released the lock, the only token is parked at one node, which every node reports with
+// Node.GetState, whatever the transport
+func TestGetStateAfterTheWorkload(t *testing.T) {
+	for _, transport := range []string{node.RPC_TRANSPORT, node.GRPC_TRANSPORT} {
+		t.Run(transport, func(t *testing.T) {
+			t.Setenv("TRANSPORT", transport)
+			c := cluster.Start(t, 3, "-requests", "3")
+
+			// The node holding the token is in the critical section
+			waitForState(c, 0, func(state node.State) bool {
+				return slices.ContainsFunc(state.Resources, func(r node.ResourceState) bool { return r.Held && r.TokenID == 0 })
+			})
+			c.WaitFor(0, "Time taken", time.Minute)
+
+			deadline := time.Now().Add(time.Minute)
+			for {
+				parked := 0
+				for i := 0; i < 3; i++ {
+					state := waitForState(c, i, func(state node.State) bool {
+						return len(state.Resources) == 1 && !state.Resources[0].Request && !state.Resources[0].Held
+					})
+					if state.ID != i || state.Successor != c.Peers[(i + 1) % 3] {
+						t.Fatalf("unexpected state of node %d: %+v", i, state)
+					}
+					parked += len(state.Tokens)
+				}
+				if parked == 1 {
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("%d tokens are parked instead of 1", parked)
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+			state := waitForState(c, 0, func(state node.State) bool { return !slices.Contains(state.Finished, false) })
+			if len(state.Finished) != 3 {
+				t.Fatalf("node 0 does not know about every node: %+v", state)
+			}
+		})
+	}
+}
+
+// Once the holder of the token crashes, its lease expires at the bootstrap node, which regenerates the token. The
+// new token skips the crashed node and reaches the node waiting next, and the old token is dropped if it turns up.
+func TestLeaseExpiry(t *testing.T) {
+	c := cluster.Start(t, 4, "-requests", "0", "-ttl", "2")
+	for i := range c.Logs {
+		c.WaitFor(i, "Node will not request", time.Minute)
+	}
+
+	c.Command(1, "request")
+	c.WaitFor(1, "Holding a token of resource-0", time.Minute)
+	c.Command(2, "request")
+	waitForState(c, 2, func(state node.State) bool { return len(state.Resources) == 1 && state.Resources[0].Request })
+
+	// The holder crashes without passing the token on, so only its lease can bring the token back
+	c.Kill(1)
+	c.WaitFor(0, "Lease of NODE-1 on token 0 of resource-0 expired", time.Minute)
+	c.WaitFor(2, "Holding a token of resource-0", time.Minute)
+
+	// The token of the crashed node must not reach a node once it was regenerated
+	late := node.Message{ID: 1, TokenID: 0, Generation: 0, Resource: "resource-0", ReqTime: -1, ReqID: -1, LastActive: 1}
+	var reply node.Message
+	err := c.Call(3, "Node.ReceiveToken", late, &reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.WaitFor(3, "Dropping the stale token 0 of resource-0 from generation 0", time.Minute)
+	state := waitForState(c, 3, func(state node.State) bool { return len(state.Resources) == 1 })
+	if len(state.Tokens) != 0 || state.Resources[0].Held {
+		t.Fatalf("node 3 kept the stale token: %+v", state)
+	}
+	state = waitForState(c, 2, func(state node.State) bool { return len(state.Resources) == 1 })
+	if !state.Resources[0].Held || state.Resources[0].TokenID != 0 {
+		t.Fatalf("node 2 lost the token after the stale token turned up: %+v", state.Resources[0])
+	}
+}
+
+// A request that gives up once the timeout runs out must leave no request or reservation behind, so that the token
+// does not stop at the node for it and a later request still gets the lock
+func TestTimeoutLeavesNothingBehind(t *testing.T) {
+	t.Setenv("HTTP_PORT", "1")
+	c := cluster.Start(t, 4, "-requests", "0")
+	for i := range c.Logs {
+		c.WaitFor(i, "Node will not request", time.Minute)
+		c.WaitFor(i, "HTTP API is running", time.Minute)
+	}
+
+	c.Command(1, "request")
+	c.WaitFor(1, "Holding a token of resource-0", time.Minute)
+
+	var res node.LockResponse
+	if status := c.HTTP(2, "POST", "/locks/resource-0/acquire", node.AcquireRequest{Timeout: 1}, &res); status != http.StatusRequestTimeout {
+		t.Fatalf("acquisition of node 2 with a timeout on a held lock answered %d: %+v", status, res)
+	}
+	state := waitForState(c, 2, func(state node.State) bool { return len(state.Resources) == 1 })
+	if r := state.Resources[0]; r.Request || r.Reserved != -1 || r.Held {
+		t.Fatalf("node 2 kept its request after the timeout: %+v", r)
+	}
+
+	// Once released, the token passes node 2 by and parks, since nobody is requesting it
+	c.Command(1, "release")
+	c.WaitFor(1, "Released resource-0", time.Minute)
+	deadline := time.Now().Add(time.Minute)
+	for parked := 0; parked != 1; time.Sleep(100 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d tokens are parked instead of 1", parked)
+		}
+		parked = 0
+		for i := range c.Logs {
+			state := waitForState(c, i, func(state node.State) bool { return len(state.Resources) == 1 })
+			if state.Resources[0].Held {
+				t.Fatalf("node %d took the token although nobody is requesting it: %+v", i, state.Resources[0])
+			}
+			parked += len(state.Tokens)
+		}
+	}
+
+	if status := c.HTTP(2, "POST", "/locks/resource-0/acquire", node.AcquireRequest{Timeout: 30}, &res); status != http.StatusOK {
+		t.Fatalf("acquisition of node 2 on a free lock answered %d: %+v", status, res)
+	}
+	if status := c.HTTP(2, "POST", "/locks/resource-0/release", node.ReleaseRequest{Token: &res.Token}, &res); status != http.StatusOK {
+		t.Fatalf("release of node 2 answered %d: %+v", status, res)
+	}
+}
@@ -0,0 +1,73 @@
+package node
+
+import (
+	"common/admin"
+)
+
+// Admin calls a node with the node role may make about itself, so that it can join the ring
+var selfService = map[string]bool{
+	"Admin.SetSuccessor": true,
+}
+
+// Administrative calls of a node. They are registered apart from the protocol messages, so that only the
+// coordinator or an operator tool can reconfigure the network.
+type Admin struct {
+	n *Node
+}
+
+// Function to set the successor of the node
+func (a *Admin) SetSuccessor(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "SetSuccessor", message.Secret)
+	if err != nil {
+		return err
+	}
+	return a.n.setSuccessor(message, reply)
+}
+
+// Function to record that a token was regenerated, so that the node drops the older copies of the token
+func (a *Admin) SetGeneration(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "SetGeneration", message.Secret)
+	if err != nil {
+		return err
+	}
+	return a.n.setGeneration(message, reply)
+}
+
+// Function to set the workload of the node
+func (a *Admin) SetRequesting(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "SetRequesting", message.Secret)
+	if err != nil {
+		return err
+	}
+	return a.n.setRequesting(message, reply)
+}
+
+// Function to hold back the messages from other nodes
+func (a *Admin) Pause(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "Pause", message.Secret)
+	if err != nil {
+		return err
+	}
+	a.n.Gate.PauseNode(a.n.ID)
+	return nil
+}
+
+// Function to handle the messages that were held back and the ones that follow
+func (a *Admin) Resume(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "Resume", message.Secret)
+	if err != nil {
+		return err
+	}
+	a.n.Gate.ResumeNode(a.n.ID)
+	return nil
+}
+
+// Function to handle the oldest message that is held back while the node stays paused
+func (a *Admin) Step(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "Step", message.Secret)
+	if err != nil {
+		return err
+	}
+	a.n.Gate.StepNode(a.n.ID)
+	return nil
+}
@@ -0,0 +1,154 @@
+package node
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Directory the trace is written to when it is turned on from the console without a directory
+const DEFAULT_TRACE_DIR = "traces"
+
+// Commands of the console and what they do
+var consoleCommands = [][2]string{
+	{"request [resource]", "request a token of a resource, resource-0 by default"},
+	{"release [resource]", "pass the token of a resource on, or withdraw the request if no token has arrived yet"},
+	{"status", "show the requests of the node and the tokens it holds"},
+	{"queue", "show the tokens parked at the node"},
+	{"votes", "not used by the ring, which grants the critical section with tokens"},
+	{"peers", "show the successor of the node and the bootstrap node"},
+	{"clock", "show the logical clock"},
+	{"pause", "hold back the tokens and wakeup signals from other nodes"},
+	{"step", "handle the oldest token or signal that is held back and stay paused"},
+	{"resume", "handle the tokens and signals that were held back and the ones that follow"},
+	{"trace on [dir] | trace off", "start or stop recording the trace, in " + DEFAULT_TRACE_DIR + " by default"},
+	{"help", "show the commands"},
+}
+
+// Function to read operator commands line by line until the input ends, so that scenarios can be driven by hand
+func (n *Node) RunConsole(in io.Reader) {
+	fmt.Printf("[NODE-%d] Console is ready, type help for the commands\n", n.ID)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		n.runCommand(fields[0], fields[1:])
+	}
+}
+
+// Function to run a single console command
+func (n *Node) runCommand(command string, args []string) {
+	switch command {
+	case "request":
+		name := ResourceName(0)
+		if len(args) > 0 {
+			name = args[0]
+		}
+		go func() {
+			token, err := n.Acquire(name)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			fmt.Printf("[NODE-%d] Holding a token of %s with fencing token %d\n", n.ID, name, token)
+		}()
+
+	case "release":
+		name := ResourceName(0)
+		if len(args) > 0 {
+			name = args[0]
+		}
+		n.Lock.Lock()
+		r, ok := n.Resources[name]
+		requesting := ok && r.Request
+		n.Lock.Unlock()
+		if !requesting {
+			fmt.Printf("[NODE-%d] Not requesting %s\n", n.ID, name)
+			return
+		}
+		if n.cancel(name) {
+			fmt.Printf("[NODE-%d] Withdrew the request for %s\n", n.ID, name)
+			return
+		}
+		n.Release(name)
+		fmt.Printf("[NODE-%d] Released %s\n", n.ID, name)
+
+	case "status":
+		state := n.state()
+		fmt.Printf("[NODE-%d] Address: %s, clock: %d, k: %d, paused: %t, tracing: %t\n", n.ID, state.IP, state.Clock, state.K, state.Paused, state.Tracing)
+		n.printLines(state.Status)
+
+	case "queue":
+		state := n.state()
+		if len(state.Tokens) == 0 {
+			fmt.Printf("[NODE-%d] No tokens are parked at the node\n", n.ID)
+		}
+		n.printLines(queueLines(state))
+
+	case "votes":
+		fmt.Printf("[NODE-%d] The Fair Ring Protocol does not use votes, a node enters the critical section with a token\n", n.ID)
+
+	case "peers":
+		state := n.state()
+		fmt.Printf("[NODE-%d] Successor: %s\n", n.ID, state.Successor)
+		fmt.Printf("[NODE-%d] Bootstrap node: %s\n", n.ID, state.Bootstrap)
+
+	case "clock":
+		fmt.Printf("[NODE-%d] Clock: %d\n", n.ID, n.state().Clock)
+
+	case "pause":
+		n.Gate.PauseNode(n.ID)
+
+	case "step":
+		n.Gate.StepNode(n.ID)
+
+	case "resume":
+		n.Gate.ResumeNode(n.ID)
+
+	case "trace":
+		n.setTracing(args)
+
+	case "help":
+		for _, c := range consoleCommands {
+			fmt.Printf("  %-32s %s\n", c[0], c[1])
+		}
+
+	default:
+		fmt.Printf("[NODE-%d] Unknown command %q, type help for the commands\n", n.ID, command)
+	}
+}
+
+// Function to print lines of the state of the node
+func (n *Node) printLines(lines []string) {
+	for _, line := range lines {
+		fmt.Printf("[NODE-%d] %s\n", n.ID, line)
+	}
+}
+
+// Function to turn the trace on or off from the console
+func (n *Node) setTracing(args []string) {
+	if len(args) == 0 || (args[0] != "on" && args[0] != "off") {
+		fmt.Printf("[NODE-%d] Usage: trace on [dir] | trace off\n", n.ID)
+		return
+	}
+
+	if args[0] == "off" {
+		n.Tracer.Stop()
+		fmt.Printf("[NODE-%d] Stopped recording the trace\n", n.ID)
+		return
+	}
+
+	dir := DEFAULT_TRACE_DIR
+	if len(args) > 1 {
+		dir = args[1]
+	}
+	path, err := n.Tracer.Start(dir, n.ID)
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while opening the trace: %s\n", n.ID, err)
+		return
+	}
+	fmt.Printf("[NODE-%d] Recording the trace in %s\n", n.ID, path)
+}
@@ -0,0 +1,22 @@
+package node
+
+import (
+	"common/storage"
+	"fmt"
+)
+
+// Function to write to the fenced storage server with the fencing token of a held resource. Returns false if
+// the server rejected the token as stale.
+func (n *Node) WriteStorage(name string, data string) (bool, error) {
+	n.Lock.Lock()
+	token := n.resource(name).Fence
+	n.Lock.Unlock()
+
+	// The server is dialed like a peer, so it is reached over TLS and on Unix domain sockets as well
+	client, err := dial(n.Storage)
+	if err != nil {
+		return false, fmt.Errorf("error in dialing: %s", err)
+	}
+	defer client.Close()
+	return storage.Send(client, storage.Write{Resource: name, Token: token, Node: n.ID, Data: data})
+}
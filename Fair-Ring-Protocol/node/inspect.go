@@ -0,0 +1,49 @@
+package node
+
+import (
+	"common/inspect"
+	"fmt"
+)
+
+// Function to fill in the part of the state shown by the console and the dashboard
+func summarize(state *State) {
+	state.Phase = inspect.IDLE
+	state.Token = len(state.Tokens) > 0
+	for _, r := range state.Resources {
+		if r.Held {
+			state.Phase = inspect.IN_CS
+			state.Token = true
+		} else if r.Request && state.Phase == inspect.IDLE {
+			state.Phase = inspect.REQUESTING
+		}
+	}
+	state.Status = statusLines(*state)
+	state.Queues = queueLines(*state)
+}
+
+// Function to describe the requests of a node and the tokens it holds
+func statusLines(state State) []string {
+	lines := []string{}
+	for _, r := range state.Resources {
+		switch {
+		case r.Held:
+			lines = append(lines, fmt.Sprintf("%s: holding token %d with fencing token %d", r.Name, r.TokenID, r.Fence))
+		case r.Request && r.Reserved != -1:
+			lines = append(lines, fmt.Sprintf("%s: requesting with timestamp %d, reserved token %d", r.Name, r.ReqTime, r.Reserved))
+		case r.Request:
+			lines = append(lines, fmt.Sprintf("%s: requesting, waiting for a token", r.Name))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: idle", r.Name))
+		}
+	}
+	return lines
+}
+
+// Function to describe the tokens parked at a node
+func queueLines(state State) []string {
+	lines := []string{}
+	for _, token := range state.Tokens {
+		lines = append(lines, fmt.Sprintf("Parked token %d of %s", token.TokenID, token.Resource))
+	}
+	return lines
+}
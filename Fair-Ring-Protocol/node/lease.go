@@ -0,0 +1,168 @@
+package node
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// Extra time the bootstrap node waits after a lease should have expired, to make up for clocks that run at slightly different speeds
+	DEFAULT_SKEW_MARGIN = 500 * time.Millisecond
+	// How often the bootstrap node looks for expired leases
+	LEASE_CHECK_INTERVAL = 500 * time.Millisecond
+)
+
+// Lease of a node on a token it holds, kept by the bootstrap node
+type TokenLease struct {
+	Holder int // ID of the node holding the token
+	Generation int // Generation of the held token
+	Fence int64 // Entry counter of the held token
+	Expiry time.Time // Time after which the token is regenerated
+}
+
+// Function to renew the lease on a held token with the bootstrap node until the token is released.
+// The node counts its own lease from the moment it sends the renewal, so it always expires before the lease
+// seen by the bootstrap node.
+func (n *Node) renewLease(name string, token Message) {
+	for {
+		n.Lock.Lock()
+		r := n.resource(name)
+		if r.Held == nil || r.Held.TokenID != token.TokenID || r.Held.Generation != token.Generation {
+			n.Lock.Unlock()
+			return
+		}
+		r.LeaseExpiry = time.Now().Add(n.TTL)
+		n.Lock.Unlock()
+
+		_, err := CallByRPC(n.Bootstrap, "Node.RenewLease", Message{ID: n.ID, TokenID: token.TokenID, Generation: token.Generation, Fence: token.Fence, TTL: int(n.TTL / time.Second), Resource: name})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while renewing the lease on token %d of %s: %s\n", n.ID, token.TokenID, name, err)
+		}
+		time.Sleep(n.TTL / 3)
+	}
+}
+
+// Function to take or extend the lease of a node on a token. Only used by the bootstrap node.
+func (n *Node) RenewLease(message Message, reply *Message) error {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	r := n.resource(message.Resource)
+	if message.Generation < r.Generations[message.TokenID] {
+		// The token was already regenerated, so the node holds a stale copy
+		fmt.Printf("[NODE-%d] Ignoring the renewal of NODE-%d on the stale token %d of %s\n", n.ID, message.ID, message.TokenID, message.Resource)
+		return nil
+	}
+	r.Leases[message.TokenID] = TokenLease{Holder: message.ID, Generation: message.Generation, Fence: message.Fence, Expiry: time.Now().Add(time.Duration(message.TTL) * time.Second + n.SkewMargin)}
+	return nil
+}
+
+// Function to end the lease of a node on a token it released. Only used by the bootstrap node.
+func (n *Node) EndLease(message Message, reply *Message) error {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	r := n.resource(message.Resource)
+	lease, ok := r.Leases[message.TokenID]
+	if ok && lease.Holder == message.ID && lease.Generation == message.Generation {
+		delete(r.Leases, message.TokenID)
+	}
+	return nil
+}
+
+// Function to regenerate every token whose holder stopped renewing its lease. Only used by the bootstrap node.
+func (n *Node) watchLeases() {
+	for {
+		time.Sleep(LEASE_CHECK_INTERVAL)
+
+		n.Lock.Lock()
+		tokens := []Message{}
+		for name, r := range n.Resources {
+			for tokenID, lease := range r.Leases {
+				if !time.Now().After(lease.Expiry) {
+					continue
+				}
+				delete(r.Leases, tokenID)
+
+				// A token of a newer generation replaces the lost one, and the old one is dropped wherever it turns up.
+				// It keeps the entry counter of the lost token so that the fencing tokens of the next holders are larger.
+				r.Generations[tokenID] = lease.Generation + 1
+				n.Clock++
+				tokens = append(tokens, Message{ID: n.ID, Clock: n.Clock, ReqTime: -1, ReqID: -1, TokenID: tokenID, Resource: name, LastActive: n.ID, Generation: lease.Generation + 1, Fence: lease.Fence})
+				fmt.Printf("[NODE-%d] Lease of NODE-%d on token %d of %s expired. Regenerating the token with generation %d\n", n.ID, lease.Holder, tokenID, name, lease.Generation + 1)
+
+				// Stop counting the holder as inside the critical section
+				n.notifyExited(lease.Holder, name)
+				if lease.Holder < len(n.Finished) {
+					n.Finished[lease.Holder] = true
+				}
+			}
+		}
+		n.Lock.Unlock()
+
+		for _, token := range tokens {
+			go func() {
+				// Every node must know the new generation before the token can meet the old copy
+				n.broadcastGeneration(token)
+				err := n.callSuccessor("Node.ReceiveToken", token)
+				if err != nil {
+					fmt.Printf("[NODE-%d] Error occurred while sending token %d of %s: %s\n", n.ID, token.TokenID, token.Resource, err)
+				}
+			}()
+		}
+	}
+}
+
+// Function to send the generation of a regenerated token to every node of the ring and wait for their answers.
+// Only used by the bootstrap node.
+func (n *Node) broadcastGeneration(token Message) {
+	if n.Members == nil {
+		return
+	}
+	var sent sync.WaitGroup
+	for ID, IP := range n.Members() {
+		if ID == n.ID {
+			continue
+		}
+		sent.Add(1)
+		go func() {
+			defer sent.Done()
+			_, err := CallByRPC(IP, "Admin.SetGeneration", Message{ID: n.ID, TokenID: token.TokenID, Generation: token.Generation, Resource: token.Resource})
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while sending generation %d of token %d of %s to NODE-%d: %s\n", n.ID, token.Generation, token.TokenID, token.Resource, ID, err)
+			}
+		}()
+	}
+	sent.Wait()
+}
+
+// Function to record the generation of a regenerated token and drop an older copy of the token parked at the node
+func (n *Node) setGeneration(message Message, reply *Message) error {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	r := n.resource(message.Resource)
+	if message.Generation <= r.Generations[message.TokenID] {
+		return nil
+	}
+	r.Generations[message.TokenID] = message.Generation
+
+	tokens := []Message{}
+	for _, token := range n.Tokens {
+		if token.Resource == message.Resource && token.TokenID == message.TokenID {
+			fmt.Printf("[NODE-%d] Dropping the stale token %d of %s from generation %d\n", n.ID, token.TokenID, token.Resource, token.Generation)
+			continue
+		}
+		tokens = append(tokens, token)
+	}
+	n.Tokens = tokens
+	return nil
+}
+
+// Function to get the time until which the node may use a resource it holds. The zero time is returned if leases are off.
+func (n *Node) LeaseExpiry(name string) time.Time {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	return n.resource(name).LeaseExpiry
+}
@@ -0,0 +1,13 @@
+package node
+
+import (
+	"context"
+	"time"
+)
+
+// Function to acquire the lock on a resource, giving up after the timeout
+func (n *Node) LockWithTimeout(name string, timeout time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return n.AcquireContext(ctx, name)
+}
@@ -8,6 +8,18 @@ type Message struct {
 	ID int // ID of the node
 	IP string // Source IP
 	ReqTime int // timestamp assigned to the token
+	ReqID int // ID of the node whose request timestamp is assigned to the token
+	TokenID int // ID of the token when there are k tokens
 	Clock int
 	NumRequests int
+	K int // number of nodes that can be in the critical section at the same time
+	LastActive int // ID of the last node that requested for or used the token
+	Resource string // name of the resource the token or the wakeup signal belongs to
+	NumResources int // number of resources the requesting nodes are spread over
+	ResourcesPerRequest int // number of resources each request takes at once
+	Timeout int // seconds a requesting node waits for the lock before giving up, 0 to wait forever
+	TTL int // seconds a held token stays valid without being renewed, 0 to turn leases off
+	Generation int // incremented every time the bootstrap node regenerates the token
+	Fence int64 // entry counter of the token, incremented every time a node enters the critical section with it
+	Secret string // shared secret of the admin service, only set on admin calls
 }
\ No newline at end of file
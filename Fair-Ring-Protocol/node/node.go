@@ -1,10 +1,22 @@
 package node
 
 import (
+	"common/address"
+	"common/admin"
+	"common/inspect"
+	"common/mux"
+	"common/safety"
+	"common/trace"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/rpc"
 	"os"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -12,113 +24,216 @@ import (
  type Node struct {
 	ID int
 	IP string
+	Bind string // address the node listens on, IP if empty
+	Bootstrap string // address of the bootstrap node
 	Successor string // IP of the successor of the node
+	Members func() map[int]string // returns the addresses of the nodes in the ring by ID, from the fixed peer list or the registry
 	Clock int
-	Request bool // boolean to check if the node is requesting for the token
-	ReqTime int // timestamp at which the node requests for the token
+	Request bool // boolean to check if the node should request for the critical section
+	Requested []string // resources requested by the node
+	Resources map[string]*Resource // state of the lock on every resource the node has seen
+	NumResources int // number of resources started by the bootstrap node
 	Finished []bool // boolean to check if the nodes in a network has finished executing the critical section
+	K int // number of tokens per resource, i.e. the number of nodes that can be in the critical section at the same time
+	Timeout time.Duration // how long the node waits for the lock before giving up, 0 to wait forever
+	TTL time.Duration // how long a held token stays valid without being renewed, 0 to turn leases off
+	SkewMargin time.Duration // extra time the bootstrap node waits before treating a lease as expired
+	Storage string // address of the fenced storage server written to in the critical section, empty for none
+	Tokens []Message // tokens parked at the node while no node is requesting for them
+	Safety safety.Checker // only used by the bootstrap node
+	Tracer trace.Tracer
+	Gate inspect.Gate // holds back the tokens and wakeup signals from other nodes while the node is paused and keeps track of the ones in flight
 	Lock sync.Mutex
  }
 
- const (
-	LOCALHOST = "127.0.0.1:"
+ // Delays that slow the protocol down so that its output can be followed
+ var (
+	MessageDelay = 1 * time.Second // time a node waits before handling a message or passing on a token
+	CSDuration = 2 * time.Second // time a node spends in the critical section
  )
 
  // Dummy critical section function
- func (n *Node) CriticalSection() {
-	n.Lock.Lock()
-	defer n.Lock.Unlock()
+ func (n *Node) CriticalSection(names ...string) {
+	// Notify the bootstrap node that the current node is entering the critical section
+	for _, name := range names {
+		_, err := CallByRPC(n.Bootstrap, "Node.NotifyEntered", Message{ID: n.ID, Resource: name})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+		}
+	}
+
 	// Simulate entering the critical section
-	fmt.Printf("[NODE-%d] Entering the critical section\n", n.ID)
-	time.Sleep(2 * time.Second)
-	fmt.Printf("[NODE-%d] Completed the critical section\n", n.ID)
+	fmt.Printf("[NODE-%d] Entering the critical section of %v\n", n.ID, names)
+	time.Sleep(CSDuration)
+
+	// Write to the fenced storage server at the end of the critical section
+	for _, name := range names {
+		if n.Storage == "" {
+			break
+		}
+		_, err := n.WriteStorage(name, fmt.Sprintf("written by node %d", n.ID))
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while writing to the storage server: %s\n", n.ID, err)
+		}
+	}
+	fmt.Printf("[NODE-%d] Completed the critical section of %v\n", n.ID, names)
 
 	// Notify the bootstrap node that the current node has finished executing the critical section
+	n.Lock.Lock()
 	n.Clock++
-	_, err := CallByRPC(LOCALHOST + "8000", "Node.NotifyFinished", Message{ID: n.ID})
-	if err != nil {
-		fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+	n.Lock.Unlock()
+	for _, name := range names {
+		_, err := CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID, Resource: name})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+		}
 	}
  }
 
  // Function to start the RPC server
  func (n *Node) StartRPCServer() {
 	rpc.Register(n)
+	rpc.RegisterName(admin.SERVICE, &Admin{n: n})
 
-	listener, err := net.Listen("tcp", n.IP)
+	listener, err := address.Listen(n.listenAddress())
 	if err != nil {
 		fmt.Printf("[NODE-%d] could not start listening: %s\n", n.ID, err)
 		os.Exit(1)
 	}
 	defer listener.Close()
+	if Transport == GRPC_TRANSPORT {
+		// The node keeps serving net/rpc on the same address, for the dashboard and the calls whose reply is not a Message
+		grpcConns, rpcConns := mux.Split(listener, serverTLS)
+		go n.startGRPCServer(grpcConns)
+		listener = rpcConns
+	} else if serverTLS != nil {
+		listener = tls.NewListener(listener, serverTLS)
+	}
 
 	fmt.Printf("[NODE-%d] Node is running on %s\n", n.ID, n.IP)
 
 	for {
 		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
 		if err != nil {
 			fmt.Printf("[NODE-%d] accept error: %s\n", n.ID, err)
 			continue
 		}
-		go rpc.ServeConn(conn)
+		go n.serveConn(conn)
 	}
  }
 
- // Initialize the token passing
+ // Initialize the token passing with k tokens for every resource
  func (n *Node) StartTokenPassing() {
-	n.Clock++
-	message := Message{ID: n.ID, Clock: n.Clock, ReqTime: -1}
+	for r := 0; r < max(n.NumResources, 1); r++ {
+		for i := 0; i < n.K; i++ {
+			n.Clock++
+			message := Message{ID: n.ID, Clock: n.Clock, ReqTime: -1, ReqID: -1, TokenID: i, Resource: ResourceName(r), LastActive: n.ID}
 
-	// Send the token to the successor concurrently
-	go func (){
-		_, err := CallByRPC(n.Successor, "Node.ReceiveToken", message)
+			// Send the token to the successor concurrently
+			go func (){
+				err := n.callSuccessor("Node.ReceiveToken", message)
 
-		if err != nil {
-			fmt.Printf("[NODE-%d] Error occurred while sending token: %s\n", n.ID, err)
-			return 
+				if err != nil {
+					fmt.Printf("[NODE-%d] Error occurred while sending token %d of %s: %s\n", n.ID, message.TokenID, message.Resource, err)
+					return 
+				}
+			}()
 		}
-	}()
+	}
  }
 
  // Function to receive the token
  func (n *Node) ReceiveToken(message Message, reply *Message) error {
-	time.Sleep(1 * time.Second)
-	fmt.Printf("[NODE-%d] Received token from NODE-%d\n", n.ID, message.ID)
+	n.deliver("TOKEN", message)
+	fmt.Printf("[NODE-%d] Received token %d of %s from NODE-%d\n", n.ID, message.TokenID, message.Resource, message.ID)
+
+	n.Lock.Lock()
 	n.Clock = max(n.Clock, message.Clock) + 1
+	r := n.resource(message.Resource)
 
-	if n.Request {
+	if message.Generation < r.Generations[message.TokenID] {
+		// The token was regenerated after the lease of its holder expired
+		fmt.Printf("[NODE-%d] Dropping the stale token %d of %s from generation %d\n", n.ID, message.TokenID, message.Resource, message.Generation)
+		n.Lock.Unlock()
+		return nil
+	}
+	r.Generations[message.TokenID] = message.Generation
+
+	if message.ReqID == n.ID && (!r.Request || message.ReqTime != r.ReqTime) {
+		// The request carried by the token was cancelled, so free the token for the other nodes
+		fmt.Printf("[NODE-%d] Clearing the cancelled request with timestamp %d from token %d of %s\n", n.ID, message.ReqTime, message.TokenID, message.Resource)
+		message.ReqTime = -1
+		message.ReqID = -1
+	}
+
+	active := r.Request || r.Wanted
+	r.Wanted = false
+	if active {
+		message.LastActive = n.ID
+	} else if message.LastActive == n.ID && message.ReqTime == -1 {
+		// The token went around the ring without any node requesting for it, so park it until a node wakes it up
+		fmt.Printf("[NODE-%d] No node requested for token %d of %s during the last lap. Parking the token\n", n.ID, message.TokenID, message.Resource)
+		message.ID = n.ID
+		n.Tokens = append(n.Tokens, message)
+		n.Lock.Unlock()
+		return nil
+	}
+
+	if r.Request {
 
 		// Update the logical clock
-		if n.ReqTime == -1 {
-			n.ReqTime = n.Clock
+		if r.ReqTime == -1 {
+			r.ReqTime = n.Clock
 		}
 
-		fmt.Printf("[NODE-%d] Requesting for the token at timestamp-%d\n", n.ID, n.ReqTime)
+		fmt.Printf("[NODE-%d] Requesting for a token of %s at timestamp-%d\n", n.ID, r.Name, r.ReqTime)
+
+		ownRequest := message.ReqTime == r.ReqTime && message.ReqID == n.ID
+		if r.Reserved == message.TokenID && !ownRequest {
+			// The reservation on this token was taken over by an earlier request
+			r.Reserved = -1
+		}
 
-		// check the values of the timestamp from the message
-		if message.ReqTime == -1 {
-			message.ReqTime = n.ReqTime
+		// A node only competes for one of the k tokens at a time
+		if r.Reserved == -1 || r.Reserved == message.TokenID {
+			// check the values of the timestamp from the message
+			if message.ReqTime == -1 {
+				message.ReqTime = r.ReqTime
+				message.ReqID = n.ID
+				r.Reserved = message.TokenID
 
-		}else if message.ReqTime == n.ReqTime {
-			// Run the critical section
-			n.CriticalSection()
-			n.Request = false // Reset the request Flag
-			message.ReqTime = -1 // Reset the timestamp
-			n.ReqTime = -1 // Reset the timestamp
+			} else if ownRequest {
+				// Keep the token until the node releases the resource. Every entry counts up the fencing token.
+				message.Fence++
+				r.Fence = message.Fence
+				r.Held = &message
+				r.Granted <- true
+				if n.TTL > 0 {
+					go n.renewLease(r.Name, message)
+				}
+				n.Lock.Unlock()
+				return nil
 
-		} else if n.ReqTime < message.ReqTime {
-			message.ReqTime = n.ReqTime
+			} else if r.ReqTime < message.ReqTime || (r.ReqTime == message.ReqTime && n.ID < message.ReqID) {
+				message.ReqTime = r.ReqTime
+				message.ReqID = n.ID
+				r.Reserved = message.TokenID
+			}
 		}
 	}
 
 	message.ID = n.ID
 	message.Clock = n.Clock + 1
+	n.Lock.Unlock()
 
 	// Send the token to the successor concurrently
 	go func() {
-		_, err := CallByRPC(n.Successor, "Node.ReceiveToken", message)
+		err := n.callSuccessor("Node.ReceiveToken", message)
 		if err != nil {
-			fmt.Printf("[NODE-%d] Error occurred while sending token: %s\n", n.ID, err)
+			fmt.Printf("[NODE-%d] Error occurred while sending token %d of %s: %s\n", n.ID, message.TokenID, message.Resource, err)
 			return
 		}
 	}()
@@ -126,32 +241,354 @@ import (
 	return nil 
  }
 
+ // Function to acquire the lock on a resource after the token passing has started, waking a parked token up if needed.
+ // Blocks until the node holds a token of the resource and returns the fencing token of the entry.
+ func (n *Node) Acquire(name string) (int64, error) {
+	return n.AcquireContext(context.Background(), name)
+ }
+
+ // Function to acquire the lock on a resource until the node holds a token of it or the context is done
+ func (n *Node) AcquireContext(ctx context.Context, name string) (int64, error) {
+	n.Lock.Lock()
+	if !n.hasTokens(name) {
+		n.Lock.Unlock()
+		return 0, fmt.Errorf("[NODE-%d] No tokens were started for %s", n.ID, name)
+	}
+
+	r := n.resource(name)
+	if r.Request {
+		n.Lock.Unlock()
+		return 0, fmt.Errorf("[NODE-%d] Already requesting %s", n.ID, name)
+	}
+	r.Request = true
+	r.Granted = make(chan bool, 1)
+	granted := r.Granted
+	n.trace(WAIT, name)
+
+	if !n.resumeToken(name) {
+		// A parked token could be anywhere on the ring, so the wakeup signal is passed along the successors
+		go n.forwardWakeup(Message{ID: n.ID, Resource: name})
+	}
+	n.Lock.Unlock()
+
+	select {
+	case <-granted:
+	case <-ctx.Done():
+		if n.cancel(name) {
+			return 0, ctx.Err()
+		}
+		// The token arrived before the request could be cancelled
+	}
+	n.trace(ACQUIRED, name)
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	return r.Fence, nil
+ }
+
+ // Function to withdraw a request that has not been granted yet. Returns false if the node already holds a token.
+ // The reservation of the request travels with the token, so it is cleared once the token comes back to the node.
+ func (n *Node) cancel(name string) bool {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	r := n.resource(name)
+	if r.Held != nil {
+		return false
+	}
+	fmt.Printf("[NODE-%d] Cancelling the request for a token of %s with timestamp %d\n", n.ID, name, r.ReqTime)
+	n.trace(CANCELLED, name)
+	r.Request = false
+	r.ReqTime = -1
+	r.Reserved = -1
+	return true
+ }
+
+ // Function to release the lock on a resource and pass the token on
+ func (n *Node) Release(name string) {
+	n.Lock.Lock()
+	r := n.resource(name)
+	if r.Held == nil {
+		n.Lock.Unlock()
+		return
+	}
+	message, pass := n.release(r)
+	n.Lock.Unlock()
+
+	if pass {
+		n.passToken(message)
+	}
+ }
+
+ // Function to release the lock on a resource only if the node holds it with the fencing token. The token is checked
+ // and the token given up under one lock, so that a client that lost the lock cannot release the entry of a newer holder.
+ func (n *Node) releaseIfFence(name string, token int64) error {
+	n.Lock.Lock()
+	r := n.resource(name)
+	if r.Held == nil {
+		n.Lock.Unlock()
+		return fmt.Errorf("node %d does not hold %s", n.ID, name)
+	}
+	if r.Fence != token {
+		n.Lock.Unlock()
+		return fmt.Errorf("%s is not held with the fencing token %d", name, token)
+	}
+	message, pass := n.release(r)
+	n.Lock.Unlock()
+
+	if pass {
+		n.passToken(message)
+	}
+	return nil
+ }
+
+ // Function to give up the token held for a resource. Returns the token to pass on, or false if the token is dropped
+ // because its lease expired. Must be called with the lock held.
+ func (n *Node) release(r *Resource) (Message, bool) {
+	message := *r.Held
+	r.Held = nil
+	n.trace(RELEASED, r.Name)
+	r.Request = false // Reset the request Flag
+	r.ReqTime = -1 // Reset the timestamp
+	r.Reserved = -1
+
+	expired := !r.LeaseExpiry.IsZero() && time.Now().After(r.LeaseExpiry)
+	r.LeaseExpiry = time.Time{}
+	if expired {
+		// The bootstrap node may have regenerated the token already, so passing it on could put two copies in the ring
+		fmt.Printf("[NODE-%d] WARNING: the lease on token %d of %s expired before the release. Dropping the token\n", n.ID, message.TokenID, r.Name)
+		return message, false
+	}
+
+	message.ReqTime = -1 // Reset the timestamp
+	message.ReqID = -1
+	message.ID = n.ID
+	n.Clock++
+	message.Clock = n.Clock
+	return message, true
+ }
+
+ // Function to end the lease on a released token and send the token to the successor. Must be called without the lock held.
+ func (n *Node) passToken(message Message) {
+	if n.TTL > 0 {
+		go func() {
+			_, err := CallByRPC(n.Bootstrap, "Node.EndLease", Message{ID: n.ID, TokenID: message.TokenID, Generation: message.Generation, Resource: message.Resource})
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while ending the lease on token %d of %s: %s\n", n.ID, message.TokenID, message.Resource, err)
+			}
+		}()
+	}
+
+	// Send the token to the successor concurrently
+	go func() {
+		err := n.callSuccessor("Node.ReceiveToken", message)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while sending token %d of %s: %s\n", n.ID, message.TokenID, message.Resource, err)
+		}
+	}()
+ }
+
+ // Function to run the critical section of the node's resources
+ func (n *Node) requestCriticalSection() {
+	ctx := context.Background()
+	if n.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.Timeout)
+		defer cancel()
+	}
+
+	_, err := n.AcquireAllContext(ctx, n.Requested)
+	if err == context.DeadlineExceeded {
+		fmt.Printf("[NODE-%d] Gave up on %v after waiting for %v\n", n.ID, n.Requested, n.Timeout)
+		n.Request = false
+		_, err = CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+		}
+		return
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	n.CriticalSection(n.Requested...)
+	n.ReleaseAll(n.Requested)
+	n.Request = false
+ }
+
  // Function to set the successor of the node
- func (n *Node) SetSuccessor(message Message, reply *Message) error {
+ func (n *Node) setSuccessor(message Message, reply *Message) error {
 	n.Successor = message.IP
 	fmt.Printf("[NODE-%d] Successor set to %s\n", n.ID, n.Successor)
 	return nil
 }
 
 func (n *Node)NotifyFinished(message Message, reply *Message) error {
-	n.Finished[message.ID] = true
+	n.notifyExited(message.ID, message.Resource)
+
+	// Requests made with Acquire outside of the workload are not measured
+	n.Lock.Lock()
+	if message.ID < len(n.Finished) {
+		n.Finished[message.ID] = true
+	}
+	n.Lock.Unlock()
 	return nil
 }
 
 // Function to decide whether the node requests for vote or not
-func (n *Node) SetRequesting(message Message, reply *Message) error {
+func (n *Node) setRequesting(message Message, reply *Message) error {
+	n.K = max(message.K, 1)
+	n.NumResources = max(message.NumResources, 1)
+	n.Timeout = time.Duration(message.Timeout) * time.Second
+	n.TTL = time.Duration(message.TTL) * time.Second
+	if n.ID == 0 && n.TTL > 0 {
+		go n.watchLeases()
+	}
+	n.Requested = []string{}
+	for i := 0; i < min(max(message.ResourcesPerRequest, 1), n.NumResources); i++ {
+		n.Requested = append(n.Requested, ResourceName((n.ID + i) % n.NumResources))
+	}
 	n.Request = n.ID < message.NumRequests
 	if n.Request {
-		fmt.Printf("[NODE-%d] Node will request for the critical section\n", n.ID)
+		fmt.Printf("[NODE-%d] Node will request for %v\n", n.ID, n.Requested)
+		go n.requestCriticalSection()
 	} else {
 		fmt.Printf("[NODE-%d] Node will not request for the critical section\n", n.ID)
 	}
 	return nil
 }
 
+// Function to receive the wakeup signal sent by a requesting node
+func (n *Node) WakeToken(message Message, reply *Message) error {
+	n.deliver("WAKEUP", message)
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	if n.resumeToken(message.Resource) {
+		fmt.Printf("[NODE-%d] Received a wakeup signal for %s from NODE-%d. Resumed the token passing\n", n.ID, message.Resource, message.ID)
+		return nil
+	}
+
+	if message.ID == n.ID {
+		// The signal went around the ring, so the tokens are already circulating
+		return nil
+	}
+
+	// Make sure the token does not park here in case it is just behind the signal
+	n.resource(message.Resource).Wanted = true
+	go n.forwardWakeup(message)
+	return nil
+}
+
+// Function to pass the wakeup signal to the successor
+func (n *Node) forwardWakeup(message Message) {
+	err := n.callSuccessor("Node.WakeToken", message)
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while sending the wakeup signal: %s\n", n.ID, err)
+	}
+}
+
+// Function to send one of the parked tokens of a resource to the successor. Returns false if no token of the
+// resource is parked at the node. Must be called with the lock held.
+func (n *Node) resumeToken(name string) bool {
+	i := 0
+	for i < len(n.Tokens) && n.Tokens[i].Resource != name {
+		i++
+	}
+	if i == len(n.Tokens) {
+		return false
+	}
+	token := n.Tokens[i]
+	n.Tokens = append(n.Tokens[:i], n.Tokens[i+1:]...)
+
+	n.Clock++
+	token.ID = n.ID
+	token.Clock = n.Clock
+	token.LastActive = n.ID
+
+	go func() {
+		err := n.callSuccessor("Node.ReceiveToken", token)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while sending token: %s\n", n.ID, err)
+		}
+	}()
+	return true
+}
+
+// Function to call a method on the successor. The nodes of the ring are numbered in order, so a successor that
+// cannot be reached is assumed to have crashed and the node after it becomes the new successor.
+func (n *Node) callSuccessor(method string, message Message) error {
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		n.Lock.Lock()
+		successor := n.Successor
+		n.Lock.Unlock()
+
+		_, err = CallByRPC(successor, method, message)
+		if err == nil || !strings.HasPrefix(err.Error(), "error in dialing") {
+			return err
+		}
+
+		next := n.nextInRing(successor)
+		n.Lock.Lock()
+		if n.Successor == successor {
+			n.Successor = next
+			fmt.Printf("[NODE-%d] Successor %s cannot be reached. New successor: %s\n", n.ID, successor, next)
+		}
+		n.Lock.Unlock()
+	}
+	return err
+}
+
+// Function to find the first node after the given one in the ring that can be reached. The ring follows the order
+// of the IDs of its members, going back to the lowest ID after the highest one.
+func (n *Node) nextInRing(IP string) string {
+	members := map[int]string{}
+	if n.Members != nil {
+		members = n.Members()
+	}
+	members[n.ID] = n.IP
+
+	IDs := []int{}
+	for ID := range members {
+		IDs = append(IDs, ID)
+	}
+	sort.Ints(IDs)
+
+	// Start after the unreachable node, or after the node itself if it is not a member
+	start := slices.Index(IDs, n.ID)
+	for i, ID := range IDs {
+		if members[ID] == IP {
+			start = i
+		}
+	}
+	for i := 1; i <= len(IDs); i++ {
+		ID := IDs[(start + i) % len(IDs)]
+		if ID == n.ID || members[ID] == IP {
+			continue
+		}
+		conn, err := address.Dial(members[ID], time.Second)
+		if err != nil {
+			continue
+		}
+		conn.Close()
+		return members[ID]
+	}
+
+	// No other node can be reached, so the ring closes on the node itself
+	return n.IP
+}
+
 // Utility function to call RPC methods
 func CallByRPC(IP string, method string, message Message) (Message, error) {
-	client, err := rpc.Dial("tcp", IP)
+	if admin.IsAdmin(method) {
+		message.Secret = admin.Secret
+	}
+	if Transport == GRPC_TRANSPORT {
+		return callByGRPC(IP, method, message)
+	}
+	client, err := dial(IP)
 	if err != nil {
 		return Message{}, fmt.Errorf("error in dialing: %s", err)
 	}
@@ -163,4 +600,12 @@ func CallByRPC(IP string, method string, message Message) (Message, error) {
 		return Message{}, fmt.Errorf("error in calling %s: %s", method, err)
 	}
 	return reply, nil
-}
\ No newline at end of file
+}
+
+// Function to get the address the node listens on
+func (n *Node) listenAddress() string {
+	if n.Bind != "" {
+		return n.Bind
+	}
+	return n.IP
+}
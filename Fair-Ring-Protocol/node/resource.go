@@ -0,0 +1,90 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// State of the lock on a single named resource. Every resource has its own k tokens circulating the ring,
+// so requests for different resources never wait for each other.
+type Resource struct {
+	Name string
+	Request bool // boolean to check if the node is requesting for a token of the resource
+	ReqTime int // timestamp at which the node requests for the token
+	Reserved int // ID of the token carrying the request of the node, -1 if none
+	Wanted bool // boolean to check if a wakeup signal for the resource passed through the node, so that its token does not park here
+	Held *Message // token held by the node while it is in the critical section
+	Fence int64 // fencing token of the current entry into the critical section
+	Granted chan bool // signalled once the node holds a token of the resource
+	LeaseExpiry time.Time // time until which the node may use the held token, zero if leases are off
+	Generations map[int]int // latest generation of every token of the resource, older copies are dropped
+	Leases map[int]TokenLease // leases on the held tokens of the resource, only kept by the bootstrap node
+}
+
+// Name of the i-th resource started by the bootstrap node
+func ResourceName(i int) string {
+	return fmt.Sprintf("resource-%d", i)
+}
+
+// Function to get the state of a resource, creating it on first use. Must be called with the lock held.
+func (n *Node) resource(name string) *Resource {
+	if n.Resources == nil {
+		n.Resources = make(map[string]*Resource)
+	}
+
+	r, ok := n.Resources[name]
+	if !ok {
+		r = &Resource{Name: name, ReqTime: -1, Reserved: -1, Generations: make(map[int]int), Leases: make(map[int]TokenLease)}
+		n.Resources[name] = r
+	}
+	return r
+}
+
+// Function to check if the bootstrap node started tokens for a resource
+func (n *Node) hasTokens(name string) bool {
+	for i := 0; i < max(n.NumResources, 1); i++ {
+		if ResourceName(i) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Function to acquire the locks on a set of resources. The locks are always taken in the order of their names,
+// so two nodes can never wait for each other's locks in a cycle.
+func (n *Node) AcquireAll(names []string) (map[string]int64, error) {
+	return n.AcquireAllContext(context.Background(), names)
+}
+
+// Function to acquire the locks on a set of resources in order until they are all granted or the context is done.
+// The locks that were already granted are released if the context is done. Returns the fencing token of every resource.
+func (n *Node) AcquireAllContext(ctx context.Context, names []string) (map[string]int64, error) {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	sorted = slices.Compact(sorted)
+
+	tokens := make(map[string]int64)
+	for i, name := range sorted {
+		token, err := n.AcquireContext(ctx, name)
+		if err != nil {
+			n.ReleaseAll(sorted[:i])
+			return nil, err
+		}
+		tokens[name] = token
+	}
+	return tokens, nil
+}
+
+// Function to release the locks on a set of resources in the reverse order of acquisition
+func (n *Node) ReleaseAll(names []string) {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	sorted = slices.Compact(sorted)
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		n.Release(sorted[i])
+	}
+}
@@ -0,0 +1,25 @@
+package node
+
+import (
+	"common/safety"
+	"fmt"
+)
+
+// Function to record that a node entered the critical section
+func (n *Node) NotifyEntered(message Message, reply *Message) error {
+	err := n.Safety.Enter(n.K, message.Resource, message.ID, safety.WRITER)
+	if err != nil {
+		fmt.Printf("[NODE-%d] SAFETY VIOLATION: %s\n", n.ID, err)
+	}
+	return nil
+}
+
+// Function to record that a node left the critical section
+func (n *Node) notifyExited(ID int, resource string) {
+	n.Safety.Exit(resource, ID)
+}
+
+// Function to print the result of the safety check
+func (n *Node) PrintSafetyCheck() {
+	fmt.Println(n.Safety.Result(n.K))
+}
@@ -0,0 +1,66 @@
+package node
+
+import (
+	"common/inspect"
+	"slices"
+	"sort"
+)
+
+// What a node knows about the ring and its tokens, returned by Node.GetState
+type State struct {
+	inspect.Snapshot // part of the state shown by the console and the dashboard, the same in every protocol
+	Bootstrap string // address of the bootstrap node
+	Successor string // IP of the successor of the node
+	K int // number of tokens per resource
+	Request bool // if the node should request for the critical section
+	Finished []bool // if each node has finished, as far as the node knows
+	Tracing bool // if the trace is being recorded
+	Tokens []Message // tokens parked at the node while no node is requesting for them
+	Resources []ResourceState // every resource the node has seen, in the order of their names
+}
+
+// State of the lock on a single resource, as part of the state of a node
+type ResourceState struct {
+	Name string
+	Request bool // if the node is requesting a token of the resource
+	ReqTime int // timestamp at which the node requests for the token
+	Reserved int // ID of the token carrying the request of the node, -1 if none
+	Wanted bool // if a wakeup signal for the resource passed through the node
+	Held bool // if the node holds a token of the resource and is in the critical section
+	TokenID int // ID of the held token, -1 if none
+	Fence int64 // fencing token of the current entry into the critical section
+}
+
+// Function to report what the node knows about the ring and its tokens. It does not change the node, so
+// tests, dashboards and the console can call it at any time.
+func (n *Node) GetState(message Message, reply *State) error {
+	*reply = n.state()
+	return nil
+}
+
+// Function to take a copy of the state of the node
+func (n *Node) state() State {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	state := State{Snapshot: inspect.Snapshot{ID: n.ID, IP: n.IP, Protocol: "ring", Clock: n.Clock, Paused: n.Gate.Paused(), InFlight: n.Gate.Messages()}, Bootstrap: n.Bootstrap, Successor: n.Successor, K: n.K, Request: n.Request, Finished: slices.Clone(n.Finished), Tracing: n.tracing(), Tokens: append([]Message{}, n.Tokens...), Resources: []ResourceState{}}
+	for _, r := range n.sortedResources() {
+		resource := ResourceState{Name: r.Name, Request: r.Request, ReqTime: r.ReqTime, Reserved: r.Reserved, Wanted: r.Wanted, Held: r.Held != nil, TokenID: -1, Fence: r.Fence}
+		if r.Held != nil {
+			resource.TokenID = r.Held.TokenID
+		}
+		state.Resources = append(state.Resources, resource)
+	}
+	summarize(&state)
+	return state
+}
+
+// Function to get the resources the node has seen in the order of their names. Must be called with the lock held.
+func (n *Node) sortedResources() []*Resource {
+	resources := []*Resource{}
+	for _, r := range n.Resources {
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+	return resources
+}
@@ -0,0 +1,36 @@
+package node
+
+import (
+	"common/admin"
+	"testing"
+)
+
+// Only the node named in the certificate may send its messages, and an operator may only read the state or make admin calls
+func TestCheckSender(t *testing.T) {
+	n := &Node{ID: 1}
+	operator := admin.Identity{ID: -1, Role: admin.OPERATOR_ROLE}
+	cases := []struct {
+		name string
+		peer admin.Identity
+		method string
+		ID int
+		allowed bool
+	}{
+		{"a message of the sender", admin.Identity{ID: 2, Role: admin.NODE_ROLE}, "Node.ReceiveToken", 2, true},
+		{"a message of another node", admin.Identity{ID: 2, Role: admin.NODE_ROLE}, "Node.ReceiveToken", 3, false},
+		{"a message of the receiver", admin.Identity{ID: 2, Role: admin.NODE_ROLE}, "Node.ReceiveToken", 1, false},
+		{"a message from an operator", operator, "Node.ReceiveToken", -1, false},
+		{"a message from an operator naming a node", operator, "Node.ReceiveToken", 2, false},
+		{"a read of the state", operator, "Node.GetState", -1, true},
+		{"an admin call", operator, "Admin.Pause", -1, true},
+		{"an admin call from a node", admin.Identity{ID: 2, Role: admin.NODE_ROLE}, "Admin.Pause", 2, false},
+		{"a relayed wake up", admin.Identity{ID: 3, Role: admin.NODE_ROLE}, "Node.WakeToken", 2, true},
+		{"a wake up from an operator", operator, "Node.WakeToken", -1, false},
+	}
+	for _, c := range cases {
+		err := n.checkSender(c.peer, c.method, Message{ID: c.ID})
+		if (err == nil) != c.allowed {
+			t.Errorf("%s: checkSender(%v, %s, ID %d) = %v, want allowed %v", c.name, c.peer, c.method, c.ID, err, c.allowed)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package node
+
+import (
+	"common/trace"
+)
+
+const (
+	WAIT = trace.WAIT
+	ACQUIRED = trace.ACQUIRED
+	RELEASED = trace.RELEASED
+	CANCELLED = trace.CANCELLED
+	ARRIVED = trace.ARRIVED
+	HANDLED = trace.HANDLED
+)
+
+// Function to start recording the trace of the node in dir/node-<ID>.jsonl
+func (n *Node) OpenTrace(dir string) error {
+	return n.Tracer.Open(dir, n.ID)
+}
+
+// Function to record an event of the node on a resource
+func (n *Node) trace(event string, resource string) {
+	n.Tracer.Record(trace.Event{Node: n.ID, Resource: resource, Event: event})
+}
+
+// Function to record a message from another node arriving at the node or being handled by it
+func (n *Node) traceMessage(event string, msgType string, message Message) {
+	n.Tracer.Record(trace.Event{Node: n.ID, Resource: message.Resource, Event: event, Message: &trace.Message{From: message.ID, Type: msgType, Clock: message.Clock, ReqTime: message.ReqTime}})
+}
+
+// Function to hold a message from another node back like Gate.Deliver, and record in the trace when it arrived
+// and when it was handed to the node
+func (n *Node) deliver(msgType string, message Message) {
+	n.traceMessage(ARRIVED, msgType, message)
+	n.Gate.Deliver(message.ID, msgType, message.Resource, MessageDelay)
+	n.traceMessage(HANDLED, msgType, message)
+}
+
+// Function to check if the trace of the node is being recorded
+func (n *Node) tracing() bool {
+	return n.Tracer.Tracing()
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"common/admin"
+	"common/config"
+	"fair_ring/node"
+	"sort"
+	"time"
+)
+
+// Function to read the configuration of the node, with the flags this protocol takes
+func LoadConfig(args []string) (config.Config, error) {
+	defaults := config.Default(node.MessageDelay, node.CSDuration)
+	defaults.SkewMargin = config.Duration(node.DEFAULT_SKEW_MARGIN)
+	return config.Load(args, defaults, config.K, config.TIMEOUT, config.TTL, config.RESOURCES, config.PER_REQUEST, config.TRACE_DIR, config.TLS_DIR, config.TRANSPORT, config.STORAGE, config.HTTP_PORT, config.LEASE_SKEW_MARGIN)
+}
+
+// Function to apply the settings that do not depend on the ID of the node
+func Apply(cfg config.Config) error {
+	node.MessageDelay = time.Duration(cfg.MessageDelay)
+	node.CSDuration = time.Duration(cfg.CSDuration)
+	admin.Secret = cfg.AdminSecret
+	return cfg.OpenLog()
+}
+
+// Function to find the successor of a node, the node with the next ID in the fixed peer list
+func Successor(cfg config.Config, ID int) string {
+	IDs := []int{}
+	for i := range cfg.Peers {
+		IDs = append(IDs, i)
+	}
+	sort.Ints(IDs)
+	for _, i := range IDs {
+		if i > ID {
+			return cfg.Peers[i]
+		}
+	}
+	return cfg.Peers[IDs[0]]
+}
+
+// Function to build the message that hands out the workload
+func WorkloadMessage(w config.Workload) node.Message {
+	return node.Message{NumRequests: w.Requests, K: max(w.K, 1), Timeout: w.Timeout, TTL: w.TTL, NumResources: w.Resources, ResourcesPerRequest: w.PerRequest}
+}
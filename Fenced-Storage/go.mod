@@ -0,0 +1,7 @@
+module fenced_storage
+
+go 1.23.2
+
+require common v0.0.0
+
+replace common => ../Common
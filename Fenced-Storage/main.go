@@ -0,0 +1,109 @@
+package main
+
+import (
+	"common/address"
+	"common/storage"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Sample storage server that only accepts writes with a fencing token at least as large as every token it has
+// seen for the resource, so a holder that lost the lock while it was paused cannot overwrite a newer holder
+type Storage struct {
+	Highest map[string]int64 // Largest fencing token seen for each resource
+	Data map[string]string // Last accepted value of each resource
+	Accepted int
+	Rejected int
+	Lock sync.Mutex
+}
+
+const (
+	DEFAULT_STORAGE_ADDR = "127.0.0.1:9000"
+	CERT_NAME = "storage" // Name of the certificate the server presents with mutual TLS
+)
+
+func main() {
+	defaultAddr := os.Getenv("STORAGE_ADDR")
+	if defaultAddr == "" {
+		defaultAddr = DEFAULT_STORAGE_ADDR
+	}
+	addr := flag.String("addr", defaultAddr, "address to listen on, host:port or unix:<path> (STORAGE_ADDR)")
+	tlsDir := flag.String("tls-dir", os.Getenv("TLS_DIR"), "directory with ca.crt and the storage certificate, for mutual TLS (TLS_DIR)")
+	flag.Parse()
+
+	s := &Storage{Highest: make(map[string]int64), Data: make(map[string]string)}
+	rpc.Register(s)
+
+	listener, err := address.Listen(*addr)
+	if err != nil {
+		fmt.Printf("[STORAGE] could not start listening: %s\n", err)
+		os.Exit(1)
+	}
+	defer listener.Close()
+
+	// With mutual TLS, only nodes with a certificate signed by the CA of the nodes can write
+	if dir := *tlsDir; dir != "" {
+		config, err := loadTLS(dir)
+		if err != nil {
+			fmt.Printf("[STORAGE] could not load the certificates from %s: %s\n", dir, err)
+			os.Exit(1)
+		}
+		listener = tls.NewListener(listener, config)
+		fmt.Printf("[STORAGE] Mutual TLS is on with the certificate of %s\n", CERT_NAME)
+	}
+
+	fmt.Printf("[STORAGE] Storage server is running on %s\n", *addr)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			fmt.Printf("[STORAGE] accept error: %s\n", err)
+			continue
+		}
+		go rpc.ServeConn(conn)
+	}
+}
+
+// Function to write the value of a resource if the fencing token is not stale
+func (s *Storage) Write(write storage.Write, reply *storage.Reply) error {
+	s.Lock.Lock()
+	defer s.Lock.Unlock()
+
+	highest, seen := s.Highest[write.Resource]
+	if seen && write.Token < highest {
+		s.Rejected++
+		fmt.Printf("[STORAGE] Rejected the write of node %d to %s with the stale fencing token %d, the highest token is %d. Accepted: %d, rejected: %d\n", write.Node, write.Resource, write.Token, highest, s.Accepted, s.Rejected)
+		*reply = storage.Reply{Accepted: false, Highest: highest}
+		return nil
+	}
+
+	s.Highest[write.Resource] = write.Token
+	s.Data[write.Resource] = write.Data
+	s.Accepted++
+	fmt.Printf("[STORAGE] Accepted the write of node %d to %s with the fencing token %d. Accepted: %d, rejected: %d\n", write.Node, write.Resource, write.Token, s.Accepted, s.Rejected)
+	*reply = storage.Reply{Accepted: true, Highest: write.Token}
+	return nil
+}
+
+// Function to load the certificate of the server and the CA its clients must be signed by
+func loadTLS(dir string) (*tls.Config, error) {
+	caPEM, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		return nil, err
+	}
+	ca := x509.NewCertPool()
+	if !ca.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate found in %s", filepath.Join(dir, "ca.crt"))
+	}
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, CERT_NAME + ".crt"), filepath.Join(dir, CERT_NAME + ".key"))
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{cert}, ClientCAs: ca, ClientAuth: tls.RequireAndVerifyClientCert, MinVersion: tls.VersionTLS13}, nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"common/storage"
+	"net"
+	"net/rpc"
+	"testing"
+)
+
+// Once a write with token N + 1 was accepted, a write with token N is refused and the value stays the newer one
+func TestWriteRejectsStaleToken(t *testing.T) {
+	s := &Storage{Highest: make(map[string]int64), Data: make(map[string]string)}
+	writes := []struct {
+		write storage.Write
+		accepted bool
+		highest int64
+	}{
+		{storage.Write{Resource: "resource-0", Token: 1, Node: 1, Data: "a"}, true, 1},
+		{storage.Write{Resource: "resource-0", Token: 2, Node: 2, Data: "b"}, true, 2},
+		{storage.Write{Resource: "resource-0", Token: 1, Node: 1, Data: "c"}, false, 2},
+		{storage.Write{Resource: "resource-0", Token: 2, Node: 2, Data: "d"}, true, 2}, // The holder may write more than once
+		{storage.Write{Resource: "resource-1", Token: 1, Node: 1, Data: "e"}, true, 1}, // Every resource has tokens of its own
+	}
+	for _, w := range writes {
+		var reply storage.Reply
+		err := s.Write(w.write, &reply)
+		if err != nil || reply.Accepted != w.accepted || reply.Highest != w.highest {
+			t.Fatalf("write %+v got %+v, %v, want accepted %t and highest token %d", w.write, reply, err, w.accepted, w.highest)
+		}
+	}
+	if s.Data["resource-0"] != "d" || s.Accepted != 4 || s.Rejected != 1 {
+		t.Fatalf("unexpected storage after the writes: %+v", s)
+	}
+}
+
+// A node that writes with a stale token over RPC is told that its write was refused
+func TestSendStaleToken(t *testing.T) {
+	server := rpc.NewServer()
+	server.Register(&Storage{Highest: make(map[string]int64), Data: make(map[string]string)})
+	conn, peer := net.Pipe()
+	go server.ServeConn(peer)
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	accepted, err := storage.Send(client, storage.Write{Resource: "resource-0", Token: 2, Node: 2})
+	if err != nil || !accepted {
+		t.Fatalf("write with token 2 got %t, %v", accepted, err)
+	}
+	accepted, err = storage.Send(client, storage.Write{Resource: "resource-0", Token: 1, Node: 1})
+	if err != nil || accepted {
+		t.Fatalf("write with the stale token 1 got %t, %v", accepted, err)
+	}
+}
@@ -1,79 +1,168 @@
 package main
 
 import (
-	"encoding/json"
+	"common/config"
+	"errors"
+	"flag"
 	"fmt"
-	"io/ioutil"
 	"lamport_shared_priority_queue/node"
 	"lamport_shared_priority_queue/utils"
 	"os"
 	"os/signal"
-	"strconv"
 	"sync"
 	"syscall"
+	"time"
 )
 
 // TODO: Make a terminal interface to start the token passing(ONLY FOR BOOTSTRAP)
 // Add in the ability to input the number of nodes that would be requesting for the critical section
 
 func main() {
+	cfg, err := utils.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Println("Error occurred while reading the configuration:", err)
+		os.Exit(1)
+	}
+	err = utils.Apply(cfg)
+	if err != nil {
+		fmt.Println("Error occurred while opening the log:", err)
+		os.Exit(1)
+	}
+
 	n := node.Node{
-		NumVotes: 0,
+		Resources: make(map[string]*node.Resource),
+		Requested: []string{node.ResourceName(0)},
+		K: 1,
+		Mode: node.WRITE,
+		Policy: node.FAIR,
 		Clock: 0,
-		Queue: utils.NewPriorityQueue(),
 		Lock: sync.Mutex{}, 
 	}
 
-	var nodesList map[int]string = utils.ReadNodesList()
+	var nodesList map[int]string = cfg.NodesList()
 
-	if len(nodesList) == 0 {
-		n.ID = 0 // Set as bootstrap node
-		n.IP = node.LOCALHOST + "8000"
-		n.Network = make(map[int]string)
-	} else {
-		n.ID = len(nodesList)
-		n.IP = node.LOCALHOST + strconv.Itoa(8000 + n.ID)	
-		n.Network = make(map[int]string)
-		for i := range nodesList {
+	// Without an ID in the configuration, the node takes the next free one, and the first node is the bootstrap node
+	n.ID, n.IP, n.Bind, err = cfg.Identity(nodesList)
+	if err != nil {
+		fmt.Println("Error occurred while choosing the ID of the node:", err)
+		os.Exit(1)
+	}
+	n.Bootstrap = cfg.BootstrapAddress(n.ID, n.IP, nodesList)
+	n.Network = make(map[int]string)
+	for i := range nodesList {
+		if i != n.ID {
 			n.Network[i] = nodesList[i]
 		}
 	}
 
-	go n.StartRPCServer()
+	// Node i presents the certificate of node-i
+	if cfg.TLSDir != "" {
+		err := n.LoadTLS(cfg.TLSDir)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while loading the certificates: %s\n", n.ID, err)
+			os.Exit(1)
+		}
+	}
 
+	err = n.OpenTrace(cfg.TraceDir)
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while opening the trace: %s\n", n.ID, err)
+	}
 
-	for i := range nodesList {
-		message := node.Message{ID: n.ID, IP: n.IP}
-		_, err := node.CallByRPC(nodesList[i], "Node.AddNode", message)
-		if err != nil {
-			fmt.Printf("[NODE-%d] Error occurred while adding node %d to the network: %s\n", n.ID, i, err)
+	n.Storage = cfg.Storage
+	if cfg.Transport != "" {
+		if cfg.Transport != node.RPC_TRANSPORT && cfg.Transport != node.GRPC_TRANSPORT {
+			fmt.Printf("[NODE-%d] Unknown transport %q, using %s\n", n.ID, cfg.Transport, node.RPC_TRANSPORT)
+		} else {
+			node.Transport = cfg.Transport
 		}
 	}
+	n.SkewMargin = time.Duration(cfg.SkewMargin)
 
-	nodesList[n.ID] = n.IP
+	go n.StartRPCServer()
 
-	jsonData, err := json.Marshal(nodesList)
-	if err != nil {
-		fmt.Println("Error occurred while marshalling nodesList: ", err)
+	// Node i serves the HTTP API on port http-port + i of the host it listens on
+	if cfg.HTTPPort != 0 {
+		go n.StartHTTPServer(n.HTTPAddress(cfg.HTTPPort))
 	}
 
-	err = ioutil.WriteFile("nodes-list.json", jsonData, os.ModePerm)
+	// With a fixed peer list every node already knows the others, so it only joins through the registry
+	if len(cfg.Peers) == 0 {
+		for i := range nodesList {
+			message := node.Message{ID: n.ID, IP: n.IP}
+			_, err := node.CallByRPC(nodesList[i], "Admin.AddNode", message)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while adding node %d to the network: %s\n", n.ID, i, err)
+			}
+		}
 
-	if err != nil {
-		fmt.Println("Error occurred while updating nodes-list.json: ", err)
+		nodesList[n.ID] = n.IP
+
+		err = config.WriteNodesList(cfg.Registry, nodesList)
+		if err != nil {
+			fmt.Printf("Error occurred while updating %s: %s\n", cfg.Registry, err)
+		}
 	}
 
 	var numRequests int
-	if n.ID == 0 {
+	var configured sync.WaitGroup
+	if cfg.IsBootstrap(n.ID) && cfg.Nodes > 0 {
+		// Hand out the workload from the configuration once enough nodes have joined
+		fmt.Printf("[NODE-%d] Waiting for %d nodes to join\n", n.ID, cfg.Nodes)
+		nodesList = cfg.WaitForNodes()
+		message := utils.WorkloadMessage(cfg.Workload)
+		numRequests = message.NumRequests
+		for i := range nodesList {
+			configured.Add(1)
+			go func(i int) {
+				defer configured.Done()
+				_, err := node.CallByRPC(nodesList[i], "Admin.SetRequesting", message)
+				if err != nil {
+					fmt.Printf("[NODE-%d] Error occurred while setting the request flag for node %d: %s\n", n.ID, i, err)
+				}
+			}(i)
+		}
+	} else if cfg.IsBootstrap(n.ID) {
 		fmt.Printf("[NODE-%d] Make sure all the nodes are up and running.\n", n.ID)
 		fmt.Printf("[NODE-%d] How many nodes should request for CS: \n", n.ID)
 		fmt.Scan(&numRequests)
+		fmt.Printf("[NODE-%d] How many nodes can be in the critical section at the same time (k): \n", n.ID)
+		fmt.Scan(&n.K)
+		n.K = max(n.K, 1)
+		var numReaders int
+		fmt.Printf("[NODE-%d] How many of the requesting nodes should request for shared (read) access: \n", n.ID)
+		fmt.Scan(&numReaders)
+		var policy string
+		fmt.Printf("[NODE-%d] Should the lock be fair or prefer writers? (fair/writer): \n", n.ID)
+		fmt.Scan(&policy)
+		if policy == "writer" {
+			n.Policy = node.WRITER_PREFERENCE
+		}
 
-		nodesList = utils.ReadNodesList()
-		message := node.Message{NumRequests: numRequests}
-		for i := 0; i < len(nodesList); i++ {
+		var numSessions int
+		fmt.Printf("[NODE-%d] How many sessions should the requesting nodes be split into (0 for none): \n", n.ID)
+		fmt.Scan(&numSessions)
+		var timeout int
+		fmt.Printf("[NODE-%d] How many seconds should a node wait for the lock before giving up (0 to wait forever): \n", n.ID)
+		fmt.Scan(&timeout)
+		var ttl int
+		fmt.Printf("[NODE-%d] How many seconds should a lease last without being renewed (0 to turn leases off): \n", n.ID)
+		fmt.Scan(&ttl)
+		var numResources int
+		fmt.Printf("[NODE-%d] How many resources should the requesting nodes be spread over: \n", n.ID)
+		fmt.Scan(&numResources)
+		var resourcesPerRequest int
+		fmt.Printf("[NODE-%d] How many resources should each request take at once: \n", n.ID)
+		fmt.Scan(&resourcesPerRequest)
+
+		nodesList = cfg.NodesList()
+		message := node.Message{NumRequests: numRequests, K: n.K, NumReaders: numReaders, Policy: n.Policy, NumSessions: numSessions, Timeout: timeout, TTL: ttl, NumResources: numResources, ResourcesPerRequest: resourcesPerRequest}
+		for i := range nodesList {
 			go func(i int) {
-				_, err := node.CallByRPC(nodesList[i], "Node.SetRequesting", message)
+				_, err := node.CallByRPC(nodesList[i], "Admin.SetRequesting", message)
 				if err != nil {
 					fmt.Printf("[NODE-%d] Error occurred while setting the request flag for node %d: %s\n", n.ID, i, err)
 				}
@@ -82,31 +171,46 @@ func main() {
 	}
 	
 	// Start the token passing
-	if n.ID == 0 {
+	if cfg.IsBootstrap(n.ID) {
 		var answer string
 		go func() {
-			for {
-				fmt.Printf("[NODE-%d] Do you want to start the request process? (y/n): ", n.ID)
-				fmt.Scan(&answer)
-				if answer == "y" {
-					go n.StartRequestProcess(node.Message{}, &node.Message{})
-					for i := range n.Network {
-						go func(i int) {
-							_, err := node.CallByRPC(n.Network[i], "Node.StartRequestProcess", node.Message{})
-							if err != nil {
-								fmt.Printf("[NODE-%d] Error occurred while starting the request process for node %d: %s\n", n.ID, i, err)
-							}
-						}(i)
+			if cfg.Nodes > 0 {
+				// Every node must know its workload before the requests start
+				configured.Wait()
+			} else {
+				for {
+					fmt.Printf("[NODE-%d] Do you want to start the request process? (y/n): ", n.ID)
+					fmt.Scan(&answer)
+					if answer == "y" {
+						break
 					}
-					break
-				} else { 
 					fmt.Printf("[NODE-%d] Waiting for all nodes to be ready...\n", n.ID)
 				}
+				if cfg.Console {
+					go n.RunConsole(os.Stdin)
+				}
+			}
+			network := map[int]string{n.ID: n.IP}
+			for i := range n.Network {
+				network[i] = n.Network[i]
+			}
+			for i := range network {
+				go func(i int) {
+					_, err := node.CallByRPC(network[i], "Admin.StartRequestProcess", node.Message{})
+					if err != nil {
+						fmt.Printf("[NODE-%d] Error occurred while starting the request process for node %d: %s\n", n.ID, i, err)
+					}
+				}(i)
 			}
 		}()
+
+		go utils.CalculateTimeTaken(&n, numRequests)
 	}
 
-	go utils.CalculateTimeTaken(&n, numRequests)
+	// The bootstrap node reads the answers to its prompts from the console first
+	if cfg.Console && !(cfg.IsBootstrap(n.ID) && cfg.Nodes == 0) {
+		go n.RunConsole(os.Stdin)
+	}
 
 	// Handling when the node fails or is shut down
 	sigChan := make(chan os.Signal, 1)
@@ -118,14 +222,15 @@ func main() {
 		fmt.Println("Shutting down...")
 
 		// Remove the node from the list
-		nodesList = utils.ReadNodesList()
+		if len(cfg.Peers) == 0 {
+			nodesList = config.ReadNodesList(cfg.Registry)
 
-		delete(nodesList, n.ID) // remove the element that left the network from the nodesList
+			delete(nodesList, n.ID) // remove the element that left the network from the nodesList
 
-		jsonData, err := json.Marshal(nodesList)
-		err = ioutil.WriteFile("nodes-list.json", jsonData, os.ModePerm)
-		if err != nil {
-			fmt.Println("Error occurred while updating nodes-list.json: ", err)
+			err := config.WriteNodesList(cfg.Registry, nodesList)
+			if err != nil {
+				fmt.Printf("Error occurred while updating %s: %s\n", cfg.Registry, err)
+			}
 		}
 		os.Exit(0)
 	}()
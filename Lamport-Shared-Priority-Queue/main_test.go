@@ -0,0 +1,141 @@
+package main
+
+import (
+	"common/cluster"
+	"lamport_shared_priority_queue/node"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// With k = 2, writers may share the critical section with each other but never with a reader
+func TestMixedWorkloadWithTwoHoldersIsSafe(t *testing.T) {
+	for _, policy := range []string{"fair", "writer"} {
+		t.Run(policy, func(t *testing.T) {
+			c := cluster.Start(t, 5, "-requests", "5", "-k", "2", "-readers", "2", "-policy", policy)
+			out := c.WaitFor(0, "Safety check", time.Minute)
+			if !strings.Contains(out, "Safety check passed") {
+				t.Fatalf("the safety check failed:\n%s", out)
+			}
+		})
+	}
+}
+
+// Once every node has released the lock, every node reports an empty queue with Node.GetState, whatever the transport
+func TestGetStateAfterTheWorkload(t *testing.T) {
+	for _, transport := range []string{node.RPC_TRANSPORT, node.GRPC_TRANSPORT} {
+		t.Run(transport, func(t *testing.T) {
+			t.Setenv("TRANSPORT", transport)
+			c := cluster.Start(t, 3, "-requests", "3")
+
+			// The node holding the lock has replies from every other node
+			waitForState(c, 0, func(state node.State) bool {
+				return slices.ContainsFunc(state.Resources, func(r node.ResourceState) bool { return r.InCS && len(r.Replied) == r.RepliesNeeded })
+			})
+			c.WaitFor(0, "Time taken", time.Minute)
+
+			for i := 0; i < 3; i++ {
+				state := waitForState(c, i, func(state node.State) bool {
+					return len(state.Resources) == 1 && !state.Resources[0].Request && len(state.Resources[0].Queue) == 0
+				})
+				if state.ID != i || len(state.Network) != 2 || state.Clock == 0 || state.Resources[0].InCS {
+					t.Fatalf("unexpected state of node %d: %+v", i, state)
+				}
+			}
+			state := waitForState(c, 0, func(state node.State) bool { return !slices.Contains(state.Finished, false) })
+			if len(state.Finished) != 3 {
+				t.Fatalf("node 0 does not know about every node: %+v", state)
+			}
+		})
+	}
+}
+
+// Once the holder of the lock crashes, its lease expires: every node drops its request and counts it as a reply,
+// so the node waiting next enters, and a release of the crashed node that turns up later changes nothing
+func TestLeaseExpiry(t *testing.T) {
+	c := cluster.Start(t, 3, "-requests", "0", "-ttl", "2")
+	for i := range c.Logs {
+		c.WaitFor(i, "Node will not request", time.Minute)
+	}
+
+	c.Command(1, "request")
+	c.WaitFor(1, "Holding resource-0", time.Minute)
+	holder := waitForState(c, 1, func(state node.State) bool { return len(state.Resources) == 1 && state.Resources[0].InCS })
+	c.Command(2, "request")
+	for _, i := range []int{0, 1} {
+		waitForState(c, i, func(state node.State) bool { return len(state.Resources) == 1 && len(state.Resources[0].Queue) == 2 })
+	}
+
+	// The holder crashes without releasing, so only its lease can let the other node in
+	c.Kill(1)
+	c.WaitFor(2, "Holding resource-0", time.Minute)
+	c.WaitFor(0, "Lease of node 1 on resource-0 expired", time.Minute)
+	waitForState(c, 0, func(state node.State) bool {
+		return len(state.Resources) == 1 && slices.Equal(state.Resources[0].Queue, []node.Item{{ID: 2, TimeStamp: state.Resources[0].Queue[0].TimeStamp, Mode: node.WRITE}})
+	})
+
+	// A release of the old holder must not remove the request of the new holder
+	late := node.Message{Type: node.RELEASE, ID: 1, IP: c.Peers[1], ReqTime: holder.Resources[0].ReqTime, Resource: "resource-0"}
+	var reply node.Message
+	err := c.Call(0, "Node.ReceiveMessage", late, &reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := waitForState(c, 0, func(state node.State) bool { return len(state.Resources) == 1 })
+	if q := state.Resources[0].Queue; len(q) != 1 || q[0].ID != 2 {
+		t.Fatalf("the late release of node 1 changed the queue of node 0: %v", q)
+	}
+	state = waitForState(c, 2, func(state node.State) bool { return len(state.Resources) == 1 })
+	if !state.Resources[0].InCS {
+		t.Fatalf("node 2 lost the lock after the late release of node 1: %+v", state.Resources[0])
+	}
+}
+
+// A request that gives up, because the lock is busy or the timeout runs out, must leave no queue entry and no
+// deferred reply behind at any node, so that a later request still gets the lock
+func TestTryLockAndTimeoutLeaveNothingBehind(t *testing.T) {
+	t.Setenv("HTTP_PORT", "1")
+	c := cluster.Start(t, 4, "-requests", "0")
+	for i := range c.Logs {
+		c.WaitFor(i, "Node will not request", time.Minute)
+		c.WaitFor(i, "HTTP API is running", time.Minute)
+	}
+
+	c.Command(1, "request")
+	c.WaitFor(1, "Holding resource-0", time.Minute)
+
+	var res node.LockResponse
+	if status := c.HTTP(2, "POST", "/locks/resource-0/acquire", node.AcquireRequest{Try: true}, &res); status != http.StatusConflict {
+		t.Fatalf("TryLock of node 2 on a held lock answered %d: %+v", status, res)
+	}
+	// The first node that defers the request makes TryLock give up without asking the others
+	c.WaitFor(2, "deferred the request for resource-0, so the lock is busy", time.Minute)
+	if status := c.HTTP(3, "POST", "/locks/resource-0/acquire", node.AcquireRequest{Timeout: 1}, &res); status != http.StatusRequestTimeout {
+		t.Fatalf("acquisition of node 3 with a timeout on a held lock answered %d: %+v", status, res)
+	}
+
+	// Only the request of the holder is left
+	for i := range c.Logs {
+		waitForState(c, i, func(state node.State) bool {
+			if len(state.Resources) != 1 {
+				return false
+			}
+			r := state.Resources[0]
+			return len(r.Queue) == 1 && r.Queue[0].ID == 1 && len(r.Deferred) == 0 && (i == 1 || !r.Request)
+		})
+	}
+
+	c.Command(1, "release")
+	c.WaitFor(1, "Released resource-0", time.Minute)
+	for i := range c.Logs {
+		waitForState(c, i, func(state node.State) bool { return len(state.Resources) == 1 && len(state.Resources[0].Queue) == 0 })
+	}
+	if status := c.HTTP(2, "POST", "/locks/resource-0/acquire", node.AcquireRequest{Try: true}, &res); status != http.StatusOK {
+		t.Fatalf("TryLock of node 2 on a free lock answered %d: %+v", status, res)
+	}
+	if status := c.HTTP(2, "POST", "/locks/resource-0/release", node.ReleaseRequest{Token: &res.Token}, &res); status != http.StatusOK {
+		t.Fatalf("release of node 2 answered %d: %+v", status, res)
+	}
+}
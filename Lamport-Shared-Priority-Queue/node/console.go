@@ -0,0 +1,168 @@
+package node
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Directory the trace is written to when it is turned on from the console without a directory
+const DEFAULT_TRACE_DIR = "traces"
+
+// Commands of the console and what they do
+var consoleCommands = [][2]string{
+	{"request [resource] [read|write]", "request the lock on a resource, resource-0 for write access by default"},
+	{"release [resource]", "release the lock on a resource, or withdraw the request if it is not granted yet"},
+	{"status", "show the requests of the node and the locks it holds"},
+	{"queue", "show the queue of every resource"},
+	{"votes", "show the replies received for the requests of the node"},
+	{"peers", "show the other nodes of the network"},
+	{"clock", "show the Lamport clock"},
+	{"pause", "hold back the messages from other nodes"},
+	{"step", "handle the oldest message that is held back and stay paused"},
+	{"resume", "handle the messages that were held back and the ones that follow"},
+	{"trace on [dir] | trace off", "start or stop recording the trace, in " + DEFAULT_TRACE_DIR + " by default"},
+	{"help", "show the commands"},
+}
+
+// Function to read operator commands line by line until the input ends, so that scenarios can be driven by hand
+func (n *Node) RunConsole(in io.Reader) {
+	fmt.Printf("[NODE-%d] Console is ready, type help for the commands\n", n.ID)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		n.runCommand(fields[0], fields[1:])
+	}
+}
+
+// Function to run a single console command
+func (n *Node) runCommand(command string, args []string) {
+	switch command {
+	case "request":
+		name, mode := ResourceName(0), WRITE
+		for _, arg := range args {
+			switch strings.ToUpper(arg) {
+			case READ, WRITE:
+				mode = strings.ToUpper(arg)
+			default:
+				name = arg
+			}
+		}
+		go func() {
+			token, err := n.Acquire(name, mode)
+			if err != nil {
+				fmt.Println(err)
+				return
+			}
+			fmt.Printf("[NODE-%d] Holding %s for %s access with fencing token %d\n", n.ID, name, mode, token)
+		}()
+
+	case "release":
+		name := ResourceName(0)
+		if len(args) > 0 {
+			name = args[0]
+		}
+		n.Lock.Lock()
+		r, ok := n.Resources[name]
+		requesting := ok && r.Request
+		n.Lock.Unlock()
+		if !requesting {
+			fmt.Printf("[NODE-%d] Not requesting %s\n", n.ID, name)
+			return
+		}
+		if n.cancel(name) {
+			fmt.Printf("[NODE-%d] Withdrew the request for %s\n", n.ID, name)
+			return
+		}
+		n.Release(name)
+		fmt.Printf("[NODE-%d] Released %s\n", n.ID, name)
+
+	case "status":
+		state := n.state()
+		fmt.Printf("[NODE-%d] Address: %s, clock: %d, k: %d, policy: %s, paused: %t, tracing: %t\n", n.ID, state.IP, state.Clock, state.K, state.Policy, state.Paused, state.Tracing)
+		n.printLines(state.Status)
+
+	case "queue":
+		n.printLines(n.state().Queues)
+
+	case "votes":
+		n.printLines(n.state().Votes)
+
+	case "peers":
+		n.printPeers()
+
+	case "clock":
+		fmt.Printf("[NODE-%d] Clock: %d\n", n.ID, n.state().Clock)
+
+	case "pause":
+		n.Gate.PauseNode(n.ID)
+
+	case "step":
+		n.Gate.StepNode(n.ID)
+
+	case "resume":
+		n.Gate.ResumeNode(n.ID)
+
+	case "trace":
+		n.setTracing(args)
+
+	case "help":
+		for _, c := range consoleCommands {
+			fmt.Printf("  %-32s %s\n", c[0], c[1])
+		}
+
+	default:
+		fmt.Printf("[NODE-%d] Unknown command %q, type help for the commands\n", n.ID, command)
+	}
+}
+
+// Function to print lines of the state of the node
+func (n *Node) printLines(lines []string) {
+	for _, line := range lines {
+		fmt.Printf("[NODE-%d] %s\n", n.ID, line)
+	}
+}
+
+// Function to print the addresses of the other nodes
+func (n *Node) printPeers() {
+	state := n.state()
+	IDs := []int{}
+	for ID := range state.Network {
+		IDs = append(IDs, ID)
+	}
+	slices.Sort(IDs)
+	fmt.Printf("[NODE-%d] Bootstrap node: %s\n", n.ID, state.Bootstrap)
+	for _, ID := range IDs {
+		fmt.Printf("[NODE-%d] Node %d: %s\n", n.ID, ID, state.Network[ID])
+	}
+}
+
+// Function to turn the trace on or off from the console
+func (n *Node) setTracing(args []string) {
+	if len(args) == 0 || (args[0] != "on" && args[0] != "off") {
+		fmt.Printf("[NODE-%d] Usage: trace on [dir] | trace off\n", n.ID)
+		return
+	}
+
+	if args[0] == "off" {
+		n.Tracer.Stop()
+		fmt.Printf("[NODE-%d] Stopped recording the trace\n", n.ID)
+		return
+	}
+
+	dir := DEFAULT_TRACE_DIR
+	if len(args) > 1 {
+		dir = args[1]
+	}
+	path, err := n.Tracer.Start(dir, n.ID)
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while opening the trace: %s\n", n.ID, err)
+		return
+	}
+	fmt.Printf("[NODE-%d] Recording the trace in %s\n", n.ID, path)
+}
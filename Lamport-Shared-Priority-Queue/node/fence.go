@@ -0,0 +1,30 @@
+package node
+
+import (
+	"common/storage"
+	"fmt"
+)
+
+// Fencing token of a request. Requests enter the critical section in the order of their timestamps, with ties
+// broken by the node ID, so the timestamp fills the high bits and the node ID the low 16 bits. The tokens only
+// grow in the order of entry with k = 1: with more holders, a request with a smaller timestamp that arrives late
+// can still enter after one with a larger timestamp, since fewer than k requests are ahead of it.
+func fencingToken(reqTime int, ID int) int64 {
+	return int64(reqTime) << 16 | int64(ID)
+}
+
+// Function to write to the fenced storage server with the fencing token of a held resource. Returns false if
+// the server rejected the token as stale.
+func (n *Node) WriteStorage(name string, data string) (bool, error) {
+	n.Lock.Lock()
+	token := n.resource(name).Fence
+	n.Lock.Unlock()
+
+	// The server is dialed like a peer, so it is reached over TLS and on Unix domain sockets as well
+	client, err := dial(n.Storage)
+	if err != nil {
+		return false, fmt.Errorf("error in dialing: %s", err)
+	}
+	defer client.Close()
+	return storage.Send(client, storage.Write{Resource: name, Token: token, Node: n.ID, Data: data})
+}
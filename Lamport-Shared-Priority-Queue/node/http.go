@@ -0,0 +1,254 @@
+package node
+
+import (
+	"common/address"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// How often a waiting acquisition sends a comment to keep the event stream open
+const SSE_KEEP_ALIVE = 15 * time.Second
+
+// Body of an acquire request, every field is optional
+type AcquireRequest struct {
+	Mode string `json:"mode"` // READ or WRITE, WRITE by default
+	Session string `json:"session"` // Session to join, empty for none
+	Timeout float64 `json:"timeout"` // Seconds to wait for the lock, 0 to wait until the client goes away
+	Try bool `json:"try"` // Give up with 409 as soon as another node is ahead, like TryLock
+}
+
+// Body of a release request
+type ReleaseRequest struct {
+	Token *int64 `json:"token"` // Fencing token of the entry the client releases, as returned by the acquisition
+}
+
+// Answer to an acquire or release request
+type LockResponse struct {
+	Resource string `json:"resource"`
+	Token int64 `json:"token"` // Fencing token of the entry into the critical section, which the client needs to release it
+	Error string `json:"error,omitempty"`
+}
+
+// State of the lock on a resource as seen by the node
+type ResourceStatus struct {
+	Name string `json:"name"`
+	Requesting bool `json:"requesting"`
+	Held bool `json:"held"`
+	Mode string `json:"mode,omitempty"`
+	Session string `json:"session,omitempty"`
+	Token int64 `json:"token,omitempty"`
+	Queue []int `json:"queue"` // IDs of the nodes in the queue of the node, in order
+}
+
+// Answer to a status request
+type StatusResponse struct {
+	Node int `json:"node"`
+	Address string `json:"address"`
+	Protocol string `json:"protocol"`
+	Clock int `json:"clock"`
+	Resources []ResourceStatus `json:"resources"`
+}
+
+// Function to get the address the node serves the HTTP API on: port base + ID on the host the node listens on, or a
+// socket next to its own if the node listens on a Unix domain socket
+func (n *Node) HTTPAddress(base int) string {
+	network, addr := address.Split(n.listenAddress())
+	if network == "unix" {
+		return address.UNIX_PREFIX + addr + ".http"
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return address.WithPort(host, base + n.ID)
+}
+
+// Function to start the HTTP API, so that clients that cannot use net/rpc can take locks through the node
+func (n *Node) StartHTTPServer(addr string) {
+	listener, err := address.Listen(addr)
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while starting the HTTP API: %s\n", n.ID, err)
+		return
+	}
+	fmt.Printf("[NODE-%d] HTTP API is running on %s\n", n.ID, addr)
+	err = http.Serve(listener, n.httpHandler())
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while starting the HTTP API: %s\n", n.ID, err)
+	}
+}
+
+// Function to route the requests of the HTTP API
+func (n *Node) httpHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /locks/{name}/acquire", n.handleAcquire)
+	mux.HandleFunc("POST /locks/{name}/release", n.handleRelease)
+	mux.HandleFunc("GET /status", n.handleStatus)
+	return mux
+}
+
+// Function to acquire a lock for an HTTP client. The request is held open until the lock is granted (long polling),
+// or the progress is streamed as server-sent events if the client asks for text/event-stream. The request for the
+// lock is cancelled if the client goes away or the timeout runs out.
+func (n *Node) handleAcquire(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+
+	var body AcquireRequest
+	if req.ContentLength != 0 {
+		err := json.NewDecoder(req.Body).Decode(&body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, LockResponse{Resource: name, Error: fmt.Sprintf("invalid body: %s", err)})
+			return
+		}
+	}
+	mode := strings.ToUpper(body.Mode)
+	if mode == "" {
+		mode = WRITE
+	}
+	if mode != READ && mode != WRITE {
+		writeJSON(w, http.StatusBadRequest, LockResponse{Resource: name, Error: fmt.Sprintf("unknown mode %s", body.Mode)})
+		return
+	}
+	if body.Session != "" {
+		// Members of a session share the resource like readers
+		mode = READ
+	}
+
+	ctx := req.Context()
+	if body.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(body.Timeout * float64(time.Second)))
+		defer cancel()
+	}
+
+	type result struct {
+		token int64
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		token, err := n.acquire(ctx, name, mode, body.Session, body.Try)
+		if err == nil && req.Context().Err() != nil {
+			// The client went away just as the lock was granted, so nobody would release it
+			fmt.Printf("[NODE-%d] HTTP client left before taking %s. Releasing it\n", n.ID, name)
+			n.Release(name)
+			err = req.Context().Err()
+		}
+		done <- result{token: token, err: err}
+	}()
+
+	if !strings.Contains(req.Header.Get("Accept"), "text/event-stream") {
+		res := <-done
+		if res.err != nil {
+			writeJSON(w, acquireStatus(res.err), LockResponse{Resource: name, Error: res.err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, LockResponse{Resource: name, Token: res.token})
+		return
+	}
+
+	// Stream the progress of the acquisition
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, LockResponse{Resource: name, Error: "streaming is not supported"})
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	writeEvent(w, "waiting", LockResponse{Resource: name})
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(SSE_KEEP_ALIVE)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case res := <-done:
+			if res.err != nil {
+				writeEvent(w, "failed", LockResponse{Resource: name, Error: res.err.Error()})
+			} else {
+				writeEvent(w, "acquired", LockResponse{Resource: name, Token: res.token})
+			}
+			flusher.Flush()
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// Function to release a lock held by an HTTP client
+func (n *Node) handleRelease(w http.ResponseWriter, req *http.Request) {
+	name := req.PathValue("name")
+
+	var body ReleaseRequest
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil || body.Token == nil {
+		writeJSON(w, http.StatusBadRequest, LockResponse{Resource: name, Error: "the body must carry the fencing token of the entry to release"})
+		return
+	}
+
+	// A client that lost the lock must not release the entry of the client that holds it now
+	err = n.releaseIfFence(name, *body.Token)
+	if err != nil {
+		writeJSON(w, http.StatusConflict, LockResponse{Resource: name, Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, LockResponse{Resource: name})
+}
+
+// Function to report the state of every resource the node has seen
+func (n *Node) handleStatus(w http.ResponseWriter, req *http.Request) {
+	n.Lock.Lock()
+	status := StatusResponse{Node: n.ID, Address: n.IP, Protocol: "lamport", Clock: n.Clock, Resources: []ResourceStatus{}}
+	for _, r := range n.Resources {
+		resource := ResourceStatus{Name: r.Name, Requesting: r.Request, Held: r.InCS, Queue: []int{}}
+		if r.Request {
+			resource.Mode = r.Mode
+			resource.Session = r.Session
+		}
+		if r.InCS {
+			resource.Token = r.Fence
+		}
+		queue := append(PriorityQueue{}, *r.Queue...)
+		sort.Slice(queue, func(i, j int) bool { return queue.Less(i, j) })
+		for _, item := range queue {
+			resource.Queue = append(resource.Queue, item.ID)
+		}
+		status.Resources = append(status.Resources, resource)
+	}
+	n.Lock.Unlock()
+
+	sort.Slice(status.Resources, func(i, j int) bool { return status.Resources[i].Name < status.Resources[j].Name })
+	writeJSON(w, http.StatusOK, status)
+}
+
+// HTTP status for a failed acquisition
+func acquireStatus(err error) int {
+	switch err {
+	case context.DeadlineExceeded:
+		return http.StatusRequestTimeout
+	case context.Canceled:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusConflict
+}
+
+// Function to write a JSON answer
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Function to write a server-sent event with a JSON payload
+func writeEvent(w http.ResponseWriter, event string, v any) {
+	data, _ := json.Marshal(v)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
@@ -0,0 +1,60 @@
+package node
+
+import (
+	"common/inspect"
+	"fmt"
+)
+
+// Function to fill in the part of the state shown by the console and the dashboard
+func summarize(state *State) {
+	state.Phase = inspect.IDLE
+	for _, r := range state.Resources {
+		if r.InCS {
+			state.Phase = inspect.IN_CS
+		} else if r.Request && state.Phase == inspect.IDLE {
+			state.Phase = inspect.REQUESTING
+		}
+	}
+	state.Status = statusLines(*state)
+	state.Queues = queueLines(*state)
+	state.Votes = voteLines(*state)
+}
+
+// Function to describe the requests and locks of a node
+func statusLines(state State) []string {
+	lines := []string{}
+	for _, r := range state.Resources {
+		switch {
+		case r.InCS:
+			lines = append(lines, fmt.Sprintf("%s: holding for %s access with fencing token %d", r.Name, r.Mode, r.Fence))
+		case r.Request:
+			lines = append(lines, fmt.Sprintf("%s: requesting %s access with timestamp %d", r.Name, r.Mode, r.ReqTime))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: idle", r.Name))
+		}
+	}
+	return lines
+}
+
+// Function to describe the queue of every resource of a node
+func queueLines(state State) []string {
+	lines := []string{}
+	for _, r := range state.Resources {
+		lines = append(lines, fmt.Sprintf("Queue of %s: %v", r.Name, r.Queue))
+	}
+	return lines
+}
+
+// Function to describe the replies received for the requests of a node and the requests it deferred
+func voteLines(state State) []string {
+	lines := []string{}
+	for _, r := range state.Resources {
+		if r.Request {
+			lines = append(lines, fmt.Sprintf("Replies for %s: %d of %d needed, from %v", r.Name, len(r.Replied), r.RepliesNeeded, r.Replied))
+		}
+		if len(r.Deferred) > 0 {
+			lines = append(lines, fmt.Sprintf("Deferred requests for %s: from %v", r.Name, r.Deferred))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,124 @@
+package node
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// Extra time a node waits after the lease of another node should have expired, to make up for clocks that run at slightly different speeds
+	DEFAULT_SKEW_MARGIN = 500 * time.Millisecond
+	// How often a node looks for expired leases
+	LEASE_CHECK_INTERVAL = 500 * time.Millisecond
+)
+
+// Lease of another node on its request for a resource
+type Lease struct {
+	ReqTime int // Timestamp of the leased request
+	Expiry time.Time // Time after which the request is treated as released
+}
+
+// Function to renew the lease on a request of the node until the request is granted and released or cancelled.
+// The node counts its own lease from the moment it sends the renewal, so it always expires before the lease
+// seen by the other nodes.
+func (n *Node) renewLease(name string, reqTime int) {
+	for {
+		n.Lock.Lock()
+		r := n.resource(name)
+		if !r.Request || r.ReqTime != reqTime {
+			n.Lock.Unlock()
+			return
+		}
+		r.LeaseExpiry = time.Now().Add(n.TTL)
+		n.Clock++
+		msg := Message{Type: RENEW, ID: n.ID, ReqTime: reqTime, TTL: int(n.TTL / time.Second), Resource: name, Clock: n.Clock}
+		n.Lock.Unlock()
+
+		for i := range n.Network {
+			go func() {
+				_, err := CallByRPC(n.Network[i], "Node.ReceiveMessage", msg)
+				if err != nil {
+					fmt.Printf("[NODE-%d] Error occurred while renewing the lease on %s with node %d: %s\n", n.ID, name, i, err)
+				}
+			}()
+		}
+		time.Sleep(n.TTL / 3)
+	}
+}
+
+// Function to extend the lease of another node on its request. Must be called with the lock held.
+func (n *Node) extendLease(r *Resource, message Message) {
+	if message.TTL <= 0 || message.ID == n.ID {
+		return
+	}
+	if ended, ok := r.Cancelled[message.ID]; ok && ended >= message.ReqTime {
+		// The request was cancelled or its lease expired, so a late renewal does not bring it back
+		return
+	}
+	r.Leases[message.ID] = Lease{ReqTime: message.ReqTime, Expiry: time.Now().Add(time.Duration(message.TTL) * time.Second + n.SkewMargin)}
+}
+
+// Function to check the leases of the other nodes and treat every expired one as a release
+func (n *Node) watchLeases() {
+	for {
+		time.Sleep(LEASE_CHECK_INTERVAL)
+
+		n.Lock.Lock()
+		names := []string{}
+		for name, r := range n.Resources {
+			for ID, lease := range r.Leases {
+				if time.Now().After(lease.Expiry) {
+					if n.expireLease(r, ID, lease) {
+						names = append(names, name)
+					}
+				}
+			}
+		}
+		n.Lock.Unlock()
+
+		for _, name := range names {
+			n.sendDeferred(name)
+			n.checkReplies(name)
+		}
+	}
+}
+
+// Function to treat an expired lease as a release. The request is removed from the queue, and since a crashed
+// node never replies, the expiry also counts as its reply to the node's own request if the expired request is
+// the latest request of the other node. Returns true if the queue or the replies changed. Must be called with
+// the lock held.
+func (n *Node) expireLease(r *Resource, ID int, lease Lease) bool {
+	delete(r.Leases, ID)
+	r.Cancelled[ID] = max(r.Cancelled[ID], lease.ReqTime)
+
+	latest := true
+	for _, item := range *r.Queue {
+		if item.ID == ID && item.TimeStamp > lease.ReqTime {
+			latest = false
+		}
+	}
+	removed := n.removeRequests(r, ID, lease.ReqTime)
+	waiting := latest && r.Request && !r.Replied[ID]
+	if waiting {
+		r.Replied[ID] = true
+	}
+	if removed || waiting {
+		fmt.Printf("[NODE-%d] Lease of node %d on %s expired. Treating its request as released\n", n.ID, ID, r.Name)
+	}
+
+	// The bootstrap node stops counting a crashed node as inside the critical section
+	if n.ID == 0 {
+		n.notifyExited(ID, r.Name)
+		if ID < len(n.Finished) {
+			n.Finished[ID] = true
+		}
+	}
+	return removed || waiting
+}
+
+// Function to get the time until which the node may use a resource it holds. The zero time is returned if leases are off.
+func (n *Node) LeaseExpiry(name string) time.Time {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	return n.resource(name).LeaseExpiry
+}
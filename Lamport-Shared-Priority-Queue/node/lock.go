@@ -0,0 +1,36 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Error returned by a try to acquire a lock that is held or requested by another node
+var ErrBusy = errors.New("the lock is busy")
+
+// Function to acquire the lock on a resource until it is granted or the context is done. A cancelled request
+// is removed from the queues of the other nodes, and the error of the context is returned.
+func (n *Node) AcquireContext(ctx context.Context, name string, mode string) (int64, error) {
+	return n.acquire(ctx, name, mode, "", false)
+}
+
+// Function to acquire the locks on a set of resources in order until they are all granted or the context is done.
+// The locks that were already granted are released if the context is done.
+func (n *Node) AcquireAllContext(ctx context.Context, names []string, mode string) (map[string]int64, error) {
+	return n.acquireAll(ctx, names, mode, "")
+}
+
+// Function to acquire the lock on a resource, giving up after the timeout
+func (n *Node) LockWithTimeout(name string, mode string, timeout time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return n.AcquireContext(ctx, name, mode)
+}
+
+// Function to acquire the lock on a resource only if it is granted right away. Returns false as soon as a node
+// defers the request, in which case the request is cancelled.
+func (n *Node) TryLock(name string, mode string) (int64, bool) {
+	token, err := n.acquire(context.Background(), name, mode, "", true)
+	return token, err == nil
+}
@@ -11,4 +11,17 @@ type Message struct {
 	ReqTime int
 	Clock int
 	NumRequests int
+	K int // Number of nodes that can be in the critical section at the same time
+	Mode string // READ or WRITE access
+	Session string // Session of a group mutual exclusion request, empty if none
+	NumSessions int // Number of sessions the requesting nodes are split into, 0 for none
+	Timeout int // Seconds a requesting node waits for the lock before giving up, 0 to wait forever
+	TTL int // Seconds a request stays valid without being renewed, 0 to turn leases off
+	NumReaders int // Number of the requesting nodes that request for READ access
+	Policy string // FAIR or WRITER_PREFERENCE
+	Resource string // Name of the resource the message is about
+	NumResources int // Number of resources the requesting nodes are spread over
+	ResourcesPerRequest int // Number of resources each request takes at once
+	Secret string // Shared secret of the admin service, only set on admin calls
+	Pending int // Timestamp of the sender's own request for the resource, sent with a reply, 0 if none
 }
\ No newline at end of file
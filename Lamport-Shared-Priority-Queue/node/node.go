@@ -1,7 +1,16 @@
 package node
 
 import (
+	"common/address"
+	"common/admin"
+	"common/inspect"
+	"common/mux"
+	"common/safety"
+	"common/trace"
 	"container/heap"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/rpc"
@@ -13,193 +22,642 @@ import (
 type Node struct {
 	ID int
 	IP string
-	Queue *PriorityQueue // Containing the list of IP addresses to contact 
-	NumVotes int // Number of votes the node has
+	Bind string // Address the node listens on, IP if empty
+	Bootstrap string // Address of the bootstrap node
+	Resources map[string]*Resource // State of the lock on every resource the node has seen
+	Requested []string // Resources requested by the node
+	Mode string // READ or WRITE access requested by the node
+	Session string // Session of the node's requests, empty if the node does not join a session
+	Timeout time.Duration // How long the node waits for the lock before giving up, 0 to wait forever
+	TTL time.Duration // How long a request stays valid without being renewed, 0 to turn leases off
+	SkewMargin time.Duration // Extra time the node waits before treating the lease of another node as expired
+	Storage string // Address of the fenced storage server written to by writers in the critical section, empty for none
+	Policy string // FAIR or WRITER_PREFERENCE
+	K int // Number of nodes that can be in the critical section at the same time
 	Clock int // Lamport clock
-	Request bool // If the node is requesting the token
-	ReqTime int // Request timestamp
+	Request bool // If the node should request for the critical section
 	Network map[int]string // Map of the network
 	Finished []bool // If the node has finished
+	Safety safety.Checker // Only used by the bootstrap node
+	Tracer trace.Tracer
+	Gate inspect.Gate // Holds back the messages from other nodes while the node is paused and keeps track of the messages in flight
 	Lock sync.Mutex
 }
 
 const (
-	LOCALHOST = "127.0.0.1:"
 	ACK = "ACK"
+	DEFERRED = "DEFERRED"
 	REPLY = "REPLY"
 	REQUEST = "REQUEST"	
-	// RELEASE = "RELEASE"
+	CANCEL = "CANCEL"
+	RENEW = "RENEW"
+	RELEASE = "RELEASE"
+	READ = "READ"
+	WRITE = "WRITE"
+	FAIR = "FAIR"
+	WRITER_PREFERENCE = "WRITER_PREFERENCE"
+)
+
+// Delays that slow the protocol down so that its output can be followed
+var (
+	MessageDelay = 1 * time.Second // Time a node waits before handling a message
+	CSDuration = 2 * time.Second // Time a node spends in the critical section
 )
 
  // Function to start the RPC server
  func (n *Node) StartRPCServer() {
 	rpc.Register(n)
+	rpc.RegisterName(admin.SERVICE, &Admin{n: n})
 
-	listener, err := net.Listen("tcp", n.IP)
+	listener, err := address.Listen(n.listenAddress())
 	if err != nil {
 		fmt.Printf("[NODE-%d] could not start listening: %s\n", n.ID, err)
 		os.Exit(1)
 	}
 	defer listener.Close()
+	if Transport == GRPC_TRANSPORT {
+		// The node keeps serving net/rpc on the same address, for the dashboard and the calls whose reply is not a Message
+		grpcConns, rpcConns := mux.Split(listener, serverTLS)
+		go n.startGRPCServer(grpcConns)
+		listener = rpcConns
+	} else if serverTLS != nil {
+		listener = tls.NewListener(listener, serverTLS)
+	}
 
 	fmt.Printf("[NODE-%d] Node is running on %s\n", n.ID, n.IP)
 
 	for {
 		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
 		if err != nil {
 			fmt.Printf("[NODE-%d] accept error: %s\n", n.ID, err)
 			continue
 		}
-		go rpc.ServeConn(conn)
+		go n.serveConn(conn)
 	}
 }
 
-func (n *Node) StartRequestProcess(message Message, reply *Message) error {
+func (n *Node) startRequestProcess(message Message, reply *Message) error {
 	if n.Request {
+		ctx := context.Background()
+		if n.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, n.Timeout)
+			defer cancel()
+		}
+
+		mode := n.Mode
+		if n.Session != "" {
+			mode = READ
+		}
+		_, err := n.acquireAll(ctx, n.Requested, mode, n.Session)
+		if err == context.DeadlineExceeded {
+			fmt.Printf("[NODE-%d] Gave up on %v after waiting for %v\n", n.ID, n.Requested, n.Timeout)
+			n.Request = false
+			_, err = CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		n.CriticalSection(n.Requested...)
+		n.ReleaseAll(n.Requested)
+		n.Request = false
+	}
+	return nil
+}
+
+// Function to acquire the lock on a resource. Blocks until the node holds the lock and returns the fencing token of the entry.
+func (n *Node) Acquire(name string, mode string) (int64, error) {
+	return n.acquire(context.Background(), name, mode, "", false)
+}
+
+// Function to acquire the lock on a resource as a member of a session. Members of the same session can be in
+// the critical section at the same time, while members of other sessions, readers and writers are kept out.
+func (n *Node) AcquireSession(name string, session string) (int64, error) {
+	return n.acquire(context.Background(), name, READ, session, false)
+}
+
+// Function to request the lock on a resource until it is granted or the context is done. The requests are
+// always sent to every node before the context is checked. With try, the request is cancelled and ErrBusy is
+// returned as soon as a node defers it or if the lock is not granted once every node has answered.
+func (n *Node) acquire(ctx context.Context, name string, mode string, session string, try bool) (int64, error) {
+	n.Lock.Lock()
+	r := n.resource(name)
+	if r.Request {
+		n.Lock.Unlock()
+		return 0, fmt.Errorf("[NODE-%d] Already requesting %s", n.ID, name)
+	}
+	r.Request = true
+	r.Mode = mode
+	r.Session = session
+	r.Granted = make(chan bool, 1)
+	granted := r.Granted
+	n.trace(WAIT, name)
+
+	// Add the request to the queue
+	n.Clock++
+	r.ReqTime = n.Clock
+	heap.Push(r.Queue, Item{ID: n.ID, TimeStamp: r.ReqTime, Mode: mode, Session: session})
+	fmt.Printf("[NODE-%d] Added node %d with %s request timestamp %d for %s to the queue. New Queue: %v\n", n.ID, n.ID, mode, r.ReqTime, name, r.Queue)
+	reqTime := r.ReqTime
+	n.Lock.Unlock()
+
+	if n.TTL > 0 {
+		go n.renewLease(name, reqTime)
+	}
+
+	for i := range n.Network {
+		n.Lock.Lock()
 		n.Clock++
-		n.ReqTime = n.Clock
+		msg := Message{Type: REQUEST, ID: n.ID, ReqTime: reqTime, Mode: mode, Session: session, TTL: int(n.TTL / time.Second), Resource: name, Clock: n.Clock}
+		n.Lock.Unlock()
 
-		// Add the request to the queue
+		reply, err := CallByRPC(n.Network[i], "Node.ReceiveMessage", msg)
+		if err != nil {
+			// Withdraw the request so that the node does not keep deferring other nodes or renewing the lease
+			if !n.cancel(name) {
+				n.endRequest(name, RELEASE)
+			}
+			return 0, fmt.Errorf("[NODE-%d] Error occurred while sending a request to node %d: %s\n", n.ID, i, err)
+		}
+		if try && reply.Type == DEFERRED {
+			fmt.Printf("[NODE-%d] Node %d deferred the request for %s, so the lock is busy\n", n.ID, i, name)
+			n.cancel(name)
+			return 0, ErrBusy
+		}
+	}
+
+	// A node alone in the network does not wait for any reply
+	n.checkReplies(name)
+
+	// Every node that did not defer the request has already replied, since replies are sent before the request returns
+	if try {
+		select {
+		case <-granted:
+		default:
+			if n.cancel(name) {
+				return 0, ErrBusy
+			}
+		}
+	} else {
+		select {
+		case <-granted:
+		case <-ctx.Done():
+			if n.cancel(name) {
+				return 0, ctx.Err()
+			}
+			// The lock was granted before the request could be cancelled
+		}
+	}
+	n.trace(ACQUIRED, name)
+
+	token := fencingToken(reqTime, n.ID)
+	n.Lock.Lock()
+	r.Fence = token
+	n.Lock.Unlock()
+	return token, nil
+}
+
+// Function to withdraw a request that has not been granted yet. Returns false if the lock was already granted.
+func (n *Node) cancel(name string) bool {
+	n.Lock.Lock()
+	r := n.resource(name)
+	if r.InCS {
+		n.Lock.Unlock()
+		return false
+	}
+	reqTime := r.ReqTime
+	n.Lock.Unlock()
+
+	fmt.Printf("[NODE-%d] Cancelling the request for %s with timestamp %d\n", n.ID, name, reqTime)
+	n.trace(CANCELLED, name)
+	n.endRequest(name, CANCEL)
+	return true
+}
+
+// Function to release the lock on a resource and reply to the deferred requests
+func (n *Node) Release(name string) {
+	n.Lock.Lock()
+	r := n.resource(name)
+	held := r.InCS
+	reqTime := n.release(r)
+	n.Lock.Unlock()
+
+	if held {
+		n.trace(RELEASED, name)
+	}
+	n.sendEnd(name, RELEASE, reqTime)
+}
+
+// Function to release the lock on a resource only if the node holds it with the fencing token. The token is checked
+// and the request reset under one lock, so that a client that lost the lock cannot release the entry of a newer holder.
+func (n *Node) releaseIfFence(name string, token int64) error {
+	n.Lock.Lock()
+	r := n.resource(name)
+	if !r.InCS {
+		n.Lock.Unlock()
+		return fmt.Errorf("node %d does not hold %s", n.ID, name)
+	}
+	if r.Fence != token {
+		n.Lock.Unlock()
+		return fmt.Errorf("%s is not held with the fencing token %d", name, token)
+	}
+	reqTime := n.release(r)
+	n.Lock.Unlock()
+
+	n.trace(RELEASED, name)
+	n.sendEnd(name, RELEASE, reqTime)
+	return nil
+}
+
+// Function to reset the node's request for a resource it releases, warning if its lease expired before.
+// Returns the timestamp of the request. Must be called with the lock held.
+func (n *Node) release(r *Resource) int {
+	if !r.LeaseExpiry.IsZero() && time.Now().After(r.LeaseExpiry) {
+		fmt.Printf("[NODE-%d] WARNING: the lease on %s expired before the release, so other nodes may have entered the critical section\n", n.ID, r.Name)
+	}
+	return n.reset(r)
+}
+
+// Function to end the node's request for a resource. Every other node is sent a RELEASE or CANCEL message so that
+// the request is no longer ahead of theirs in its queue, and the deferred requests that can go ahead now are replied to.
+func (n *Node) endRequest(name string, msgType string) {
+	n.Lock.Lock()
+	reqTime := n.reset(n.resource(name))
+	n.Lock.Unlock()
+	n.sendEnd(name, msgType, reqTime)
+}
+
+// Function to send the RELEASE or CANCEL message of an ended request to every other node and reply to the
+// deferred requests. Must be called without the lock held.
+func (n *Node) sendEnd(name string, msgType string, reqTime int) {
+	var wg sync.WaitGroup
+	for i := range n.Network {
 		n.Lock.Lock()
-		heap.Push(n.Queue, Item{ID: n.ID, TimeStamp: n.ReqTime})
-		fmt.Printf("[NODE-%d] Added node %d with request timestamp %d to the queue. New Queue: %v\n", n.ID, n.ID, n.ReqTime, n.Queue)
+		n.Clock++
+		msg := Message{Type: msgType, ID: n.ID, ReqTime: reqTime, Resource: name, Clock: n.Clock}
 		n.Lock.Unlock()
 
-		for i := range n.Network {
-			_, err := CallByRPC(n.Network[i], "Node.ReceiveMessage", Message{Type: REQUEST, ID: n.ID, ReqTime: n.ReqTime, Clock: n.Clock})
+		// concurrently send the message, as every call waits for the message delay of the receiving node
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := CallByRPC(n.Network[i], "Node.ReceiveMessage", msg)
 			if err != nil {
-				return fmt.Errorf("[NODE-%d] Error occurred while sending a request to node %d: %s\n", n.ID, i, err)
+				fmt.Printf("[NODE-%d] Error occurred while sending a %s message to node %d: %s\n", n.ID, msgType, i, err)
 			}
-			n.Clock++
+		}()
+	}
+	n.sendDeferred(name)
+	wg.Wait()
+}
+
+// Function to reset the node's request for a resource and remove it from the queue. Returns the timestamp of the request.
+// Must be called with the lock held.
+func (n *Node) reset(r *Resource) int {
+	// Reset the node's request status
+	r.Replied = make(map[int]bool)
+	r.Request = false
+	r.InCS = false
+	r.LeaseExpiry = time.Time{}
+	n.removeRequests(r, n.ID, r.ReqTime)
+	return r.ReqTime
+}
+
+// Function to reply to the deferred requests for a resource that are no longer held back by the requests ahead of them
+func (n *Node) sendDeferred(name string) {
+	n.Lock.Lock()
+	r := n.resource(name)
+	replies := make(map[int]Message)
+	for _, item := range *r.Queue {
+		if r.Deferred[item.ID] && !n.blocked(r, item) {
+			delete(r.Deferred, item.ID)
+			replies[item.ID] = n.reply(r, item)
 		}
 	}
-	return nil
+	n.Lock.Unlock()
+
+	var wg sync.WaitGroup
+	for to, msg := range replies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := CallByRPC(n.Network[to], "Node.ReceiveMessage", msg)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while sending a reply message to node %d: %s\n", n.ID, to, err)
+			}
+			fmt.Printf("[NODE-%d] Sent a reply message for %s to node %d\n", n.ID, name, to)
+		}()
+	}
+	wg.Wait()
+}
+
+// Function to build the reply to a request. The reply carries the node's own request for the resource, so that
+// the requesting node counts it even if the request itself has not reached it yet. Must be called with the lock held.
+func (n *Node) reply(r *Resource, request Item) Message {
+	n.Clock++
+	msg := Message{Type: REPLY, ID: n.ID, ReqTime: request.TimeStamp, Resource: r.Name, Clock: n.Clock}
+	if r.Request {
+		msg.Pending = r.ReqTime
+		msg.Mode = r.Mode
+		msg.Session = r.Session
+	}
+	return msg
 }
 
 // Dummy critical section function
-func (n *Node) CriticalSection() {
-	n.Lock.Lock()
-	defer n.Lock.Unlock()
+func (n *Node) CriticalSection(names ...string) {
+	// Notify Bootstrap node when entering the critical section
+	for _, name := range names {
+		n.Lock.Lock()
+		r := n.resource(name)
+		mode, session := r.Mode, r.Session
+		n.Lock.Unlock()
+
+		_, err := CallByRPC(n.Bootstrap, "Node.NotifyEntered", Message{ID: n.ID, Mode: mode, Session: session, Resource: name})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+		}
+	}
+
 	// Simulate entering the critical section
-	fmt.Printf("[NODE-%d] Entering the critical section\n", n.ID)
-	time.Sleep(2 * time.Second)
-	fmt.Printf("[NODE-%d] Completed the critical section\n", n.ID)
+	if n.Session != "" {
+		fmt.Printf("[NODE-%d] Entering the critical section of %v as a member of %s\n", n.ID, names, n.Session)
+	} else {
+		fmt.Printf("[NODE-%d] Entering the critical section of %v for %s access\n", n.ID, names, n.Mode)
+	}
+	time.Sleep(CSDuration)
+
+	// Writers write to the fenced storage server at the end of the critical section
+	for _, name := range names {
+		n.Lock.Lock()
+		writer := group(n.resource(name).Mode, n.resource(name).Session) == ""
+		n.Lock.Unlock()
+		if n.Storage == "" || !writer {
+			continue
+		}
+		_, err := n.WriteStorage(name, fmt.Sprintf("written by node %d", n.ID))
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while writing to the storage server: %s\n", n.ID, err)
+		}
+	}
+	fmt.Printf("[NODE-%d] Completed the critical section of %v\n", n.ID, names)
 
 	// Notify Bootstrap node when the critical section is completed
+	n.Lock.Lock()
 	n.Clock++
-	_, err := CallByRPC(LOCALHOST + "8000", "Node.NotifyFinished", Message{ID: n.ID})
-	if err != nil {
-		fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+	n.Lock.Unlock()
+	for _, name := range names {
+		_, err := CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID, Resource: name})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+		}
 	}
 }
 
 func (n *Node)NotifyFinished(message Message, reply *Message) error {
-	n.Finished[message.ID] = true
+	n.notifyExited(message.ID, message.Resource)
+	n.Lock.Lock()
+	if message.ID < len(n.Finished) {
+		n.Finished[message.ID] = true
+	}
+	n.Lock.Unlock()
 	return nil
 }
 
 // Handle the different types of messages
 func (n *Node)ReceiveMessage(message Message, reply *Message) error {
+	n.deliver(message.Type, message)
 	n.Lock.Lock()
 	n.Clock = max(n.Clock, message.Clock) + 1
 	n.Lock.Unlock()
-	
-	time.Sleep(1 * time.Second)
 	switch message.Type {
 	case REQUEST:
-		top := n.Queue.Peek()
-		if top == nil {
-			// Direct send a reply since there is no other request in the queue
-			n.Clock++
-			fmt.Printf("[NODE-%d] Received a request from node %d. Sending a reply directly since the queue is empty\n", n.ID, message.ID)
-			_, err := CallByRPC(n.Network[message.ID], "Node.ReceiveMessage", Message{Type: REPLY, ID: n.ID, Clock: n.Clock})
-			if err != nil {
-				fmt.Printf("[NODE-%d] Error occurred while sending a reply to node %d: %s\n", n.ID, message.ID, err)
-			}
-		} else {
-			if top.(Item).TimeStamp > message.ReqTime {
-				// Directly send a reply since the request is earlier than the top of the queue
-				n.Clock++
-				fmt.Printf("[NODE-%d] Received a request from node %d. Sending a reply directly since the request is earlier than the top of the queue\n", n.ID, message.ID)
-				_, err := CallByRPC(n.Network[message.ID], "Node.ReceiveMessage", Message{Type: REPLY, ID: n.ID, Clock: n.Clock})
-				if err != nil {
-					fmt.Printf("[NODE-%d] Error occurred while sending a reply to node %d: %s\n", n.ID, message.ID, err)
-				}
-			} else if top.(Item).TimeStamp == message.ReqTime { // Case when the logical clocks could be the same
-				// Compare the IDs of the nodes
-				fmt.Printf("[NODE-%d] Received a request from node %d with same request timestamps. Comparing the IDs of the nodes\n", n.ID, message.ID)
-				if top.(Item).ID > message.ID {
-					// Directly send a reply since the message ID is smaller than the queue ID
-					fmt.Printf("[NODE-%d] Sending a reply to node %d since the ID is smaller than the queue ID\n", n.ID, message.ID)
-					_, err := CallByRPC(n.Network[message.ID], "Node.ReceiveMessage", Message{Type: REPLY, ID: n.ID, Clock: n.Clock})
-					if err != nil {
-						fmt.Printf("[NODE-%d] Error occurred while sending a reply to node %d: %s\n", n.ID, message.ID, err)
-					}
-				} else {
-					// Add the request to the queue
-					n.Lock.Lock()
-					heap.Push(n.Queue, Item{ID: message.ID, TimeStamp: message.ReqTime})
-					fmt.Printf("[NODE-%d] Added node %d with timestamp %d to the queue. New Queue: %v\n", n.ID, message.ID, message.ReqTime, n.Queue)
-					n.Lock.Unlock()
-				}
-			} else {
-				// Add the request to the queue
-				n.Lock.Lock()
-				heap.Push(n.Queue, Item{ID: message.ID, TimeStamp: message.ReqTime})
-				fmt.Printf("[NODE-%d] Added node %d with timestamp %d to the queue. New Queue: %v\n", n.ID, message.ID, message.ReqTime, n.Queue)
-				n.Lock.Unlock()
-			}
+		n.Lock.Lock()
+		r := n.resource(message.Resource)
+		request := Item{ID: message.ID, TimeStamp: message.ReqTime, Mode: message.Mode, Session: message.Session}
+		if !n.enqueue(r, request) {
+			// The release or cancel overtook the request or the lease of the request already expired
+			fmt.Printf("[NODE-%d] Ignoring the ended request for %s from node %d\n", n.ID, message.Resource, message.ID)
+			n.Lock.Unlock()
+			break
+		}
+		n.extendLease(r, message)
+		if n.blocked(r, request) {
+			r.Deferred[message.ID] = true
+			fmt.Printf("[NODE-%d] Deferring the %s request for %s from node %d. New Queue: %v\n", n.ID, message.Mode, message.Resource, message.ID, r.Queue)
+			n.Lock.Unlock()
+			// Tell the requesting node that it has to wait, so that a TryLock can give up right away
+			*reply = Message{Type: DEFERRED}
+			break
+		}
+		delete(r.Deferred, message.ID)
+		msg := n.reply(r, request)
+		n.Lock.Unlock()
+
+		// Directly send a reply since not enough requests are ahead of this one
+		fmt.Printf("[NODE-%d] Received a %s request for %s from node %d. Sending a reply directly\n", n.ID, message.Mode, message.Resource, message.ID)
+		_, err := CallByRPC(n.Network[message.ID], "Node.ReceiveMessage", msg)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while sending a reply to node %d: %s\n", n.ID, message.ID, err)
 		}
 	
 	case REPLY:
-		fmt.Printf("[NODE-%d] Received a reply from node %d\n", n.ID, message.ID)
-		n.NumVotes++ 
-		n.RequestCriticalSection()
+		n.Lock.Lock()
+		r := n.resource(message.Resource)
+		if message.Pending > 0 {
+			// The other node was requesting the resource too when it replied
+			n.enqueue(r, Item{ID: message.ID, TimeStamp: message.Pending, Mode: message.Mode, Session: message.Session})
+		}
+		if !r.Request || message.ReqTime != r.ReqTime || r.Replied[message.ID] {
+			// A reply to a cancelled request can arrive after the cancel
+			fmt.Printf("[NODE-%d] Ignoring a late reply from node %d\n", n.ID, message.ID)
+			n.Lock.Unlock()
+			break
+		}
+		fmt.Printf("[NODE-%d] Received a reply for %s from node %d\n", n.ID, message.Resource, message.ID)
+		r.Replied[message.ID] = true
+		n.Lock.Unlock()
+		n.checkReplies(message.Resource)
+
+	case RENEW:
+		n.Lock.Lock()
+		n.extendLease(n.resource(message.Resource), message)
+		n.Lock.Unlock()
+
+	case RELEASE, CANCEL:
+		n.Lock.Lock()
+		r := n.resource(message.Resource)
+		r.Cancelled[message.ID] = max(r.Cancelled[message.ID], message.ReqTime)
+		if lease, ok := r.Leases[message.ID]; ok && lease.ReqTime <= message.ReqTime {
+			delete(r.Leases, message.ID)
+		}
+		n.removeRequests(r, message.ID, message.ReqTime)
+		if message.Type == RELEASE {
+			fmt.Printf("[NODE-%d] Node %d released %s. New Queue: %v\n", n.ID, message.ID, message.Resource, r.Queue)
+		} else {
+			fmt.Printf("[NODE-%d] Node %d cancelled its request for %s. New Queue: %v\n", n.ID, message.ID, message.Resource, r.Queue)
+		}
+		n.Lock.Unlock()
+
+		// The requests behind the ended one may be able to go ahead now
+		n.sendDeferred(message.Resource)
+		n.checkReplies(message.Resource)
 	}
 	return nil
 }
 
 // Function to add a new node to the network
-func (n *Node)AddNode(message Message, reply *Message) error {
+func (n *Node)addNode(message Message, reply *Message) error {
 	n.Network[message.ID] = message.IP
 	// fmt.Printf("[NODE-%d] Added node %d to the network. New network: %v\n", n.ID, message.ID, n.Network)
 	*reply = Message{Type: ACK}
 	return nil
 }
 
-func (n *Node)RequestCriticalSection() {
-	if n.NumVotes == len(n.Network) {
-		fmt.Printf("[NODE-%d] Received all the votes: %d\n", n.ID, n.NumVotes)
-		n.CriticalSection()
+// Function to decide whether a request has to wait for the requests ahead of it in the queue of a resource. A request
+// is held back while k earlier requests are ahead of it, or while an earlier request that cannot share the critical
+// section with it is. The node's own request counts as ahead of every other request while the node holds the resource.
+// The node defers its reply to a request that is held back, and enters the critical section once every other node
+// has replied and its own request is not held back. Must be called with the lock held.
+func (n *Node) blocked(r *Resource, request Item) bool {
+	ahead := 0
+	for _, item := range *r.Queue {
+		if item.ID == request.ID {
+			continue
+		}
+		holding := item.ID == n.ID && r.InCS
+		if !holding && !n.precedes(item.Mode, item.TimeStamp, item.ID, request.Mode, request.TimeStamp, request.ID) {
+			continue
+		}
+		groupA, groupB := group(item.Mode, item.Session), group(request.Mode, request.Session)
+		if compatible(groupA, groupB) {
+			continue
+		}
+		if groupA != "" || groupB != "" {
+			// Readers and sessions never share the critical section with writers or other groups
+			return true
+		}
+		ahead++
+	}
+	return ahead >= n.K
+}
+
+// Function to add a request to the queue of a resource, in place of any earlier request of the same node.
+// Returns false if the request has already ended. Must be called with the lock held.
+func (n *Node) enqueue(r *Resource, request Item) bool {
+	if ended, ok := r.Cancelled[request.ID]; ok && ended >= request.TimeStamp {
+		return false
+	}
+	for _, item := range *r.Queue {
+		if item.ID == request.ID && item.TimeStamp > request.TimeStamp {
+			return false
+		}
+		if item.ID == request.ID && item.TimeStamp == request.TimeStamp {
+			return true
+		}
+	}
+	n.removeRequests(r, request.ID, request.TimeStamp)
+	heap.Push(r.Queue, request)
+	return true
+}
 
-		// Reset the node's request status
-		n.NumVotes = 0
-		n.Request = false
+// Function to remove the requests of a node up to a timestamp from the queue of a resource. Returns true if
+// a request was removed. Must be called with the lock held.
+func (n *Node) removeRequests(r *Resource, ID int, reqTime int) bool {
+	removed := false
+	for i := 0; i < r.Queue.Len(); i++ {
+		item := (*r.Queue)[i]
+		if item.ID == ID && item.TimeStamp <= reqTime {
+			heap.Remove(r.Queue, i)
+			removed = true
+			i--
+		}
+	}
+	if removed {
+		delete(r.Deferred, ID)
+	}
+	return removed
+}
 
-		n.Lock.Lock()
-		defer n.Lock.Unlock()
+// Function to check if the first request is served before the second one under the policy of the lock
+func (n *Node) precedes(modeA string, reqTimeA int, IDA int, modeB string, reqTimeB int, IDB int) bool {
+	if n.Policy == WRITER_PREFERENCE && modeA != modeB {
+		return modeA == WRITE
+	}
+	if reqTimeA != reqTimeB {
+		return reqTimeA < reqTimeB
+	}
+	return IDA < IDB
+}
 
-		heap.Pop(n.Queue)
-		queueLen := n.Queue.Len() // fixes the length of the queue since the length of the queue updates after every pop
-		for i := 0; i < queueLen; i++ {
-			item := heap.Pop(n.Queue)
+// Group of a request. Readers form one group and every session forms its own group, while writers are in no group.
+func group(mode string, session string) string {
+	if session != "" {
+		return "SESSION-" + session
+	}
+	if mode == READ {
+		return READ
+	}
+	return ""
+}
 
-			n.Clock++
-			msg := Message{Type: REPLY, ID: n.ID, Clock: n.Clock}
-			_, err := CallByRPC(n.Network[item.(Item).ID], "Node.ReceiveMessage", msg)
-			if err != nil {
-				fmt.Printf("[NODE-%d] Error occurred while sending a reply message to node %d: %s\n", n.ID, item.(Item).ID, err)
-			}
-			fmt.Printf("[NODE-%d] Sent a reply message to node %d\n", n.ID, item.(Item).ID)
-		}
+// Requests of the same group can share the critical section with each other but not with other requests
+func compatible(groupA string, groupB string) bool {
+	return groupA != "" && groupA == groupB
+}
+
+// Function to hand the resource to the waiting Acquire call once every other node has replied and no request
+// ahead of the node's own request holds it back
+func (n *Node) checkReplies(name string) {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	r := n.resource(name)
+	if r.Request && !r.InCS && len(r.Replied) >= len(n.Network) && !n.blocked(r, Item{ID: n.ID, TimeStamp: r.ReqTime, Mode: r.Mode, Session: r.Session}) {
+		fmt.Printf("[NODE-%d] Received every reply for %s and no request is ahead\n", n.ID, name)
+		r.InCS = true
+		r.Granted <- true
 	}
 }
 
 // Function to decide whether the node requests for vote or not
-func (n *Node) SetRequesting(message Message, reply *Message) error {
+func (n *Node) setRequesting(message Message, reply *Message) error {
+	n.K = max(message.K, 1)
+	n.Policy = FAIR
+	if message.Policy == WRITER_PREFERENCE {
+		n.Policy = WRITER_PREFERENCE
+	}
+	n.Mode = WRITE
+	if n.ID < message.NumReaders {
+		n.Mode = READ
+	}
+	n.Timeout = time.Duration(message.Timeout) * time.Second
+	n.TTL = time.Duration(message.TTL) * time.Second
+	if n.TTL > 0 {
+		go n.watchLeases()
+	}
+	n.Session = ""
+	if message.NumSessions > 0 {
+		n.Session = fmt.Sprintf("session-%d", n.ID % message.NumSessions)
+	}
+
+	numResources := max(message.NumResources, 1)
+	n.Requested = []string{}
+	for i := 0; i < min(max(message.ResourcesPerRequest, 1), numResources); i++ {
+		n.Requested = append(n.Requested, ResourceName((n.ID + i) % numResources))
+	}
+
 	n.Request = n.ID < message.NumRequests
 	if n.Request {
-		fmt.Printf("[NODE-%d] Node will request for the critical section\n", n.ID)
+		if n.Session != "" {
+			fmt.Printf("[NODE-%d] Node will request for %v as a member of %s\n", n.ID, n.Requested, n.Session)
+		} else {
+			fmt.Printf("[NODE-%d] Node will request for %s access to %v\n", n.ID, n.Mode, n.Requested)
+		}
 	} else {
 		fmt.Printf("[NODE-%d] Node will not request for the critical section\n", n.ID)
 	}
@@ -209,7 +667,13 @@ func (n *Node) SetRequesting(message Message, reply *Message) error {
 
 // Utility function to call RPC methods
 func CallByRPC(IP string, method string, message Message) (Message, error) {
-	client, err := rpc.Dial("tcp", IP)
+	if admin.IsAdmin(method) {
+		message.Secret = admin.Secret
+	}
+	if Transport == GRPC_TRANSPORT {
+		return callByGRPC(IP, method, message)
+	}
+	client, err := dial(IP)
 	if err != nil {
 		return Message{}, fmt.Errorf("error in dialing: %s", err)
 	}
@@ -221,4 +685,12 @@ func CallByRPC(IP string, method string, message Message) (Message, error) {
 		return Message{}, fmt.Errorf("error in calling %s: %s", method, err)
 	}
 	return reply, nil
-}
\ No newline at end of file
+}
+
+// Function to get the address the node listens on
+func (n *Node) listenAddress() string {
+	if n.Bind != "" {
+		return n.Bind
+	}
+	return n.IP
+}
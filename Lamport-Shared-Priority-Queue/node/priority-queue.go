@@ -3,6 +3,8 @@ package node
 type Item struct {
 	ID        int
 	TimeStamp int
+	Mode      string // READ or WRITE access requested
+	Session   string // Session the request belongs to, empty if none
 }
 
 type PriorityQueue []Item
@@ -0,0 +1,90 @@
+package node
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// State of the lock on a single named resource. Every resource has its own queue and replies,
+// so requests for different resources never wait for each other.
+type Resource struct {
+	Name string
+	Queue *PriorityQueue // Requests for the resource that have not been released yet, including the node's own request
+	Replied map[int]bool // Nodes that have replied to the current request
+	Deferred map[int]bool // Nodes whose request is in the queue and has not been replied to yet
+	Mode string // READ or WRITE access requested by the node
+	Session string // Session the node's request belongs to, empty if none
+	Request bool // If the node is requesting the resource
+	InCS bool // If the node holds the resource
+	ReqTime int // Request timestamp
+	Granted chan bool // Signalled once the node holds the resource
+	Fence int64 // Fencing token of the current entry into the critical section
+	LeaseExpiry time.Time // Time until which the node may use the resource, zero if leases are off
+	Leases map[int]Lease // Leases of the other nodes on their requests
+	Cancelled map[int]int // Timestamp of the last released, cancelled or expired request of each node
+}
+
+// Name of the i-th resource used by the bootstrap workload
+func ResourceName(i int) string {
+	return fmt.Sprintf("resource-%d", i)
+}
+
+// Function to get the state of a resource, creating it on first use. Must be called with the lock held.
+func (n *Node) resource(name string) *Resource {
+	if n.Resources == nil {
+		n.Resources = make(map[string]*Resource)
+	}
+
+	r, ok := n.Resources[name]
+	if !ok {
+		queue := make(PriorityQueue, 0)
+		heap.Init(&queue)
+		r = &Resource{Name: name, Queue: &queue, Replied: make(map[int]bool), Deferred: make(map[int]bool), Mode: WRITE, Leases: make(map[int]Lease), Cancelled: make(map[int]int)}
+		n.Resources[name] = r
+	}
+	return r
+}
+
+// Function to acquire the locks on a set of resources. The locks are always taken in the order of their names,
+// so two nodes can never wait for each other's locks in a cycle.
+func (n *Node) AcquireAll(names []string, mode string) (map[string]int64, error) {
+	return n.acquireAll(context.Background(), names, mode, "")
+}
+
+// Function to acquire the locks on a set of resources as a member of a session
+func (n *Node) AcquireAllSession(names []string, session string) (map[string]int64, error) {
+	return n.acquireAll(context.Background(), names, READ, session)
+}
+
+// Function to acquire the locks on a set of resources in order. Returns the fencing token of every resource.
+func (n *Node) acquireAll(ctx context.Context, names []string, mode string, session string) (map[string]int64, error) {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	sorted = slices.Compact(sorted)
+
+	tokens := make(map[string]int64)
+	for i, name := range sorted {
+		token, err := n.acquire(ctx, name, mode, session, false)
+		if err != nil {
+			n.ReleaseAll(sorted[:i])
+			return nil, err
+		}
+		tokens[name] = token
+	}
+	return tokens, nil
+}
+
+// Function to release the locks on a set of resources in the reverse order of acquisition
+func (n *Node) ReleaseAll(names []string) {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	sorted = slices.Compact(sorted)
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		n.Release(sorted[i])
+	}
+}
@@ -0,0 +1,78 @@
+package node
+
+import (
+	"common/inspect"
+	"slices"
+	"sort"
+)
+
+// What a node knows about the network and its locks, returned by Node.GetState
+type State struct {
+	inspect.Snapshot // Part of the state shown by the console and the dashboard, the same in every protocol
+	Bootstrap string // Address of the bootstrap node
+	K int // Number of nodes that can be in the critical section at the same time
+	Policy string // FAIR or WRITER_PREFERENCE
+	Request bool // If the node should request for the critical section
+	Network map[int]string // Addresses of the other nodes
+	Finished []bool // If each node has finished, as far as the node knows
+	Tracing bool // If the trace is being recorded
+	Resources []ResourceState // Every resource the node has seen, in the order of their names
+}
+
+// State of the lock on a single resource, as part of the state of a node
+type ResourceState struct {
+	Name string
+	Request bool // If the node is requesting the resource
+	ReqTime int // Request timestamp
+	Mode string // READ or WRITE access requested by the node
+	Session string // Session the node's request belongs to, empty if none
+	InCS bool // If the node holds the resource
+	Fence int64 // Fencing token of the current entry into the critical section
+	Queue []Item // Requests for the resource in order of priority, including the node's own request
+	Replied []int // Nodes that have replied to the current request, in order of their IDs
+	RepliesNeeded int // Number of replies the node needs to enter the critical section, one from every other node
+	Deferred []int // Nodes whose requests the node has not replied to yet, in order of their IDs
+}
+
+// Function to report what the node knows about the network and its locks. It does not change the node, so
+// tests, dashboards and the console can call it at any time.
+func (n *Node) GetState(message Message, reply *State) error {
+	*reply = n.state()
+	return nil
+}
+
+// Function to take a copy of the state of the node
+func (n *Node) state() State {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	state := State{Snapshot: inspect.Snapshot{ID: n.ID, IP: n.IP, Protocol: "lamport", Clock: n.Clock, Paused: n.Gate.Paused(), InFlight: n.Gate.Messages()}, Bootstrap: n.Bootstrap, K: n.K, Policy: n.Policy, Request: n.Request, Network: make(map[int]string), Finished: slices.Clone(n.Finished), Tracing: n.tracing(), Resources: []ResourceState{}}
+	for ID, IP := range n.Network {
+		state.Network[ID] = IP
+	}
+	for _, r := range n.sortedResources() {
+		resource := ResourceState{Name: r.Name, Request: r.Request, ReqTime: r.ReqTime, Mode: r.Mode, Session: r.Session, InCS: r.InCS, Fence: r.Fence, Queue: append([]Item{}, *r.Queue...), Replied: []int{}, RepliesNeeded: len(n.Network), Deferred: []int{}}
+		sort.Slice(resource.Queue, func(i, j int) bool { return PriorityQueue(resource.Queue).Less(i, j) })
+		for ID := range r.Replied {
+			resource.Replied = append(resource.Replied, ID)
+		}
+		slices.Sort(resource.Replied)
+		for ID := range r.Deferred {
+			resource.Deferred = append(resource.Deferred, ID)
+		}
+		slices.Sort(resource.Deferred)
+		state.Resources = append(state.Resources, resource)
+	}
+	summarize(&state)
+	return state
+}
+
+// Function to get the resources the node has seen in the order of their names. Must be called with the lock held.
+func (n *Node) sortedResources() []*Resource {
+	resources := []*Resource{}
+	for _, r := range n.Resources {
+		resources = append(resources, r)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Name < resources[j].Name })
+	return resources
+}
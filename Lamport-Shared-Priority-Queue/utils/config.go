@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"common/admin"
+	"common/config"
+	"lamport_shared_priority_queue/node"
+	"time"
+)
+
+// Function to read the configuration of the node, with the flags this protocol takes
+func LoadConfig(args []string) (config.Config, error) {
+	defaults := config.Default(node.MessageDelay, node.CSDuration)
+	defaults.SkewMargin = config.Duration(node.DEFAULT_SKEW_MARGIN)
+	return config.Load(args, defaults, config.K, config.READERS, config.POLICY, config.SESSIONS, config.TIMEOUT, config.TTL, config.RESOURCES, config.PER_REQUEST, config.TRACE_DIR, config.TLS_DIR, config.TRANSPORT, config.STORAGE, config.HTTP_PORT, config.LEASE_SKEW_MARGIN)
+}
+
+// Function to apply the settings that do not depend on the ID of the node
+func Apply(cfg config.Config) error {
+	node.MessageDelay = time.Duration(cfg.MessageDelay)
+	node.CSDuration = time.Duration(cfg.CSDuration)
+	admin.Secret = cfg.AdminSecret
+	return cfg.OpenLog()
+}
+
+// Function to build the message that hands out the workload
+func WorkloadMessage(w config.Workload) node.Message {
+	policy := node.FAIR
+	if w.Policy == "writer" {
+		policy = node.WRITER_PREFERENCE
+	}
+	return node.Message{NumRequests: w.Requests, K: max(w.K, 1), NumReaders: w.Readers, Policy: policy, NumSessions: w.Sessions, Timeout: w.Timeout, TTL: w.TTL, NumResources: w.Resources, ResourcesPerRequest: w.PerRequest}
+}
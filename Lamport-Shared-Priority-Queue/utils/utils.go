@@ -2,11 +2,8 @@ package utils
 
 import (
 	"container/heap"
-	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"lamport_shared_priority_queue/node"
-	"os"
 	"time"
 )
 
@@ -16,33 +13,25 @@ func NewPriorityQueue() *node.PriorityQueue {
 	return &pq
 }
 
-func ReadNodesList() map[int]string {
-	jsonFile, err := os.Open("nodes-list.json")
-	if err != nil {
-		fmt.Println("Error opening nodes-list.json file:", err)
-	}
-	defer jsonFile.Close()
-
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-
-	var nodesList map[int]string
-
-	json.Unmarshal(byteValue, &nodesList) // Puts the byte value into the nodesList map
-
-	return nodesList
-}
-
 // Calculate the time taken from the first node to request to the last node to exist the critical section
 func CalculateTimeTaken(n *node.Node, numRequests int) {
 	startTime := time.Now()
 
 	if n.ID == 0 {
+		n.Lock.Lock()
 		n.Finished = make([]bool, numRequests)
+		n.Lock.Unlock()
 		for {
-			if all(n.Finished) {
+			// The nodes report that they finished concurrently, so the list is only read under the lock
+			n.Lock.Lock()
+			done := all(n.Finished)
+			n.Lock.Unlock()
+			if done {
 				fmt.Printf("Time taken for all nodes to exit the critical section: %v\n", time.Since(startTime))
+				n.PrintSafetyCheck()
 				break
 			}
+			time.Sleep(10 * time.Millisecond)
 		}
 	}
 }
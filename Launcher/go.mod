@@ -0,0 +1,7 @@
+module launcher
+
+go 1.23.2
+
+require common v0.0.0
+
+replace common => ../Common
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"bufio"
+	"common/address"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	STOP_TIMEOUT = 3 * time.Second // How long a node gets to shut down before it is killed
+)
+
+// Protocol the launcher can start
+type Protocol struct {
+	Dir string // Directory of the protocol, relative to the root of the repository
+	Workload []string // Workload flags the launcher passes on to the bootstrap node
+}
+
+var protocols = map[string]Protocol{
+	"lamport": {Dir: "Lamport-Shared-Priority-Queue", Workload: []string{"requests", "k", "readers", "policy", "sessions", "timeout", "ttl", "resources", "per-request"}},
+	"voting": {Dir: "Voting-Protocol", Workload: []string{"requests", "k", "readers", "policy", "timeout", "ttl", "resources", "per-request"}},
+	"ring": {Dir: "Fair-Ring-Protocol", Workload: []string{"requests", "k", "timeout", "ttl", "resources", "per-request"}},
+	"centralized": {Dir: "Centralized-Protocol", Workload: []string{"requests", "k"}},
+	"suzuki-kasami": {Dir: "Suzuki-Kasami-Protocol", Workload: []string{"requests"}},
+	"raymond": {Dir: "Raymond-Tree-Protocol", Workload: []string{"requests"}},
+	"naimi-trehel": {Dir: "Naimi-Trehel-Protocol", Workload: []string{"requests"}},
+}
+
+// Colors of the node prefixes, picked in turn
+var colors = []int{36, 33, 32, 35, 34, 31, 96, 93, 92, 95, 94, 91}
+
+// Node process started by the launcher
+type Process struct {
+	ID int
+	Cmd *exec.Cmd
+	Done chan struct{} // Closed once the process has exited
+}
+
+// Launcher of a local network of nodes
+type Launcher struct {
+	Dir string // Directory of the protocol, where the nodes run
+	Binary string
+	Args []string // Arguments passed on to every node
+	Addrs []string // Address of every node by ID
+	Color bool
+	Processes []*Process
+	Finished chan bool // Signalled once the bootstrap node reports the time taken
+	Probe *tls.Config // TLS settings for checking that a node is reachable, nil to only open a TCP connection
+	OutputLock sync.Mutex
+}
+
+// Tool to start a local network of nodes of one protocol, hand the workload to the bootstrap node,
+// show the output of every node and shut the network down again
+func main() {
+	protocolName := flag.String("protocol", "lamport", "protocol to run: " + protocolNames())
+	numNodes := flag.Int("nodes", 10, "number of nodes to start")
+	requests := flag.Int("requests", -1, "number of nodes that request for the critical section, all the nodes by default")
+	flag.Int("k", 1, "number of nodes that can be in the critical section at the same time")
+	flag.Int("readers", 0, "number of the requesting nodes that request for shared (read) access")
+	flag.String("policy", "fair", "policy of the lock: fair or writer")
+	flag.Int("sessions", 0, "number of sessions the requesting nodes are split into, 0 for none")
+	flag.Int("timeout", 0, "seconds a node waits for the lock before giving up, 0 to wait forever")
+	flag.Int("ttl", 0, "seconds a lease lasts without being renewed, 0 to turn leases off")
+	flag.Int("resources", 1, "number of resources the requesting nodes are spread over")
+	flag.Int("per-request", 1, "number of resources each request takes at once")
+	flag.Duration("message-delay", time.Second, "time a node waits before handling a message")
+	flag.Duration("cs-duration", 2 * time.Second, "time a node spends in the critical section")
+	addr := flag.String("addr", address.DEFAULT, "address of the bootstrap node, node i listens on the same host with the port increased by i")
+	root := flag.String("root", "..", "root directory of the repository")
+	keep := flag.Bool("keep", false, "keep the nodes running after the requests are done, until interrupted")
+	linger := flag.Duration("linger", 2 * time.Second, "how long to keep showing the output after the requests are done")
+	startup := flag.Duration("startup", 15 * time.Second, "how long to wait for a node to start")
+	noColor := flag.Bool("no-color", false, "do not color the node prefixes")
+	flag.Parse()
+
+	protocol, ok := protocols[*protocolName]
+	if !ok {
+		fmt.Printf("[LAUNCHER] Unknown protocol %q, expected one of %s\n", *protocolName, protocolNames())
+		os.Exit(1)
+	}
+	if *numNodes < 1 {
+		fmt.Println("[LAUNCHER] At least one node is needed")
+		os.Exit(1)
+	}
+	if *requests < 0 {
+		flag.Set("requests", strconv.Itoa(*numNodes))
+	}
+
+	l := &Launcher{
+		Dir: filepath.Join(*root, protocol.Dir),
+		Color: !*noColor && os.Getenv("NO_COLOR") == "",
+		Finished: make(chan bool, 1),
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "message-delay" || f.Name == "cs-duration" {
+			l.Args = append(l.Args, "-" + f.Name, f.Value.String())
+		}
+	})
+
+	for i := 0; i < *numNodes; i++ {
+		nodeAddr, err := address.Offset(*addr, i)
+		if err != nil {
+			fmt.Println("[LAUNCHER] Error occurred while choosing the addresses of the nodes:", err)
+			os.Exit(1)
+		}
+		if reachable(nodeAddr, nil) {
+			fmt.Printf("[LAUNCHER] %s is already in use. Is another network still running?\n", nodeAddr)
+			os.Exit(1)
+		}
+		l.Addrs = append(l.Addrs, nodeAddr)
+	}
+
+	tmp, err := os.MkdirTemp("", "launcher-")
+	if err != nil {
+		fmt.Println("[LAUNCHER] Error occurred while creating a temporary directory:", err)
+		os.Exit(1)
+	}
+	defer os.RemoveAll(tmp)
+	l.Binary = filepath.Join(tmp, "node")
+	fmt.Printf("[LAUNCHER] Building %s\n", l.Dir)
+	build := exec.Command("go", "build", "-o", l.Binary, ".")
+	build.Dir = l.Dir
+	build.Stdout = os.Stdout
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Println("[LAUNCHER] Error occurred while building the nodes:", err)
+		os.Exit(1)
+	}
+
+	host, _, _ := net.SplitHostPort(*addr)
+	l.Probe = probeTLS(l.Dir, host)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	code := l.run(protocol, *numNodes, *startup, *keep, *linger, sigChan)
+	l.stop()
+	os.Exit(code)
+}
+
+// Function to start the nodes, start the requests and wait until they are done. Returns the exit code.
+func (l *Launcher) run(protocol Protocol, numNodes int, startup time.Duration, keep bool, linger time.Duration, sigChan chan os.Signal) int {
+	err := writeNodesList(l.Dir, map[int]string{})
+	if err != nil {
+		fmt.Println("[LAUNCHER] Error occurred while resetting nodes-list.json:", err)
+		return 1
+	}
+
+	// The bootstrap node hands out the workload and starts the requests once all the nodes have joined
+	bootstrapArgs := []string{"-nodes", strconv.Itoa(numNodes)}
+	for _, name := range protocol.Workload {
+		bootstrapArgs = append(bootstrapArgs, "-" + name, flag.Lookup(name).Value.String())
+	}
+
+	// Nodes register in nodes-list.json as they join, so they are started one after the other
+	for i := 0; i < numNodes; i++ {
+		args := append([]string{"-id", strconv.Itoa(i), "-advertise", l.Addrs[i]}, l.Args...)
+		if i == 0 {
+			args = append(args, bootstrapArgs...)
+		}
+		p, err := l.start(i, args)
+		if err != nil {
+			fmt.Printf("[LAUNCHER] Error occurred while starting node %d: %s\n", i, err)
+			return 1
+		}
+		addr := l.Addrs[i]
+		deadline := time.After(startup)
+		for !reachable(addr, l.Probe) || len(readNodesList(l.Dir)) <= i {
+			select {
+			case <-p.Done:
+				fmt.Printf("[LAUNCHER] Node %d exited while starting\n", i)
+				return 1
+			case <-deadline:
+				fmt.Printf("[LAUNCHER] Node %d did not start listening on %s within %v\n", i, addr, startup)
+				return 1
+			case <-sigChan:
+				return 1
+			case <-time.After(100 * time.Millisecond):
+			}
+		}
+	}
+	fmt.Printf("[LAUNCHER] All %d nodes are running on %s to %s\n", numNodes, l.Addrs[0], l.Addrs[numNodes - 1])
+
+	bootstrap := l.Processes[0]
+
+	if keep {
+		fmt.Println("[LAUNCHER] Press Ctrl+C to stop the nodes")
+		<-sigChan
+		return 0
+	}
+	select {
+	case <-l.Finished:
+	case <-bootstrap.Done:
+		fmt.Println("[LAUNCHER] The bootstrap node exited before the requests were done")
+		return 1
+	case <-sigChan:
+		return 1
+	}
+	select {
+	case <-time.After(linger):
+	case <-sigChan:
+	}
+	return 0
+}
+
+// Function to start the node with the given ID and command-line arguments
+func (l *Launcher) start(ID int, args []string) (*Process, error) {
+	cmd := exec.Command(l.Binary, args...)
+	cmd.Dir = l.Dir
+	// The launcher stops the nodes itself, so Ctrl+C must not reach them
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+	p := &Process{ID: ID, Cmd: cmd, Done: make(chan struct{})}
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		r.Close()
+		return nil, err
+	}
+	l.Processes = append(l.Processes, p)
+
+	go func() {
+		l.forward(ID, r)
+		cmd.Wait()
+		close(p.Done)
+	}()
+	return p, nil
+}
+
+// Function to show the output of a node line by line with its prefix, and watch it for the progress of the run
+func (l *Launcher) forward(ID int, r io.ReadCloser) {
+	defer r.Close()
+	prefix := fmt.Sprintf("node-%-2d | ", ID)
+	if l.Color {
+		prefix = fmt.Sprintf("\x1b[%dm%s\x1b[0m", colors[ID % len(colors)], prefix)
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64 * 1024), 1024 * 1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		l.OutputLock.Lock()
+		fmt.Println(prefix + line)
+		l.OutputLock.Unlock()
+
+		if ID == 0 && strings.Contains(line, "Time taken for all nodes") {
+			select {
+			case l.Finished <- true:
+			default:
+			}
+		}
+	}
+}
+
+// Function to stop the nodes, newest first, since every node removes itself from nodes-list.json when it stops
+func (l *Launcher) stop() {
+	for i := len(l.Processes) - 1; i >= 0; i-- {
+		p := l.Processes[i]
+		p.Cmd.Process.Signal(syscall.SIGTERM)
+		select {
+		case <-p.Done:
+		case <-time.After(STOP_TIMEOUT):
+			fmt.Printf("[LAUNCHER] Node %d did not stop within %v. Killing it\n", p.ID, STOP_TIMEOUT)
+			p.Cmd.Process.Kill()
+			<-p.Done
+		}
+	}
+	err := writeNodesList(l.Dir, map[int]string{})
+	if err != nil {
+		fmt.Println("[LAUNCHER] Error occurred while resetting nodes-list.json:", err)
+	}
+	fmt.Printf("[LAUNCHER] Stopped %d nodes\n", len(l.Processes))
+}
+
+// Function to check if a node accepts connections. With mutual TLS, the TLS handshake must succeed as well.
+func reachable(addr string, config *tls.Config) bool {
+	conn, err := net.DialTimeout("tcp", addr, 200 * time.Millisecond)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+	if config == nil {
+		return true
+	}
+	tlsConn := tls.Client(conn, config)
+	tlsConn.SetDeadline(time.Now().Add(time.Second))
+	return tlsConn.Handshake() == nil
+}
+
+// Function to get the TLS settings for checking the nodes on a host if TLS_DIR is set. The launcher presents the
+// operator certificate, so that the nodes do not reject the check.
+func probeTLS(dir string, host string) *tls.Config {
+	certs := os.Getenv("TLS_DIR")
+	if certs == "" {
+		return nil
+	}
+	if !filepath.IsAbs(certs) {
+		// The nodes run in the directory of the protocol
+		certs = filepath.Join(dir, certs)
+	}
+	caPEM, err := os.ReadFile(filepath.Join(certs, "ca.crt"))
+	if err != nil {
+		fmt.Println("[LAUNCHER] Error occurred while loading the CA, only checking that the nodes accept TCP connections:", err)
+		return nil
+	}
+	ca := x509.NewCertPool()
+	ca.AppendCertsFromPEM(caPEM)
+	cert, err := tls.LoadX509KeyPair(filepath.Join(certs, "operator.crt"), filepath.Join(certs, "operator.key"))
+	if err != nil {
+		fmt.Println("[LAUNCHER] Error occurred while loading the operator certificate, only checking that the nodes accept TCP connections:", err)
+		return nil
+	}
+	// gRPC servers only accept connections that offer HTTP/2
+	return &tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: ca, ServerName: host, NextProtos: []string{"h2"}, MinVersion: tls.VersionTLS13}
+}
+
+// Function to read the nodes registered in the directory of the protocol
+func readNodesList(dir string) map[int]string {
+	nodesList := make(map[int]string)
+	data, err := os.ReadFile(filepath.Join(dir, "nodes-list.json"))
+	if err != nil {
+		return nodesList
+	}
+	json.Unmarshal(data, &nodesList)
+	return nodesList
+}
+
+func writeNodesList(dir string, nodesList map[int]string) error {
+	data, err := json.Marshal(nodesList)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "nodes-list.json"), data, os.ModePerm)
+}
+
+func protocolNames() string {
+	names := []string{}
+	for name := range protocols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,10 @@
+module naimi_trehel
+
+go 1.23.2
+
+require (
+	common v0.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace common => ../Common
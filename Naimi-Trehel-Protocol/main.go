@@ -0,0 +1,181 @@
+package main
+
+import (
+	"common/config"
+	"errors"
+	"flag"
+	"fmt"
+	"naimi_trehel/node"
+	"naimi_trehel/utils"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+func main() {
+	cfg, err := utils.LoadConfig(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Println("Error occurred while reading the configuration:", err)
+		os.Exit(1)
+	}
+	err = utils.Apply(cfg)
+	if err != nil {
+		fmt.Println("Error occurred while opening the log:", err)
+		os.Exit(1)
+	}
+
+	n := node.Node{
+		Next: node.NONE,
+		Clock: 0,
+		Lock: sync.Mutex{}, 
+	}
+
+	var nodesList map[int]string = cfg.NodesList()
+
+	// Without an ID in the configuration, the node takes the next free one, and the first node is the bootstrap node
+	n.ID, n.IP, n.Bind, err = cfg.Identity(nodesList)
+	if err != nil {
+		fmt.Println("Error occurred while choosing the ID of the node:", err)
+		os.Exit(1)
+	}
+	n.Bootstrap = cfg.BootstrapAddress(n.ID, n.IP, nodesList)
+	n.Network = make(map[int]string)
+	for i := range nodesList {
+		if i != n.ID {
+			n.Network[i] = nodesList[i]
+		}
+	}
+	if n.ID == 0 {
+		n.Last = node.NONE // The bootstrap node is the root of the tree and starts with the token
+		n.HasToken = true
+	} else {
+		n.Last = 0 // Every node initially points at the bootstrap node
+	}
+
+	go n.StartRPCServer()
+
+	// With a fixed peer list every node already knows the others, so it only joins through the registry
+	if len(cfg.Peers) == 0 {
+		for i := range nodesList {
+			message := node.Message{ID: n.ID, IP: n.IP}
+			_, err := node.CallByRPC(nodesList[i], "Admin.AddNode", message)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while adding node %d to the network: %s\n", n.ID, i, err)
+			}
+		}
+
+		nodesList[n.ID] = n.IP
+
+		err = config.WriteNodesList(cfg.Registry, nodesList)
+		if err != nil {
+			fmt.Printf("Error occurred while updating %s: %s\n", cfg.Registry, err)
+		}
+	}
+
+	var numRequests int
+	var configured sync.WaitGroup
+	if cfg.IsBootstrap(n.ID) && cfg.Nodes > 0 {
+		// Hand out the workload from the configuration once enough nodes have joined
+		fmt.Printf("[NODE-%d] Waiting for %d nodes to join\n", n.ID, cfg.Nodes)
+		nodesList = cfg.WaitForNodes()
+		message := utils.WorkloadMessage(cfg.Workload)
+		numRequests = message.NumRequests
+		for i := range nodesList {
+			configured.Add(1)
+			go func(i int) {
+				defer configured.Done()
+				_, err := node.CallByRPC(nodesList[i], "Admin.SetRequesting", message)
+				if err != nil {
+					fmt.Printf("[NODE-%d] Error occurred while setting the request flag for node %d: %s\n", n.ID, i, err)
+				}
+			}(i)
+		}
+	} else if cfg.IsBootstrap(n.ID) {
+		fmt.Printf("[NODE-%d] Make sure all the nodes are up and running.\n", n.ID)
+		fmt.Printf("[NODE-%d] How many nodes should request for CS: \n", n.ID)
+		fmt.Scan(&numRequests)
+
+		nodesList = cfg.NodesList()
+		message := node.Message{NumRequests: numRequests}
+		for i := range nodesList {
+			go func(i int) {
+				_, err := node.CallByRPC(nodesList[i], "Admin.SetRequesting", message)
+				if err != nil {
+					fmt.Printf("[NODE-%d] Error occurred while setting the request flag for node %d: %s\n", n.ID, i, err)
+				}
+			}(i)
+		}
+	}
+	
+	// Start the request process
+	if cfg.IsBootstrap(n.ID) {
+		var answer string
+		go func() {
+			if cfg.Nodes > 0 {
+				// Every node must know its workload before the requests start
+				configured.Wait()
+			} else {
+				for {
+					fmt.Printf("[NODE-%d] Do you want to start the request process? (y/n): ", n.ID)
+					fmt.Scan(&answer)
+					if answer == "y" {
+						break
+					}
+					fmt.Printf("[NODE-%d] Waiting for all nodes to be ready...\n", n.ID)
+				}
+				if cfg.Console {
+					go n.RunConsole(os.Stdin)
+				}
+			}
+			network := map[int]string{n.ID: n.IP}
+			for i := range n.Network {
+				network[i] = n.Network[i]
+			}
+			for i := range network {
+				go func(i int) {
+					_, err := node.CallByRPC(network[i], "Admin.StartRequestProcess", node.Message{})
+					if err != nil {
+						fmt.Printf("[NODE-%d] Error occurred while starting the request process for node %d: %s\n", n.ID, i, err)
+					}
+				}(i)
+			}
+		}()
+
+		go utils.CalculateTimeTaken(&n, numRequests)
+	}
+
+	// The bootstrap node reads the answers to its prompts from the console first
+	if cfg.Console && !(cfg.IsBootstrap(n.ID) && cfg.Nodes == 0) {
+		go n.RunConsole(os.Stdin)
+	}
+
+	// Handling when the node fails or is shut down
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	// For cleanup after the node is shut down
+	go func() {
+		<-sigChan
+		fmt.Println("Shutting down...")
+
+		// Remove the node from the list
+		if len(cfg.Peers) == 0 {
+			nodesList = config.ReadNodesList(cfg.Registry)
+
+			delete(nodesList, n.ID) // remove the element that left the network from the nodesList
+
+			err := config.WriteNodesList(cfg.Registry, nodesList)
+			if err != nil {
+				fmt.Printf("Error occurred while updating %s: %s\n", cfg.Registry, err)
+			}
+		}
+		os.Exit(0)
+	}()
+
+	select {}
+}
+
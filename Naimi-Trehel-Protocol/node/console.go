@@ -0,0 +1,157 @@
+package node
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Commands of the console and what they do
+var consoleCommands = [][2]string{
+	{"request", "request the critical section and stay in it until release"},
+	{"release", "leave a critical section requested from the console"},
+	{"status", "show whether the node is requesting, holds the token or is in the critical section"},
+	{"queue", "show the node the token is passed to after the node, the local part of the distributed queue"},
+	{"votes", "not used by Naimi-Trehel, which grants the critical section with a token"},
+	{"peers", "show the other nodes of the network and the probable owner of the token"},
+	{"clock", "show the Lamport clock"},
+	{"pause", "hold back the messages from other nodes"},
+	{"step", "handle the oldest message that is held back and stay paused"},
+	{"resume", "handle the messages that were held back and the ones that follow"},
+	{"trace on | trace off", "not available, Naimi-Trehel does not record traces"},
+	{"help", "show the commands"},
+}
+
+// Function to read operator commands line by line until the input ends, so that scenarios can be driven by hand
+func (n *Node) RunConsole(in io.Reader) {
+	fmt.Printf("[NODE-%d] Console is ready, type help for the commands\n", n.ID)
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		n.runCommand(fields[0], fields[1:])
+	}
+}
+
+// Function to run a single console command
+func (n *Node) runCommand(command string, args []string) {
+	switch command {
+	case "request":
+		n.Lock.Lock()
+		if n.Request {
+			n.Lock.Unlock()
+			fmt.Printf("[NODE-%d] Already requesting the critical section\n", n.ID)
+			return
+		}
+		n.Request = true
+		n.Hold = make(chan bool)
+		n.Lock.Unlock()
+		go n.startRequestProcess(Message{}, &Message{})
+
+	case "release":
+		n.release()
+
+	case "status":
+		state := n.state()
+		fmt.Printf("[NODE-%d] Address: %s, clock: %d, requesting: %t, holding the token: %t, in the critical section: %t, paused: %t\n", n.ID, state.IP, state.Clock, state.Requesting, state.HasToken, state.HasToken && state.Requesting, state.Paused)
+
+	case "queue":
+		n.printLines(n.state().Queues)
+
+	case "votes":
+		fmt.Printf("[NODE-%d] Naimi-Trehel does not use votes, a node enters the critical section with the token\n", n.ID)
+
+	case "peers":
+		n.printPeers()
+		n.Lock.Lock()
+		if n.Last == NONE {
+			fmt.Printf("[NODE-%d] The node is the root of the tree\n", n.ID)
+		} else {
+			fmt.Printf("[NODE-%d] Probable owner of the token: node %d\n", n.ID, n.Last)
+		}
+		n.Lock.Unlock()
+
+	case "clock":
+		fmt.Printf("[NODE-%d] Clock: %d\n", n.ID, n.state().Clock)
+
+	case "pause":
+		n.Gate.PauseNode(n.ID)
+
+	case "step":
+		n.Gate.StepNode(n.ID)
+
+	case "resume":
+		n.Gate.ResumeNode(n.ID)
+
+	case "trace":
+		fmt.Printf("[NODE-%d] Naimi-Trehel does not record traces\n", n.ID)
+
+	case "help":
+		for _, c := range consoleCommands {
+			fmt.Printf("  %-32s %s\n", c[0], c[1])
+		}
+
+	default:
+		fmt.Printf("[NODE-%d] Unknown command %q, type help for the commands\n", n.ID, command)
+	}
+}
+
+// Function to leave a critical section requested from the console
+func (n *Node) release() {
+	n.Lock.Lock()
+	hold := n.Hold
+	if !n.Holding {
+		n.Lock.Unlock()
+		if hold != nil {
+			fmt.Printf("[NODE-%d] Still waiting for the critical section, the request cannot be withdrawn\n", n.ID)
+		} else {
+			fmt.Printf("[NODE-%d] Not in a critical section requested from the console\n", n.ID)
+		}
+		return
+	}
+	n.Hold = nil
+	n.Holding = false
+	n.Lock.Unlock()
+	close(hold)
+}
+
+// Function to stay in the critical section until it is released from the console, or for CSDuration otherwise
+func (n *Node) stayInCriticalSection() {
+	n.Lock.Lock()
+	hold := n.Hold
+	n.Holding = hold != nil
+	n.Lock.Unlock()
+
+	if hold == nil {
+		time.Sleep(CSDuration)
+		return
+	}
+	fmt.Printf("[NODE-%d] Staying in the critical section until it is released from the console\n", n.ID)
+	<-hold
+}
+
+// Function to print lines of the state of the node
+func (n *Node) printLines(lines []string) {
+	for _, line := range lines {
+		fmt.Printf("[NODE-%d] %s\n", n.ID, line)
+	}
+}
+
+// Function to print the addresses of the other nodes
+func (n *Node) printPeers() {
+	state := n.state()
+	IDs := []int{}
+	for ID := range state.Network {
+		IDs = append(IDs, ID)
+	}
+	slices.Sort(IDs)
+	fmt.Printf("[NODE-%d] Bootstrap node: %s\n", n.ID, state.Bootstrap)
+	for _, ID := range IDs {
+		fmt.Printf("[NODE-%d] Node %d: %s\n", n.ID, ID, state.Network[ID])
+	}
+}
@@ -0,0 +1,41 @@
+package node
+
+import (
+	"common/inspect"
+	"fmt"
+)
+
+// Function to fill in the part of the state shown by the console and the dashboard
+func summarize(state *State) {
+	state.Phase = phase(state.HasToken && state.Requesting, state.Requesting)
+	state.Token = state.HasToken
+	state.Status = statusLines(*state)
+	state.Queues = queueLines(*state)
+}
+
+// Function to describe the request of a node and where it sends its requests
+func statusLines(state State) []string {
+	if state.Last == NONE {
+		return []string{fmt.Sprintf("Requesting: %t, root of the tree", state.Requesting)}
+	}
+	return []string{fmt.Sprintf("Requesting: %t, probable owner of the token: node %d", state.Requesting, state.Last)}
+}
+
+// Function to describe the node that waits for the token after a node
+func queueLines(state State) []string {
+	if state.Next == NONE {
+		return []string{"No node waits for the token after the node"}
+	}
+	return []string{fmt.Sprintf("Node %d receives the token after the node", state.Next)}
+}
+
+// Function to get what the node is doing with the critical section
+func phase(inCS bool, requesting bool) inspect.Phase {
+	switch {
+	case inCS:
+		return inspect.IN_CS
+	case requesting:
+		return inspect.REQUESTING
+	}
+	return inspect.IDLE
+}
@@ -0,0 +1,12 @@
+package node
+
+type Message struct {
+	Type string // Request, Token
+	ID int
+	IP string
+	ReqTime int
+	Clock int
+	NumRequests int
+	Requester int // ID of the node that asked for the token, kept while the request is forwarded
+	Secret string // Shared secret of the admin service, only set on admin calls
+}
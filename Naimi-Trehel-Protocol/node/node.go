@@ -0,0 +1,241 @@
+package node
+
+import (
+	"common/address"
+	"common/admin"
+	"common/inspect"
+	"fmt"
+	"net/rpc"
+	"os"
+	"sync"
+	"time"
+)
+
+type Node struct {
+	ID int
+	IP string
+	Bind string // Address the node listens on, IP if empty
+	Bootstrap string // Address of the bootstrap node
+	Last int // Probable owner of the token, NONE if the node is the root of the tree
+	Next int // Node to pass the token to after the critical section, NONE if there is no such node
+	HasToken bool // If the node holds the token
+	Requesting bool // If the node has asked for the token and has not released it yet
+	Clock int // Lamport clock
+	Request bool // If the node should request for the critical section
+	Network map[int]string // Map of the network
+	Finished []bool // If the node has finished
+	Hold chan bool // Closed to leave a critical section requested from the console, nil for the requests of the workload
+	Holding bool // If the node waits in the critical section until it is released from the console
+	Gate inspect.Gate // Holds back the messages from other nodes while the node is paused and keeps track of the messages in flight
+	Lock sync.Mutex
+}
+
+const (
+	NONE = -1
+	ACK = "ACK"
+	REQUEST = "REQUEST"
+	TOKEN = "TOKEN"
+)
+
+// Delays that slow the protocol down so that its output can be followed
+var (
+	MessageDelay = 1 * time.Second // Time a node waits before handling a message
+	CSDuration = 2 * time.Second // Time a node spends in the critical section
+)
+
+// Function to start the RPC server
+func (n *Node) StartRPCServer() {
+	rpc.Register(n)
+	rpc.RegisterName(admin.SERVICE, &Admin{n: n})
+
+	listener, err := address.Listen(n.listenAddress())
+	if err != nil {
+		fmt.Printf("[NODE-%d] could not start listening: %s\n", n.ID, err)
+		os.Exit(1)
+	}
+	defer listener.Close()
+
+	fmt.Printf("[NODE-%d] Node is running on %s\n", n.ID, n.IP)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			fmt.Printf("[NODE-%d] accept error: %s\n", n.ID, err)
+			continue
+		}
+		go rpc.ServeConn(conn)
+	}
+}
+
+func (n *Node) startRequestProcess(message Message, reply *Message) error {
+	if !n.Request {
+		return nil
+	}
+
+	n.Lock.Lock()
+	n.Requesting = true
+
+	if n.Last == NONE {
+		// The node is the root of the tree, so it either holds the token or is already waiting for it
+		enter := n.HasToken
+		n.Lock.Unlock()
+		if enter {
+			fmt.Printf("[NODE-%d] Already holding the token\n", n.ID)
+			n.useCriticalSection()
+		}
+		return nil
+	}
+
+	// Ask the probable owner for the token and become the new root of the tree
+	fmt.Printf("[NODE-%d] Sending a request to node %d\n", n.ID, n.Last)
+	n.send(n.Last, Message{Type: REQUEST, Requester: n.ID})
+	n.Last = NONE
+	n.Lock.Unlock()
+	return nil
+}
+
+// Dummy critical section function
+func (n *Node) CriticalSection() {
+	// Simulate entering the critical section
+	fmt.Printf("[NODE-%d] Entering the critical section\n", n.ID)
+	n.stayInCriticalSection()
+	fmt.Printf("[NODE-%d] Completed the critical section\n", n.ID)
+
+	// Notify Bootstrap node when the critical section is completed
+	n.Clock++
+	_, err := CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID})
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+	}
+}
+
+func (n *Node) NotifyFinished(message Message, reply *Message) error {
+	if message.ID < len(n.Finished) {
+		n.Finished[message.ID] = true
+	}
+	return nil
+}
+
+// Handle the different types of messages
+func (n *Node) ReceiveMessage(message Message, reply *Message) error {
+	n.Gate.Deliver(message.ID, message.Type, "", MessageDelay)
+	n.Lock.Lock()
+	n.Clock = max(n.Clock, message.Clock) + 1
+	n.Lock.Unlock()
+
+	switch message.Type {
+	case REQUEST:
+		n.Lock.Lock()
+		fmt.Printf("[NODE-%d] Received a request for node %d from node %d\n", n.ID, message.Requester, message.ID)
+
+		if n.Last == NONE {
+			if n.Requesting {
+				// Pass the token on once the node is done with it
+				n.Next = message.Requester
+				fmt.Printf("[NODE-%d] Node %d will receive the token next\n", n.ID, n.Next)
+			} else {
+				n.HasToken = false
+				fmt.Printf("[NODE-%d] Sending the idle token to node %d\n", n.ID, message.Requester)
+				n.send(message.Requester, Message{Type: TOKEN})
+			}
+		} else {
+			// Forward the request along the path to the root
+			fmt.Printf("[NODE-%d] Forwarding the request of node %d to node %d\n", n.ID, message.Requester, n.Last)
+			n.send(n.Last, Message{Type: REQUEST, Requester: message.Requester})
+		}
+
+		// Path reversal: the requester becomes the probable owner of the token
+		n.Last = message.Requester
+		n.Lock.Unlock()
+
+	case TOKEN:
+		fmt.Printf("[NODE-%d] Received the token from node %d\n", n.ID, message.ID)
+		n.Lock.Lock()
+		n.HasToken = true
+		n.Lock.Unlock()
+		n.useCriticalSection()
+	}
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Function to execute the critical section and pass the token to the next node afterwards
+func (n *Node) useCriticalSection() {
+	n.CriticalSection()
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	n.Requesting = false
+	n.Request = false
+	if n.Next != NONE {
+		n.HasToken = false
+		fmt.Printf("[NODE-%d] Sending the token to node %d\n", n.ID, n.Next)
+		n.send(n.Next, Message{Type: TOKEN})
+		n.Next = NONE
+	}
+}
+
+// Function to send a message to another node concurrently. Must be called with the lock held.
+func (n *Node) send(ID int, message Message) {
+	n.Clock++
+	message.ID = n.ID
+	message.IP = n.IP
+	message.Clock = n.Clock
+
+	go func() {
+		_, err := CallByRPC(n.Network[ID], "Node.ReceiveMessage", message)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while sending a %s message to node %d: %s\n", n.ID, message.Type, ID, err)
+		}
+	}()
+}
+
+// Function to add a new node to the network
+func (n *Node) addNode(message Message, reply *Message) error {
+	n.Lock.Lock()
+	n.Network[message.ID] = message.IP
+	n.Lock.Unlock()
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Function to decide whether the node requests for the critical section or not
+func (n *Node) setRequesting(message Message, reply *Message) error {
+	n.Request = n.ID < message.NumRequests
+	if n.Request {
+		fmt.Printf("[NODE-%d] Node will request for the critical section\n", n.ID)
+	} else {
+		fmt.Printf("[NODE-%d] Node will not request for the critical section\n", n.ID)
+	}
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Utility function to call RPC methods
+func CallByRPC(IP string, method string, message Message) (Message, error) {
+	if admin.IsAdmin(method) {
+		message.Secret = admin.Secret
+	}
+	conn, err := address.Dial(IP, 0)
+	if err != nil {
+		return Message{}, fmt.Errorf("error in dialing: %s", err)
+	}
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	var reply Message
+	err = client.Call(method, message, &reply)
+	if err != nil {
+		return Message{}, fmt.Errorf("error in calling %s: %s", method, err)
+	}
+	return reply, nil
+}
+
+// Function to get the address the node listens on
+func (n *Node) listenAddress() string {
+	if n.Bind != "" {
+		return n.Bind
+	}
+	return n.IP
+}
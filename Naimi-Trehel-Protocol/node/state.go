@@ -0,0 +1,37 @@
+package node
+
+import (
+	"common/inspect"
+	"maps"
+	"slices"
+)
+
+// What a node knows about the network and the critical section, returned by Node.GetState
+type State struct {
+	inspect.Snapshot // Part of the state shown by the console and the dashboard, the same in every protocol
+	Bootstrap string // Address of the bootstrap node
+	Request bool // If the node should request for the critical section
+	Requesting bool // If the node has asked for the token and has not released it yet
+	HasToken bool // If the node holds the token
+	Last int // Probable owner of the token, NONE if the node is the root of the tree
+	Next int // Node to pass the token to after the critical section, NONE if there is no such node
+	Network map[int]string // Addresses of the other nodes
+	Finished []bool // If each node has finished, as far as the node knows
+}
+
+// Function to report what the node knows about the network and the critical section. It does not change the
+// node, so tests, dashboards and the console can call it at any time.
+func (n *Node) GetState(message Message, reply *State) error {
+	*reply = n.state()
+	return nil
+}
+
+// Function to take a copy of the state of the node
+func (n *Node) state() State {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	state := State{Snapshot: inspect.Snapshot{ID: n.ID, IP: n.IP, Protocol: "naimi-trehel", Clock: n.Clock, Paused: n.Gate.Paused(), InFlight: n.Gate.Messages()}, Bootstrap: n.Bootstrap, Request: n.Request, Requesting: n.Requesting, HasToken: n.HasToken, Last: n.Last, Next: n.Next, Network: maps.Clone(n.Network), Finished: slices.Clone(n.Finished)}
+	summarize(&state)
+	return state
+}
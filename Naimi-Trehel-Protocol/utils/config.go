@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"common/admin"
+	"common/config"
+	"naimi_trehel/node"
+	"time"
+)
+
+// Function to read the configuration of the node, with the flags this protocol takes
+func LoadConfig(args []string) (config.Config, error) {
+	return config.Load(args, config.Default(node.MessageDelay, node.CSDuration))
+}
+
+// Function to apply the settings that do not depend on the ID of the node
+func Apply(cfg config.Config) error {
+	node.MessageDelay = time.Duration(cfg.MessageDelay)
+	node.CSDuration = time.Duration(cfg.CSDuration)
+	admin.Secret = cfg.AdminSecret
+	return cfg.OpenLog()
+}
+
+// Function to build the message that hands out the workload
+func WorkloadMessage(w config.Workload) node.Message {
+	return node.Message{NumRequests: w.Requests}
+}
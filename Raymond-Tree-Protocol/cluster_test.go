@@ -0,0 +1,17 @@
+package main
+
+import (
+	"common/cluster"
+	"raymond_tree/node"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	cluster.Main(m)
+}
+
+// Function to wait until the state of a node satisfies a condition, failing the test after a minute
+func waitForState(c *cluster.Cluster, i int, condition func(node.State) bool) node.State {
+	return cluster.WaitForState(c, i, node.Message{}, time.Minute, condition)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"common/cluster"
+	"raymond_tree/node"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Once every node has left the critical section, exactly one node reports that it holds the token with
+// Node.GetState and no node is waiting for it
+func TestGetStateAfterTheWorkload(t *testing.T) {
+	c := cluster.Start(t, 3, "-requests", "3")
+	c.WaitFor(0, "Time taken", time.Minute)
+
+	holders := 0
+	for i := 0; i < 3; i++ {
+		state := waitForState(c, i, func(state node.State) bool { return !state.Using && !state.Asked && len(state.Queue) == 0 })
+		if state.ID != i || len(state.Network) != 2 || state.Clock == 0 {
+			t.Fatalf("unexpected state of node %d: %+v", i, state)
+		}
+		if state.Holder == state.ID {
+			holders++
+		}
+	}
+	if holders != 1 {
+		t.Fatalf("%d nodes hold the token", holders)
+	}
+	state := waitForState(c, 0, func(state node.State) bool { return !slices.Contains(state.Finished, false) })
+	if len(state.Finished) != 3 {
+		t.Fatalf("node 0 does not know about every node: %+v", state)
+	}
+}
@@ -0,0 +1,35 @@
+package node
+
+import (
+	"common/inspect"
+	"fmt"
+)
+
+// Function to fill in the part of the state shown by the console and the dashboard
+func summarize(state *State) {
+	state.Phase = phase(state.Using, state.Request)
+	state.Token = state.Holder == state.ID
+	state.Status = statusLines(*state)
+	state.Queues = queueLines(*state)
+}
+
+// Function to describe the request of a node and the direction of the token
+func statusLines(state State) []string {
+	return []string{fmt.Sprintf("Requesting: %t, holder: node %d, asked for the token: %t", state.Request, state.Holder, state.Asked)}
+}
+
+// Function to describe the neighbours waiting for the token at a node
+func queueLines(state State) []string {
+	return []string{fmt.Sprintf("Queue: %v", state.Queue)}
+}
+
+// Function to get what the node is doing with the critical section
+func phase(inCS bool, requesting bool) inspect.Phase {
+	switch {
+	case inCS:
+		return inspect.IN_CS
+	case requesting:
+		return inspect.REQUESTING
+	}
+	return inspect.IDLE
+}
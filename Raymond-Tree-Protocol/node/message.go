@@ -0,0 +1,15 @@
+package node
+
+import (
+
+)
+
+type Message struct {
+	Type string // Request, Privilege
+	ID int
+	IP string
+	ReqTime int
+	Clock int
+	NumRequests int
+	Secret string // Shared secret of the admin service, only set on admin calls
+}
\ No newline at end of file
@@ -0,0 +1,249 @@
+package node
+
+import (
+	"common/address"
+	"common/admin"
+	"common/inspect"
+	"container/heap"
+	"fmt"
+	"net/rpc"
+	"os"
+	"sync"
+	"time"
+)
+
+type Node struct {
+	ID int
+	IP string
+	Bind string // Address the node listens on, IP if empty
+	Bootstrap string // Address of the bootstrap node
+	Holder int // ID of the neighbour in the direction of the token, own ID if the node holds the token
+	Using bool // If the node is executing the critical section
+	Asked bool // If the node has already sent a request to its holder
+	Queue *PriorityQueue // Neighbours (and the node itself) waiting for the token, in order of arrival
+	Clock int // Lamport clock
+	Request bool // If the node is requesting for the critical section
+	Network map[int]string // Map of the network
+	Finished []bool // If the node has finished
+	Hold chan bool // Closed to leave a critical section requested from the console, nil for the requests of the workload
+	Holding bool // If the node waits in the critical section until it is released from the console
+	Gate inspect.Gate // Holds back the messages from other nodes while the node is paused and keeps track of the messages in flight
+	Lock sync.Mutex
+}
+
+const (
+	ACK = "ACK"
+	REQUEST = "REQUEST"
+	PRIVILEGE = "PRIVILEGE"
+)
+
+// Delays that slow the protocol down so that its output can be followed
+var (
+	MessageDelay = 1 * time.Second // Time a node waits before handling a message
+	CSDuration = 2 * time.Second // Time a node spends in the critical section
+)
+
+// Parent of a node in the logical tree. Nodes join in the order of their IDs, so the tree is a
+// binary heap rooted at the bootstrap node and the parent of a node is always registered before it.
+func Parent(ID int) int {
+	if ID == 0 {
+		return 0
+	}
+	return (ID - 1) / 2
+}
+
+// Function to start the RPC server
+func (n *Node) StartRPCServer() {
+	rpc.Register(n)
+	rpc.RegisterName(admin.SERVICE, &Admin{n: n})
+
+	listener, err := address.Listen(n.listenAddress())
+	if err != nil {
+		fmt.Printf("[NODE-%d] could not start listening: %s\n", n.ID, err)
+		os.Exit(1)
+	}
+	defer listener.Close()
+
+	fmt.Printf("[NODE-%d] Node is running on %s\n", n.ID, n.IP)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			fmt.Printf("[NODE-%d] accept error: %s\n", n.ID, err)
+			continue
+		}
+		go rpc.ServeConn(conn)
+	}
+}
+
+func (n *Node) startRequestProcess(message Message, reply *Message) error {
+	if n.Request {
+		n.Lock.Lock()
+		defer n.Lock.Unlock()
+
+		n.Clock++
+		heap.Push(n.Queue, Item{ID: n.ID, TimeStamp: n.Clock})
+		fmt.Printf("[NODE-%d] Added own request to the queue. New Queue: %v\n", n.ID, n.Queue)
+
+		n.assignPrivilege()
+		n.makeRequest()
+	}
+	return nil
+}
+
+// Dummy critical section function
+func (n *Node) CriticalSection() {
+	// Simulate entering the critical section
+	fmt.Printf("[NODE-%d] Entering the critical section\n", n.ID)
+	n.stayInCriticalSection()
+	fmt.Printf("[NODE-%d] Completed the critical section\n", n.ID)
+
+	// Notify Bootstrap node when the critical section is completed
+	n.Clock++
+	_, err := CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID})
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+	}
+}
+
+func (n *Node) NotifyFinished(message Message, reply *Message) error {
+	if message.ID < len(n.Finished) {
+		n.Finished[message.ID] = true
+	}
+	return nil
+}
+
+// Handle the different types of messages
+func (n *Node) ReceiveMessage(message Message, reply *Message) error {
+	n.Gate.Deliver(message.ID, message.Type, "", MessageDelay)
+	n.Lock.Lock()
+	n.Clock = max(n.Clock, message.Clock) + 1
+	n.Lock.Unlock()
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	switch message.Type {
+	case REQUEST:
+		heap.Push(n.Queue, Item{ID: message.ID, TimeStamp: n.Clock})
+		fmt.Printf("[NODE-%d] Received a request from node %d. New Queue: %v\n", n.ID, message.ID, n.Queue)
+
+	case PRIVILEGE:
+		fmt.Printf("[NODE-%d] Received the token from node %d\n", n.ID, message.ID)
+		n.Holder = n.ID
+	}
+
+	n.assignPrivilege()
+	n.makeRequest()
+
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Function to hand the token to the head of the queue if the node holds an unused token.
+// Must be called with the lock held.
+func (n *Node) assignPrivilege() {
+	if n.Holder != n.ID || n.Using || n.Queue.Len() == 0 {
+		return
+	}
+
+	head := heap.Pop(n.Queue).(Item)
+	n.Asked = false
+
+	if head.ID == n.ID {
+		n.Using = true
+		go n.useCriticalSection()
+		return
+	}
+
+	n.Holder = head.ID
+	fmt.Printf("[NODE-%d] Passing the token to node %d\n", n.ID, head.ID)
+	n.send(head.ID, PRIVILEGE)
+}
+
+// Function to ask the holder for the token on behalf of the queue.
+// Must be called with the lock held.
+func (n *Node) makeRequest() {
+	if n.Holder == n.ID || n.Queue.Len() == 0 || n.Asked {
+		return
+	}
+
+	fmt.Printf("[NODE-%d] Sending a request to node %d\n", n.ID, n.Holder)
+	n.send(n.Holder, REQUEST)
+	n.Asked = true
+}
+
+// Function to execute the critical section and pass the token on afterwards
+func (n *Node) useCriticalSection() {
+	n.CriticalSection()
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	n.Using = false
+	n.Request = false
+	n.assignPrivilege()
+	n.makeRequest()
+}
+
+// Function to send a message to a neighbour concurrently. Must be called with the lock held.
+func (n *Node) send(ID int, msgType string) {
+	n.Clock++
+	message := Message{Type: msgType, ID: n.ID, IP: n.IP, Clock: n.Clock}
+
+	go func() {
+		_, err := CallByRPC(n.Network[ID], "Node.ReceiveMessage", message)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while sending a %s message to node %d: %s\n", n.ID, msgType, ID, err)
+		}
+	}()
+}
+
+// Function to add a new node to the network
+func (n *Node) addNode(message Message, reply *Message) error {
+	n.Lock.Lock()
+	n.Network[message.ID] = message.IP
+	n.Lock.Unlock()
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Function to decide whether the node requests for the critical section or not
+func (n *Node) setRequesting(message Message, reply *Message) error {
+	n.Request = n.ID < message.NumRequests
+	if n.Request {
+		fmt.Printf("[NODE-%d] Node will request for the critical section\n", n.ID)
+	} else {
+		fmt.Printf("[NODE-%d] Node will not request for the critical section\n", n.ID)
+	}
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Utility function to call RPC methods
+func CallByRPC(IP string, method string, message Message) (Message, error) {
+	if admin.IsAdmin(method) {
+		message.Secret = admin.Secret
+	}
+	conn, err := address.Dial(IP, 0)
+	if err != nil {
+		return Message{}, fmt.Errorf("error in dialing: %s", err)
+	}
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	var reply Message
+	err = client.Call(method, message, &reply)
+	if err != nil {
+		return Message{}, fmt.Errorf("error in calling %s: %s", method, err)
+	}
+	return reply, nil
+}
+
+// Function to get the address the node listens on
+func (n *Node) listenAddress() string {
+	if n.Bind != "" {
+		return n.Bind
+	}
+	return n.IP
+}
@@ -0,0 +1,39 @@
+package node
+
+import (
+	"common/inspect"
+	"maps"
+	"slices"
+	"sort"
+)
+
+// What a node knows about the network and the critical section, returned by Node.GetState
+type State struct {
+	inspect.Snapshot // Part of the state shown by the console and the dashboard, the same in every protocol
+	Bootstrap string // Address of the bootstrap node
+	Request bool // If the node is requesting for the critical section
+	Holder int // ID of the neighbour in the direction of the token, own ID if the node holds the token
+	Using bool // If the node is executing the critical section
+	Asked bool // If the node has already sent a request to its holder
+	Queue []Item // Neighbours (and the node itself) waiting for the token, in order of priority
+	Network map[int]string // Addresses of the other nodes
+	Finished []bool // If each node has finished, as far as the node knows
+}
+
+// Function to report what the node knows about the network and the critical section. It does not change the
+// node, so tests, dashboards and the console can call it at any time.
+func (n *Node) GetState(message Message, reply *State) error {
+	*reply = n.state()
+	return nil
+}
+
+// Function to take a copy of the state of the node
+func (n *Node) state() State {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	state := State{Snapshot: inspect.Snapshot{ID: n.ID, IP: n.IP, Protocol: "raymond", Clock: n.Clock, Paused: n.Gate.Paused(), InFlight: n.Gate.Messages()}, Bootstrap: n.Bootstrap, Request: n.Request, Holder: n.Holder, Using: n.Using, Asked: n.Asked, Queue: append([]Item{}, *n.Queue...), Network: maps.Clone(n.Network), Finished: slices.Clone(n.Finished)}
+	sort.Slice(state.Queue, func(i, j int) bool { return PriorityQueue(state.Queue).Less(i, j) })
+	summarize(&state)
+	return state
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"common/cluster"
+	"slices"
+	"suzuki_kasami/node"
+	"testing"
+	"time"
+)
+
+// Once every node has left the critical section, exactly one node reports the token with Node.GetState and no
+// node has seen more than one request from any node
+func TestGetStateAfterTheWorkload(t *testing.T) {
+	c := cluster.Start(t, 3, "-requests", "3")
+	c.WaitFor(0, "Time taken", time.Minute)
+
+	holders := 0
+	for i := 0; i < 3; i++ {
+		state := waitForState(c, i, func(state node.State) bool { return !state.Request && !state.InCS })
+		if state.ID != i || len(state.Network) != 2 || state.Clock == 0 {
+			t.Fatalf("unexpected state of node %d: %+v", i, state)
+		}
+		for ID, number := range state.RN {
+			if number > 1 {
+				t.Fatalf("node %d has seen %d requests from node %d instead of at most 1: %+v", i, number, ID, state)
+			}
+		}
+		if state.HasToken {
+			holders++
+			if len(state.TokenQueue) != 0 {
+				t.Fatalf("nodes are still waiting for the token: %+v", state)
+			}
+		}
+	}
+	if holders != 1 {
+		t.Fatalf("%d nodes hold the token", holders)
+	}
+	state := waitForState(c, 0, func(state node.State) bool { return !slices.Contains(state.Finished, false) })
+	if len(state.Finished) != 3 {
+		t.Fatalf("node 0 does not know about every node: %+v", state)
+	}
+}
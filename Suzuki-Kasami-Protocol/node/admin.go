@@ -0,0 +1,68 @@
+package node
+
+import (
+	"common/admin"
+)
+
+// Administrative calls of a node. They are registered apart from the protocol messages, so that only the
+// nodes that know the admin secret can reconfigure the network.
+type Admin struct {
+	n *Node
+}
+
+// Function to add a new node to the network
+func (a *Admin) AddNode(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "AddNode", message.Secret)
+	if err != nil {
+		return err
+	}
+	return a.n.addNode(message, reply)
+}
+
+// Function to set the workload of the node
+func (a *Admin) SetRequesting(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "SetRequesting", message.Secret)
+	if err != nil {
+		return err
+	}
+	return a.n.setRequesting(message, reply)
+}
+
+// Function to start the requests of the node
+func (a *Admin) StartRequestProcess(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "StartRequestProcess", message.Secret)
+	if err != nil {
+		return err
+	}
+	return a.n.startRequestProcess(message, reply)
+}
+
+// Function to hold back the messages from other nodes
+func (a *Admin) Pause(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "Pause", message.Secret)
+	if err != nil {
+		return err
+	}
+	a.n.Gate.PauseNode(a.n.ID)
+	return nil
+}
+
+// Function to handle the messages that were held back and the ones that follow
+func (a *Admin) Resume(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "Resume", message.Secret)
+	if err != nil {
+		return err
+	}
+	a.n.Gate.ResumeNode(a.n.ID)
+	return nil
+}
+
+// Function to handle the oldest message that is held back while the node stays paused
+func (a *Admin) Step(message Message, reply *Message) error {
+	err := admin.CheckSecret(a.n.ID, "Step", message.Secret)
+	if err != nil {
+		return err
+	}
+	a.n.Gate.StepNode(a.n.ID)
+	return nil
+}
@@ -0,0 +1,41 @@
+package node
+
+import (
+	"common/inspect"
+	"fmt"
+)
+
+// Function to fill in the part of the state shown by the console and the dashboard
+func summarize(state *State) {
+	state.Phase = phase(state.InCS, state.Request)
+	state.Token = state.HasToken
+	state.Status = statusLines(*state)
+	state.Queues = queueLines(*state)
+}
+
+// Function to describe the request of a node
+func statusLines(state State) []string {
+	return []string{fmt.Sprintf("Requesting: %t, holding the token: %t, in the critical section: %t", state.Request, state.HasToken, state.InCS)}
+}
+
+// Function to describe the queue of the token and the outstanding requests known to a node
+func queueLines(state State) []string {
+	lines := []string{}
+	if state.HasToken {
+		lines = append(lines, fmt.Sprintf("Token queue: %v, executed requests: %v", state.TokenQueue, state.LN))
+	} else {
+		lines = append(lines, "The token queue travels with the token, which is at another node")
+	}
+	return append(lines, fmt.Sprintf("Latest requests: %v", state.RN))
+}
+
+// Function to get what the node is doing with the critical section
+func phase(inCS bool, requesting bool) inspect.Phase {
+	switch {
+	case inCS:
+		return inspect.IN_CS
+	case requesting:
+		return inspect.REQUESTING
+	}
+	return inspect.IDLE
+}
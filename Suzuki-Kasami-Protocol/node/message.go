@@ -0,0 +1,19 @@
+package node
+
+type Message struct {
+	Type string // Request, Token
+	ID int
+	IP string
+	ReqTime int
+	Clock int
+	NumRequests int
+	SeqNum int // Request number of the requesting node
+	Token *Token // Token passed along with a TOKEN message
+	Secret string // Shared secret of the admin service, only set on admin calls
+}
+
+// Token shared by all the nodes in the network
+type Token struct {
+	LN map[int]int // Request number of the most recently executed request of each node
+	Queue []int // IDs of the nodes waiting for the token
+}
@@ -0,0 +1,275 @@
+package node
+
+import (
+	"common/address"
+	"common/admin"
+	"common/inspect"
+	"fmt"
+	"net/rpc"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+type Node struct {
+	ID int
+	IP string
+	Bind string // Address the node listens on, IP if empty
+	Bootstrap string // Address of the bootstrap node
+	RN map[int]int // Highest request number received from each node
+	Token *Token // Token held by the node, nil if the node does not hold it
+	InCS bool // If the node is executing the critical section
+	Clock int // Lamport clock
+	Request bool // If the node is requesting for the critical section
+	Network map[int]string // Map of the network
+	Finished []bool // If the node has finished
+	Hold chan bool // Closed to leave a critical section requested from the console, nil for the requests of the workload
+	Holding bool // If the node waits in the critical section until it is released from the console
+	Gate inspect.Gate // Holds back the messages from other nodes while the node is paused and keeps track of the messages in flight
+	Lock sync.Mutex
+}
+
+const (
+	ACK = "ACK"
+	REQUEST = "REQUEST"
+	TOKEN = "TOKEN"
+)
+
+// Delays that slow the protocol down so that its output can be followed
+var (
+	MessageDelay = 1 * time.Second // Time a node waits before handling a message
+	CSDuration = 2 * time.Second // Time a node spends in the critical section
+)
+
+// Function to start the RPC server
+func (n *Node) StartRPCServer() {
+	rpc.Register(n)
+	rpc.RegisterName(admin.SERVICE, &Admin{n: n})
+
+	listener, err := address.Listen(n.listenAddress())
+	if err != nil {
+		fmt.Printf("[NODE-%d] could not start listening: %s\n", n.ID, err)
+		os.Exit(1)
+	}
+	defer listener.Close()
+
+	fmt.Printf("[NODE-%d] Node is running on %s\n", n.ID, n.IP)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			fmt.Printf("[NODE-%d] accept error: %s\n", n.ID, err)
+			continue
+		}
+		go rpc.ServeConn(conn)
+	}
+}
+
+func (n *Node) startRequestProcess(message Message, reply *Message) error {
+	if !n.Request {
+		return nil
+	}
+
+	n.Lock.Lock()
+	n.Clock++
+	n.RN[n.ID]++
+	seqNum := n.RN[n.ID]
+
+	// The token is already at this node, so there is no need to ask for it
+	if n.Token != nil && !n.InCS {
+		n.InCS = true
+		n.Lock.Unlock()
+		fmt.Printf("[NODE-%d] Already holding the token\n", n.ID)
+		n.CriticalSection()
+		n.ReleaseCriticalSection()
+		return nil
+	}
+	n.Lock.Unlock()
+
+	// Broadcast the request to all the nodes in the network
+	for i := range n.Network {
+		go func(i int) {
+			n.Lock.Lock()
+			n.Clock++
+			msg := Message{Type: REQUEST, ID: n.ID, SeqNum: seqNum, Clock: n.Clock}
+			n.Lock.Unlock()
+
+			fmt.Printf("[NODE-%d] Sending a request with sequence number %d to node %d\n", n.ID, seqNum, i)
+			_, err := CallByRPC(n.Network[i], "Node.ReceiveMessage", msg)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while sending a request to node %d: %s\n", n.ID, i, err)
+			}
+		}(i)
+	}
+	return nil
+}
+
+// Dummy critical section function
+func (n *Node) CriticalSection() {
+	// Simulate entering the critical section
+	fmt.Printf("[NODE-%d] Entering the critical section\n", n.ID)
+	n.stayInCriticalSection()
+	fmt.Printf("[NODE-%d] Completed the critical section\n", n.ID)
+
+	// Notify Bootstrap node when the critical section is completed
+	n.Clock++
+	_, err := CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID})
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+	}
+}
+
+func (n *Node) NotifyFinished(message Message, reply *Message) error {
+	if message.ID < len(n.Finished) {
+		n.Finished[message.ID] = true
+	}
+	return nil
+}
+
+// Handle the different types of messages
+func (n *Node) ReceiveMessage(message Message, reply *Message) error {
+	n.Gate.Deliver(message.ID, message.Type, "", MessageDelay)
+	n.Lock.Lock()
+	n.Clock = max(n.Clock, message.Clock) + 1
+	n.Lock.Unlock()
+	switch message.Type {
+	case REQUEST:
+		fmt.Printf("[NODE-%d] Received a request from node %d with sequence number %d\n", n.ID, message.ID, message.SeqNum)
+
+		n.Lock.Lock()
+		n.RN[message.ID] = max(n.RN[message.ID], message.SeqNum)
+
+		// Hand over an idle token if the request is an outstanding one
+		if n.Token != nil && !n.InCS && n.RN[message.ID] == n.Token.LN[message.ID] + 1 {
+			token := n.Token
+			n.Token = nil
+			n.Lock.Unlock()
+			n.sendToken(message.ID, token)
+			break
+		}
+		n.Lock.Unlock()
+
+	case TOKEN:
+		fmt.Printf("[NODE-%d] Received the token from node %d. Token queue: %v\n", n.ID, message.ID, message.Token.Queue)
+
+		n.Lock.Lock()
+		n.Token = message.Token
+		n.InCS = true
+		n.Lock.Unlock()
+
+		n.CriticalSection()
+		n.ReleaseCriticalSection()
+	}
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Function to update the token after the critical section and pass it on to the next waiting node
+func (n *Node) ReleaseCriticalSection() {
+	n.Lock.Lock()
+	n.InCS = false
+	n.Request = false
+	n.Token.LN[n.ID] = n.RN[n.ID]
+
+	// Append every node with an outstanding request that is not already waiting in the token queue
+	ids := make([]int, 0, len(n.Network))
+	for i := range n.Network {
+		ids = append(ids, i)
+	}
+	sort.Ints(ids)
+	for _, i := range ids {
+		if n.RN[i] == n.Token.LN[i] + 1 && !contains(n.Token.Queue, i) {
+			n.Token.Queue = append(n.Token.Queue, i)
+		}
+	}
+
+	if len(n.Token.Queue) == 0 {
+		fmt.Printf("[NODE-%d] No outstanding requests. Keeping the token\n", n.ID)
+		n.Lock.Unlock()
+		return
+	}
+
+	next := n.Token.Queue[0]
+	token := n.Token
+	token.Queue = token.Queue[1:]
+	n.Token = nil
+	n.Lock.Unlock()
+
+	n.sendToken(next, token)
+}
+
+// Function to send the token to another node concurrently
+func (n *Node) sendToken(ID int, token *Token) {
+	n.Lock.Lock()
+	n.Clock++
+	msg := Message{Type: TOKEN, ID: n.ID, Clock: n.Clock, Token: token}
+	n.Lock.Unlock()
+
+	fmt.Printf("[NODE-%d] Sending the token to node %d\n", n.ID, ID)
+	go func() {
+		_, err := CallByRPC(n.Network[ID], "Node.ReceiveMessage", msg)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while sending the token to node %d: %s\n", n.ID, ID, err)
+		}
+	}()
+}
+
+// Function to add a new node to the network
+func (n *Node) addNode(message Message, reply *Message) error {
+	n.Lock.Lock()
+	n.Network[message.ID] = message.IP
+	n.Lock.Unlock()
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Function to decide whether the node requests for the critical section or not
+func (n *Node) setRequesting(message Message, reply *Message) error {
+	n.Request = n.ID < message.NumRequests
+	if n.Request {
+		fmt.Printf("[NODE-%d] Node will request for the critical section\n", n.ID)
+	} else {
+		fmt.Printf("[NODE-%d] Node will not request for the critical section\n", n.ID)
+	}
+	*reply = Message{Type: ACK}
+	return nil
+}
+
+// Utility function to call RPC methods
+func CallByRPC(IP string, method string, message Message) (Message, error) {
+	if admin.IsAdmin(method) {
+		message.Secret = admin.Secret
+	}
+	conn, err := address.Dial(IP, 0)
+	if err != nil {
+		return Message{}, fmt.Errorf("error in dialing: %s", err)
+	}
+	client := rpc.NewClient(conn)
+	defer client.Close()
+
+	var reply Message
+	err = client.Call(method, message, &reply)
+	if err != nil {
+		return Message{}, fmt.Errorf("error in calling %s: %s", method, err)
+	}
+	return reply, nil
+}
+
+// Function to check if an ID is present in a slice
+func contains(slice []int, ID int) bool {
+	for _, v := range slice {
+		if v == ID {
+			return true
+		}
+	}
+	return false
+}
+
+// Function to get the address the node listens on
+func (n *Node) listenAddress() string {
+	if n.Bind != "" {
+		return n.Bind
+	}
+	return n.IP
+}
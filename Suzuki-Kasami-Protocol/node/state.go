@@ -0,0 +1,42 @@
+package node
+
+import (
+	"common/inspect"
+	"maps"
+	"slices"
+)
+
+// What a node knows about the network and the critical section, returned by Node.GetState
+type State struct {
+	inspect.Snapshot // Part of the state shown by the console and the dashboard, the same in every protocol
+	Bootstrap string // Address of the bootstrap node
+	Request bool // If the node is requesting for the critical section
+	InCS bool // If the node is executing the critical section
+	HasToken bool // If the node holds the token
+	RN map[int]int // Highest request number received from each node
+	LN map[int]int // Request number of the most recently executed request of each node, only known to the holder of the token
+	TokenQueue []int // IDs of the nodes waiting for the token, only known to the holder of the token
+	Network map[int]string // Addresses of the other nodes
+	Finished []bool // If each node has finished, as far as the node knows
+}
+
+// Function to report what the node knows about the network and the critical section. It does not change the
+// node, so tests, dashboards and the console can call it at any time.
+func (n *Node) GetState(message Message, reply *State) error {
+	*reply = n.state()
+	return nil
+}
+
+// Function to take a copy of the state of the node
+func (n *Node) state() State {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+
+	state := State{Snapshot: inspect.Snapshot{ID: n.ID, IP: n.IP, Protocol: "suzuki-kasami", Clock: n.Clock, Paused: n.Gate.Paused(), InFlight: n.Gate.Messages()}, Bootstrap: n.Bootstrap, Request: n.Request, InCS: n.InCS, HasToken: n.Token != nil, RN: maps.Clone(n.RN), Network: maps.Clone(n.Network), Finished: slices.Clone(n.Finished)}
+	if n.Token != nil {
+		state.LN = maps.Clone(n.Token.LN)
+		state.TokenQueue = slices.Clone(n.Token.Queue)
+	}
+	summarize(&state)
+	return state
+}
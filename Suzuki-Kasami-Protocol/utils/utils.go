@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"fmt"
+	"suzuki_kasami/node"
+	"time"
+)
+
+// Calculate the time taken from the first node to request to the last node to exist the critical section
+func CalculateTimeTaken(n *node.Node, numRequests int) {
+	startTime := time.Now()
+
+	if n.ID == 0 {
+		n.Finished = make([]bool, numRequests)
+		for {
+			if all(n.Finished) {
+				fmt.Printf("Time taken for all nodes to exit the critical section: %v\n", time.Since(startTime))
+				break
+			}
+		}
+	}
+}
+
+func all(arr []bool) bool {
+	for _, v := range arr {
+		if !v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,3 @@
+module trace_checker
+
+go 1.23.2
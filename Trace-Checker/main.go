@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Event in the trace of a node, as written by the nodes
+type TraceEvent struct {
+	Time int64 `json:"time"` // Unix time in nanoseconds
+	Node int `json:"node"`
+	Resource string `json:"resource"`
+	Event string `json:"event"` // WAIT, ACQUIRED, RELEASED or CANCELLED. The ARRIVED and HANDLED events of messages are skipped
+}
+
+const (
+	WAIT = "WAIT"
+	ACQUIRED = "ACQUIRED"
+	RELEASED = "RELEASED"
+	CANCELLED = "CANCELLED"
+)
+
+// Replays the traces recorded by the nodes and checks that no set of nodes ever waited for each other in a cycle
+func main() {
+	dir := "traces"
+	if len(os.Args) > 1 {
+		dir = os.Args[1]
+	}
+
+	events, err := readTraces(dir)
+	if err != nil {
+		fmt.Println("Error occurred while reading the traces:", err)
+		os.Exit(1)
+	}
+	if len(events) == 0 {
+		fmt.Printf("No trace events found in %s\n", dir)
+		os.Exit(1)
+	}
+
+	holders := make(map[string]map[int]bool) // Nodes holding each resource
+	waiting := make(map[int]map[string]bool) // Resources each node is waiting for
+	cycles := 0
+
+	for _, event := range events {
+		switch event.Event {
+		case WAIT:
+			if waiting[event.Node] == nil {
+				waiting[event.Node] = make(map[string]bool)
+			}
+			waiting[event.Node][event.Resource] = true
+
+		case ACQUIRED:
+			delete(waiting[event.Node], event.Resource)
+			if holders[event.Resource] == nil {
+				holders[event.Resource] = make(map[int]bool)
+			}
+			holders[event.Resource][event.Node] = true
+
+		case RELEASED:
+			delete(holders[event.Resource], event.Node)
+
+		case CANCELLED:
+			// The node gave up waiting
+			delete(waiting[event.Node], event.Resource)
+		}
+
+		if event.Event != WAIT {
+			continue
+		}
+
+		// A new wait is the only event that can close a cycle in the wait-for graph
+		cycle := findCycle(waitForGraph(holders, waiting))
+		if cycle != nil {
+			cycles++
+			fmt.Printf("CIRCULAR WAIT after node %d started waiting for %s: %v\n", event.Node, event.Resource, cycle)
+		}
+	}
+
+	for ID, resources := range waiting {
+		for resource := range resources {
+			fmt.Printf("Node %d was still waiting for %s at the end of the trace\n", ID, resource)
+		}
+	}
+
+	if cycles > 0 {
+		fmt.Printf("Trace check failed: found %d circular waits in %d events\n", cycles, len(events))
+		os.Exit(1)
+	}
+	fmt.Printf("Trace check passed: no circular waits in %d events\n", len(events))
+}
+
+// Function to read and merge the traces of all the nodes in a directory in the order of time
+func readTraces(dir string) ([]TraceEvent, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	events := []TraceEvent{}
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var event TraceEvent
+			err := json.Unmarshal(scanner.Bytes(), &event)
+			if err != nil {
+				file.Close()
+				return nil, fmt.Errorf("%s: %s", name, err)
+			}
+			events = append(events, event)
+		}
+		file.Close()
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time < events[j].Time
+	})
+	return events, nil
+}
+
+// Function to build the wait-for graph, with an edge from every waiting node to every other node holding the resource it waits for
+func waitForGraph(holders map[string]map[int]bool, waiting map[int]map[string]bool) map[int][]int {
+	graph := make(map[int][]int)
+	for ID, resources := range waiting {
+		for resource := range resources {
+			for holder := range holders[resource] {
+				if holder != ID {
+					graph[ID] = append(graph[ID], holder)
+				}
+			}
+		}
+	}
+	return graph
+}
+
+// Function to find a cycle in the wait-for graph using a depth first search. Returns nil if there is none.
+func findCycle(graph map[int][]int) []int {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[int]int)
+	path := []int{}
+
+	var visit func(ID int) []int
+	visit = func(ID int) []int {
+		state[ID] = visiting
+		path = append(path, ID)
+		for _, next := range graph[ID] {
+			if state[next] == visiting {
+				// The cycle is the part of the path starting at the node that was visited again
+				for i := range path {
+					if path[i] == next {
+						return append(append([]int{}, path[i:]...), next)
+					}
+				}
+			}
+			if state[next] == unvisited {
+				cycle := visit(next)
+				if cycle != nil {
+					return cycle
+				}
+			}
+		}
+		path = path[:len(path) - 1]
+		state[ID] = done
+		return nil
+	}
+
+	// Visit the nodes in the order of their IDs so that the output is deterministic
+	IDs := make([]int, 0, len(graph))
+	for ID := range graph {
+		IDs = append(IDs, ID)
+	}
+	sort.Ints(IDs)
+	for _, ID := range IDs {
+		if state[ID] == unvisited {
+			cycle := visit(ID)
+			if cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Page that replays the trace, served as is so that the visualizer works without any external service
+//
+//go:embed index.html
+var page []byte
+
+// Event in the trace of a node, as written by the nodes
+type TraceEvent struct {
+	Time int64 `json:"time"` // Unix time in nanoseconds
+	Node int `json:"node"`
+	Resource string `json:"resource"`
+	Event string `json:"event"` // WAIT, ACQUIRED, RELEASED, CANCELLED, ARRIVED or HANDLED
+	Message *TraceMessage `json:"message,omitempty"` // Only set for ARRIVED and HANDLED
+}
+
+// Message from another node in the trace of the node that received it
+type TraceMessage struct {
+	From int `json:"from"`
+	Type string `json:"type"`
+	Clock int `json:"clock"`
+	ReqTime int `json:"reqTime"`
+}
+
+// Recorded run sent to the page
+type Run struct {
+	Dir string `json:"dir"`
+	Nodes []int `json:"nodes"`
+	Events []TraceEvent `json:"events"`
+}
+
+// Serves a web page that animates the traces recorded by the nodes, so that a run can be stepped through
+// forwards and backwards
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8090", "address to serve the visualizer on")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-addr host:port] [trace dir]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	dir := "traces"
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	// The traces are read again on every load of the page, so that a new run only needs a reload
+	_, err := readRun(dir)
+	if err != nil {
+		fmt.Println("Error occurred while reading the traces:", err)
+		os.Exit(1)
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	})
+	http.HandleFunc("/trace", func(w http.ResponseWriter, r *http.Request) {
+		run, err := readRun(dir)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(run)
+	})
+
+	fmt.Printf("Replaying the traces in %s on http://%s\n", dir, *addr)
+	err = http.ListenAndServe(*addr, nil)
+	if err != nil {
+		fmt.Println("Error occurred while serving the visualizer:", err)
+		os.Exit(1)
+	}
+}
+
+// Function to read and merge the traces of all the nodes in a directory in the order of time
+func readRun(dir string) (Run, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return Run{}, err
+	}
+	if len(files) == 0 {
+		return Run{}, fmt.Errorf("no traces found in %s, start the nodes with -trace-dir %s", dir, dir)
+	}
+
+	run := Run{Dir: dir, Nodes: []int{}, Events: []TraceEvent{}}
+	nodes := make(map[int]bool)
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return Run{}, err
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var event TraceEvent
+			err := json.Unmarshal(scanner.Bytes(), &event)
+			if err != nil {
+				file.Close()
+				return Run{}, fmt.Errorf("%s: %s", name, err)
+			}
+			nodes[event.Node] = true
+			if event.Message != nil {
+				nodes[event.Message.From] = true
+			}
+			run.Events = append(run.Events, event)
+		}
+		file.Close()
+	}
+
+	for ID := range nodes {
+		run.Nodes = append(run.Nodes, ID)
+	}
+	sort.Ints(run.Nodes)
+	sort.SliceStable(run.Events, func(i, j int) bool {
+		return run.Events[i].Time < run.Events[j].Time
+	})
+	return run, nil
+}
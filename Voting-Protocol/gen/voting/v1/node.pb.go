@@ -0,0 +1,795 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: voting/v1/node.proto
+
+// Version 1 of the messages exchanged by the nodes of the Voting Protocol.
+// Fields may be added with new numbers, but existing numbers must never be changed or reused.
+
+package votingv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type of a message between two nodes
+type MessageType int32
+
+const (
+	MessageType_MESSAGE_TYPE_UNSPECIFIED  MessageType = 0
+	MessageType_MESSAGE_TYPE_REQUEST      MessageType = 1
+	MessageType_MESSAGE_TYPE_VOTE         MessageType = 2
+	MessageType_MESSAGE_TYPE_RELEASE      MessageType = 3
+	MessageType_MESSAGE_TYPE_RESCIND_VOTE MessageType = 4
+	MessageType_MESSAGE_TYPE_ACK          MessageType = 5
+	MessageType_MESSAGE_TYPE_DENY         MessageType = 6
+	MessageType_MESSAGE_TYPE_CANCEL       MessageType = 7
+	MessageType_MESSAGE_TYPE_RENEW        MessageType = 8
+	MessageType_MESSAGE_TYPE_FENCE        MessageType = 9
+	MessageType_MESSAGE_TYPE_PROOF        MessageType = 10
+)
+
+// Enum value maps for MessageType.
+var (
+	MessageType_name = map[int32]string{
+		0:  "MESSAGE_TYPE_UNSPECIFIED",
+		1:  "MESSAGE_TYPE_REQUEST",
+		2:  "MESSAGE_TYPE_VOTE",
+		3:  "MESSAGE_TYPE_RELEASE",
+		4:  "MESSAGE_TYPE_RESCIND_VOTE",
+		5:  "MESSAGE_TYPE_ACK",
+		6:  "MESSAGE_TYPE_DENY",
+		7:  "MESSAGE_TYPE_CANCEL",
+		8:  "MESSAGE_TYPE_RENEW",
+		9:  "MESSAGE_TYPE_FENCE",
+		10: "MESSAGE_TYPE_PROOF",
+	}
+	MessageType_value = map[string]int32{
+		"MESSAGE_TYPE_UNSPECIFIED":  0,
+		"MESSAGE_TYPE_REQUEST":      1,
+		"MESSAGE_TYPE_VOTE":         2,
+		"MESSAGE_TYPE_RELEASE":      3,
+		"MESSAGE_TYPE_RESCIND_VOTE": 4,
+		"MESSAGE_TYPE_ACK":          5,
+		"MESSAGE_TYPE_DENY":         6,
+		"MESSAGE_TYPE_CANCEL":       7,
+		"MESSAGE_TYPE_RENEW":        8,
+		"MESSAGE_TYPE_FENCE":        9,
+		"MESSAGE_TYPE_PROOF":        10,
+	}
+)
+
+func (x MessageType) Enum() *MessageType {
+	p := new(MessageType)
+	*p = x
+	return p
+}
+
+func (x MessageType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MessageType) Descriptor() protoreflect.EnumDescriptor {
+	return file_voting_v1_node_proto_enumTypes[0].Descriptor()
+}
+
+func (MessageType) Type() protoreflect.EnumType {
+	return &file_voting_v1_node_proto_enumTypes[0]
+}
+
+func (x MessageType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MessageType.Descriptor instead.
+func (MessageType) EnumDescriptor() ([]byte, []int) {
+	return file_voting_v1_node_proto_rawDescGZIP(), []int{0}
+}
+
+// Access requested for a resource
+type Mode int32
+
+const (
+	Mode_MODE_UNSPECIFIED Mode = 0
+	Mode_MODE_READ        Mode = 1
+	Mode_MODE_WRITE       Mode = 2
+)
+
+// Enum value maps for Mode.
+var (
+	Mode_name = map[int32]string{
+		0: "MODE_UNSPECIFIED",
+		1: "MODE_READ",
+		2: "MODE_WRITE",
+	}
+	Mode_value = map[string]int32{
+		"MODE_UNSPECIFIED": 0,
+		"MODE_READ":        1,
+		"MODE_WRITE":       2,
+	}
+)
+
+func (x Mode) Enum() *Mode {
+	p := new(Mode)
+	*p = x
+	return p
+}
+
+func (x Mode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Mode) Descriptor() protoreflect.EnumDescriptor {
+	return file_voting_v1_node_proto_enumTypes[1].Descriptor()
+}
+
+func (Mode) Type() protoreflect.EnumType {
+	return &file_voting_v1_node_proto_enumTypes[1]
+}
+
+func (x Mode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Mode.Descriptor instead.
+func (Mode) EnumDescriptor() ([]byte, []int) {
+	return file_voting_v1_node_proto_rawDescGZIP(), []int{1}
+}
+
+// Order in which readers and writers are served
+type Policy int32
+
+const (
+	Policy_POLICY_UNSPECIFIED       Policy = 0
+	Policy_POLICY_FAIR              Policy = 1
+	Policy_POLICY_WRITER_PREFERENCE Policy = 2
+)
+
+// Enum value maps for Policy.
+var (
+	Policy_name = map[int32]string{
+		0: "POLICY_UNSPECIFIED",
+		1: "POLICY_FAIR",
+		2: "POLICY_WRITER_PREFERENCE",
+	}
+	Policy_value = map[string]int32{
+		"POLICY_UNSPECIFIED":       0,
+		"POLICY_FAIR":              1,
+		"POLICY_WRITER_PREFERENCE": 2,
+	}
+)
+
+func (x Policy) Enum() *Policy {
+	p := new(Policy)
+	*p = x
+	return p
+}
+
+func (x Policy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Policy) Descriptor() protoreflect.EnumDescriptor {
+	return file_voting_v1_node_proto_enumTypes[2].Descriptor()
+}
+
+func (Policy) Type() protoreflect.EnumType {
+	return &file_voting_v1_node_proto_enumTypes[2]
+}
+
+func (x Policy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Policy.Descriptor instead.
+func (Policy) EnumDescriptor() ([]byte, []int) {
+	return file_voting_v1_node_proto_rawDescGZIP(), []int{2}
+}
+
+// Message between two nodes, used for the protocol messages as well as for the setup of the network
+type Message struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Type                MessageType            `protobuf:"varint,1,opt,name=type,proto3,enum=voting.v1.MessageType" json:"type,omitempty"`
+	Id                  int64                  `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	Ip                  string                 `protobuf:"bytes,3,opt,name=ip,proto3" json:"ip,omitempty"`
+	ReqTime             int64                  `protobuf:"varint,4,opt,name=req_time,json=reqTime,proto3" json:"req_time,omitempty"` // Request timestamp
+	Clock               int64                  `protobuf:"varint,5,opt,name=clock,proto3" json:"clock,omitempty"`                    // Lamport clock of the sender
+	NumRequests         int64                  `protobuf:"varint,6,opt,name=num_requests,json=numRequests,proto3" json:"num_requests,omitempty"`
+	K                   int64                  `protobuf:"varint,7,opt,name=k,proto3" json:"k,omitempty"` // Number of nodes that can be in the critical section at the same time
+	Mode                Mode                   `protobuf:"varint,8,opt,name=mode,proto3,enum=voting.v1.Mode" json:"mode,omitempty"`
+	NumReaders          int64                  `protobuf:"varint,9,opt,name=num_readers,json=numReaders,proto3" json:"num_readers,omitempty"` // Number of the requesting nodes that request for READ access
+	Policy              Policy                 `protobuf:"varint,10,opt,name=policy,proto3,enum=voting.v1.Policy" json:"policy,omitempty"`
+	Timeout             int64                  `protobuf:"varint,11,opt,name=timeout,proto3" json:"timeout,omitempty"`                                                      // Seconds a requesting node waits for the lock before giving up, 0 to wait forever
+	Ttl                 int64                  `protobuf:"varint,12,opt,name=ttl,proto3" json:"ttl,omitempty"`                                                              // Seconds a request stays valid without being renewed, 0 to turn leases off
+	Fence               int64                  `protobuf:"varint,13,opt,name=fence,proto3" json:"fence,omitempty"`                                                          // Vote epoch carried by a vote, or the fencing token recorded with a voter
+	Resource            string                 `protobuf:"bytes,14,opt,name=resource,proto3" json:"resource,omitempty"`                                                     // Name of the resource the message is about
+	NumResources        int64                  `protobuf:"varint,15,opt,name=num_resources,json=numResources,proto3" json:"num_resources,omitempty"`                        // Number of resources the requesting nodes are spread over
+	ResourcesPerRequest int64                  `protobuf:"varint,16,opt,name=resources_per_request,json=resourcesPerRequest,proto3" json:"resources_per_request,omitempty"` // Number of resources each request takes at once
+	Signer              int64                  `protobuf:"varint,17,opt,name=signer,proto3" json:"signer,omitempty"`                                                        // ID of the node that signed the message
+	Signature           []byte                 `protobuf:"bytes,18,opt,name=signature,proto3" json:"signature,omitempty"`                                                   // Ed25519 signature of the signer over the other fields, empty if signing is off
+	Certificates        []*VoteCertificate     `protobuf:"bytes,19,rep,name=certificates,proto3" json:"certificates,omitempty"`                                             // Certificate of a vote, or the certificates of the votes of a writer
+	Secret              string                 `protobuf:"bytes,20,opt,name=secret,proto3" json:"secret,omitempty"`                                                         // Shared secret of the admin service, only set on admin calls
+	To                  int64                  `protobuf:"varint,21,opt,name=to,proto3" json:"to,omitempty"`                                                                // ID of the node a VOTE or RELEASE is meant for, so that it cannot be replayed to another node
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Message) Reset() {
+	*x = Message{}
+	mi := &file_voting_v1_node_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Message) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Message) ProtoMessage() {}
+
+func (x *Message) ProtoReflect() protoreflect.Message {
+	mi := &file_voting_v1_node_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Message.ProtoReflect.Descriptor instead.
+func (*Message) Descriptor() ([]byte, []int) {
+	return file_voting_v1_node_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Message) GetType() MessageType {
+	if x != nil {
+		return x.Type
+	}
+	return MessageType_MESSAGE_TYPE_UNSPECIFIED
+}
+
+func (x *Message) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Message) GetIp() string {
+	if x != nil {
+		return x.Ip
+	}
+	return ""
+}
+
+func (x *Message) GetReqTime() int64 {
+	if x != nil {
+		return x.ReqTime
+	}
+	return 0
+}
+
+func (x *Message) GetClock() int64 {
+	if x != nil {
+		return x.Clock
+	}
+	return 0
+}
+
+func (x *Message) GetNumRequests() int64 {
+	if x != nil {
+		return x.NumRequests
+	}
+	return 0
+}
+
+func (x *Message) GetK() int64 {
+	if x != nil {
+		return x.K
+	}
+	return 0
+}
+
+func (x *Message) GetMode() Mode {
+	if x != nil {
+		return x.Mode
+	}
+	return Mode_MODE_UNSPECIFIED
+}
+
+func (x *Message) GetNumReaders() int64 {
+	if x != nil {
+		return x.NumReaders
+	}
+	return 0
+}
+
+func (x *Message) GetPolicy() Policy {
+	if x != nil {
+		return x.Policy
+	}
+	return Policy_POLICY_UNSPECIFIED
+}
+
+func (x *Message) GetTimeout() int64 {
+	if x != nil {
+		return x.Timeout
+	}
+	return 0
+}
+
+func (x *Message) GetTtl() int64 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *Message) GetFence() int64 {
+	if x != nil {
+		return x.Fence
+	}
+	return 0
+}
+
+func (x *Message) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *Message) GetNumResources() int64 {
+	if x != nil {
+		return x.NumResources
+	}
+	return 0
+}
+
+func (x *Message) GetResourcesPerRequest() int64 {
+	if x != nil {
+		return x.ResourcesPerRequest
+	}
+	return 0
+}
+
+func (x *Message) GetSigner() int64 {
+	if x != nil {
+		return x.Signer
+	}
+	return 0
+}
+
+func (x *Message) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+func (x *Message) GetCertificates() []*VoteCertificate {
+	if x != nil {
+		return x.Certificates
+	}
+	return nil
+}
+
+func (x *Message) GetSecret() string {
+	if x != nil {
+		return x.Secret
+	}
+	return ""
+}
+
+func (x *Message) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+// Signed statement of a voter that it voted for a request
+type VoteCertificate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Voter         int64                  `protobuf:"varint,1,opt,name=voter,proto3" json:"voter,omitempty"`
+	Candidate     int64                  `protobuf:"varint,2,opt,name=candidate,proto3" json:"candidate,omitempty"`            // ID of the node the vote was cast for
+	ReqTime       int64                  `protobuf:"varint,3,opt,name=req_time,json=reqTime,proto3" json:"req_time,omitempty"` // Timestamp of the request the vote was cast for
+	Mode          Mode                   `protobuf:"varint,4,opt,name=mode,proto3,enum=voting.v1.Mode" json:"mode,omitempty"`
+	Resource      string                 `protobuf:"bytes,5,opt,name=resource,proto3" json:"resource,omitempty"`
+	Epoch         int64                  `protobuf:"varint,6,opt,name=epoch,proto3" json:"epoch,omitempty"` // Vote epoch of the voter
+	Signature     []byte                 `protobuf:"bytes,7,opt,name=signature,proto3" json:"signature,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VoteCertificate) Reset() {
+	*x = VoteCertificate{}
+	mi := &file_voting_v1_node_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VoteCertificate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoteCertificate) ProtoMessage() {}
+
+func (x *VoteCertificate) ProtoReflect() protoreflect.Message {
+	mi := &file_voting_v1_node_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoteCertificate.ProtoReflect.Descriptor instead.
+func (*VoteCertificate) Descriptor() ([]byte, []int) {
+	return file_voting_v1_node_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *VoteCertificate) GetVoter() int64 {
+	if x != nil {
+		return x.Voter
+	}
+	return 0
+}
+
+func (x *VoteCertificate) GetCandidate() int64 {
+	if x != nil {
+		return x.Candidate
+	}
+	return 0
+}
+
+func (x *VoteCertificate) GetReqTime() int64 {
+	if x != nil {
+		return x.ReqTime
+	}
+	return 0
+}
+
+func (x *VoteCertificate) GetMode() Mode {
+	if x != nil {
+		return x.Mode
+	}
+	return Mode_MODE_UNSPECIFIED
+}
+
+func (x *VoteCertificate) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *VoteCertificate) GetEpoch() int64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+func (x *VoteCertificate) GetSignature() []byte {
+	if x != nil {
+		return x.Signature
+	}
+	return nil
+}
+
+// Call of a method of the receiving node, like Node.ReceiveMessage
+type ConnectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"` // Matches the call with its result on the stream
+	Method        string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Message       *Message               `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectRequest) Reset() {
+	*x = ConnectRequest{}
+	mi := &file_voting_v1_node_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectRequest) ProtoMessage() {}
+
+func (x *ConnectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_voting_v1_node_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectRequest.ProtoReflect.Descriptor instead.
+func (*ConnectRequest) Descriptor() ([]byte, []int) {
+	return file_voting_v1_node_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ConnectRequest) GetCallId() uint64 {
+	if x != nil {
+		return x.CallId
+	}
+	return 0
+}
+
+func (x *ConnectRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *ConnectRequest) GetMessage() *Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+// Result of a call
+type ConnectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CallId        uint64                 `protobuf:"varint,1,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	Reply         *Message               `protobuf:"bytes,2,opt,name=reply,proto3" json:"reply,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"` // Empty if the call succeeded
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConnectResponse) Reset() {
+	*x = ConnectResponse{}
+	mi := &file_voting_v1_node_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConnectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConnectResponse) ProtoMessage() {}
+
+func (x *ConnectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_voting_v1_node_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConnectResponse.ProtoReflect.Descriptor instead.
+func (*ConnectResponse) Descriptor() ([]byte, []int) {
+	return file_voting_v1_node_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ConnectResponse) GetCallId() uint64 {
+	if x != nil {
+		return x.CallId
+	}
+	return 0
+}
+
+func (x *ConnectResponse) GetReply() *Message {
+	if x != nil {
+		return x.Reply
+	}
+	return nil
+}
+
+func (x *ConnectResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_voting_v1_node_proto protoreflect.FileDescriptor
+
+var file_voting_v1_node_proto_rawDesc = string([]byte{
+	0x0a, 0x14, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2f, 0x76, 0x31, 0x2f, 0x6e, 0x6f, 0x64, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x31, 0x22, 0xfd, 0x04, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x2a, 0x0a,
+	0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x16, 0x2e, 0x76, 0x6f,
+	0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x54,
+	0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x69, 0x64, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x70, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x70, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x65, 0x71,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x65, 0x71,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x05, 0x63, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x21, 0x0a, 0x0c, 0x6e, 0x75,
+	0x6d, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x0b, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x0c, 0x0a,
+	0x01, 0x6b, 0x18, 0x07, 0x20, 0x01, 0x28, 0x03, 0x52, 0x01, 0x6b, 0x12, 0x23, 0x0a, 0x04, 0x6d,
+	0x6f, 0x64, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x76, 0x6f, 0x74, 0x69,
+	0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65,
+	0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x75, 0x6d, 0x5f, 0x72, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x73, 0x12, 0x29, 0x0a, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x11, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x6f,
+	0x6c, 0x69, 0x63, 0x79, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x18, 0x0a, 0x07,
+	0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x74,
+	0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x74, 0x6c, 0x18, 0x0c, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x03, 0x74, 0x74, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x66, 0x65, 0x6e, 0x63,
+	0x65, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x66, 0x65, 0x6e, 0x63, 0x65, 0x12, 0x1a,
+	0x0a, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x0e, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x6e, 0x75,
+	0x6d, 0x5f, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0c, 0x6e, 0x75, 0x6d, 0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12,
+	0x32, 0x0a, 0x15, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x5f, 0x70, 0x65, 0x72,
+	0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x10, 0x20, 0x01, 0x28, 0x03, 0x52, 0x13,
+	0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x50, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x18, 0x11, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x06, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x12, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x3e, 0x0a, 0x0c, 0x63, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x18, 0x13, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x6f, 0x74, 0x65,
+	0x43, 0x65, 0x72, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x52, 0x0c, 0x63, 0x65, 0x72,
+	0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x65, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x65, 0x63,
+	0x72, 0x65, 0x74, 0x18, 0x14, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x65, 0x63, 0x72, 0x65,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x15, 0x20, 0x01, 0x28, 0x03, 0x52, 0x02, 0x74,
+	0x6f, 0x22, 0xd5, 0x01, 0x0a, 0x0f, 0x56, 0x6f, 0x74, 0x65, 0x43, 0x65, 0x72, 0x74, 0x69, 0x66,
+	0x69, 0x63, 0x61, 0x74, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x76, 0x6f, 0x74, 0x65, 0x72, 0x12, 0x1c, 0x0a, 0x09, 0x63,
+	0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09,
+	0x63, 0x61, 0x6e, 0x64, 0x69, 0x64, 0x61, 0x74, 0x65, 0x12, 0x19, 0x0a, 0x08, 0x72, 0x65, 0x71,
+	0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x65, 0x71,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x0f, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
+	0x6f, 0x64, 0x65, 0x52, 0x04, 0x6d, 0x6f, 0x64, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x73,
+	0x6f, 0x75, 0x72, 0x63, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x18, 0x06,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x05, 0x65, 0x70, 0x6f, 0x63, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x73,
+	0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x6f, 0x0a, 0x0e, 0x43, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x17, 0x0a, 0x07, 0x63,
+	0x61, 0x6c, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x63, 0x61,
+	0x6c, 0x6c, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x2c, 0x0a, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e,
+	0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67,
+	0x65, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x6a, 0x0a, 0x0f, 0x43, 0x6f,
+	0x6e, 0x6e, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x17, 0x0a,
+	0x07, 0x63, 0x61, 0x6c, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06,
+	0x63, 0x61, 0x6c, 0x6c, 0x49, 0x64, 0x12, 0x28, 0x0a, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x12, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x52, 0x05, 0x72, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x2a, 0xa3, 0x02, 0x0a, 0x0b, 0x4d, 0x65, 0x73, 0x73, 0x61,
+	0x67, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x18, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47,
+	0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49,
+	0x45, 0x44, 0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54, 0x10, 0x01, 0x12, 0x15,
+	0x0a, 0x11, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x56,
+	0x4f, 0x54, 0x45, 0x10, 0x02, 0x12, 0x18, 0x0a, 0x14, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x4c, 0x45, 0x41, 0x53, 0x45, 0x10, 0x03, 0x12,
+	0x1d, 0x0a, 0x19, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x52, 0x45, 0x53, 0x43, 0x49, 0x4e, 0x44, 0x5f, 0x56, 0x4f, 0x54, 0x45, 0x10, 0x04, 0x12, 0x14,
+	0x0a, 0x10, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x41,
+	0x43, 0x4b, 0x10, 0x05, 0x12, 0x15, 0x0a, 0x11, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x44, 0x45, 0x4e, 0x59, 0x10, 0x06, 0x12, 0x17, 0x0a, 0x13, 0x4d,
+	0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x43, 0x41, 0x4e, 0x43,
+	0x45, 0x4c, 0x10, 0x07, 0x12, 0x16, 0x0a, 0x12, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x52, 0x45, 0x4e, 0x45, 0x57, 0x10, 0x08, 0x12, 0x16, 0x0a, 0x12,
+	0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x46, 0x45, 0x4e,
+	0x43, 0x45, 0x10, 0x09, 0x12, 0x16, 0x0a, 0x12, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f,
+	0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x52, 0x4f, 0x4f, 0x46, 0x10, 0x0a, 0x2a, 0x3b, 0x0a, 0x04,
+	0x4d, 0x6f, 0x64, 0x65, 0x12, 0x14, 0x0a, 0x10, 0x4d, 0x4f, 0x44, 0x45, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0d, 0x0a, 0x09, 0x4d, 0x4f,
+	0x44, 0x45, 0x5f, 0x52, 0x45, 0x41, 0x44, 0x10, 0x01, 0x12, 0x0e, 0x0a, 0x0a, 0x4d, 0x4f, 0x44,
+	0x45, 0x5f, 0x57, 0x52, 0x49, 0x54, 0x45, 0x10, 0x02, 0x2a, 0x4f, 0x0a, 0x06, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x12, 0x16, 0x0a, 0x12, 0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x55, 0x4e,
+	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x0f, 0x0a, 0x0b, 0x50,
+	0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x46, 0x41, 0x49, 0x52, 0x10, 0x01, 0x12, 0x1c, 0x0a, 0x18,
+	0x50, 0x4f, 0x4c, 0x49, 0x43, 0x59, 0x5f, 0x57, 0x52, 0x49, 0x54, 0x45, 0x52, 0x5f, 0x50, 0x52,
+	0x45, 0x46, 0x45, 0x52, 0x45, 0x4e, 0x43, 0x45, 0x10, 0x02, 0x32, 0x53, 0x0a, 0x0b, 0x4e, 0x6f,
+	0x64, 0x65, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x44, 0x0a, 0x07, 0x43, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x12, 0x19, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1a, 0x2e, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x6e,
+	0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x28, 0x01, 0x30, 0x01, 0x42,
+	0x28, 0x5a, 0x26, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63,
+	0x6f, 0x6c, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x2f, 0x76, 0x31,
+	0x3b, 0x76, 0x6f, 0x74, 0x69, 0x6e, 0x67, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+})
+
+var (
+	file_voting_v1_node_proto_rawDescOnce sync.Once
+	file_voting_v1_node_proto_rawDescData []byte
+)
+
+func file_voting_v1_node_proto_rawDescGZIP() []byte {
+	file_voting_v1_node_proto_rawDescOnce.Do(func() {
+		file_voting_v1_node_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_voting_v1_node_proto_rawDesc), len(file_voting_v1_node_proto_rawDesc)))
+	})
+	return file_voting_v1_node_proto_rawDescData
+}
+
+var file_voting_v1_node_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_voting_v1_node_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_voting_v1_node_proto_goTypes = []any{
+	(MessageType)(0),        // 0: voting.v1.MessageType
+	(Mode)(0),               // 1: voting.v1.Mode
+	(Policy)(0),             // 2: voting.v1.Policy
+	(*Message)(nil),         // 3: voting.v1.Message
+	(*VoteCertificate)(nil), // 4: voting.v1.VoteCertificate
+	(*ConnectRequest)(nil),  // 5: voting.v1.ConnectRequest
+	(*ConnectResponse)(nil), // 6: voting.v1.ConnectResponse
+}
+var file_voting_v1_node_proto_depIdxs = []int32{
+	0, // 0: voting.v1.Message.type:type_name -> voting.v1.MessageType
+	1, // 1: voting.v1.Message.mode:type_name -> voting.v1.Mode
+	2, // 2: voting.v1.Message.policy:type_name -> voting.v1.Policy
+	4, // 3: voting.v1.Message.certificates:type_name -> voting.v1.VoteCertificate
+	1, // 4: voting.v1.VoteCertificate.mode:type_name -> voting.v1.Mode
+	3, // 5: voting.v1.ConnectRequest.message:type_name -> voting.v1.Message
+	3, // 6: voting.v1.ConnectResponse.reply:type_name -> voting.v1.Message
+	5, // 7: voting.v1.NodeService.Connect:input_type -> voting.v1.ConnectRequest
+	6, // 8: voting.v1.NodeService.Connect:output_type -> voting.v1.ConnectResponse
+	8, // [8:9] is the sub-list for method output_type
+	7, // [7:8] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_voting_v1_node_proto_init() }
+func file_voting_v1_node_proto_init() {
+	if File_voting_v1_node_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_voting_v1_node_proto_rawDesc), len(file_voting_v1_node_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_voting_v1_node_proto_goTypes,
+		DependencyIndexes: file_voting_v1_node_proto_depIdxs,
+		EnumInfos:         file_voting_v1_node_proto_enumTypes,
+		MessageInfos:      file_voting_v1_node_proto_msgTypes,
+	}.Build()
+	File_voting_v1_node_proto = out.File
+	file_voting_v1_node_proto_goTypes = nil
+	file_voting_v1_node_proto_depIdxs = nil
+}
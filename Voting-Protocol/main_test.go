@@ -0,0 +1,172 @@
+package main
+
+import (
+	"common/cluster"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+	"voting_protocol/node"
+)
+
+// Once every node has released the lock, every node reports its vote as free with Node.GetState, whatever the transport
+func TestGetStateAfterTheWorkload(t *testing.T) {
+	for _, transport := range []string{node.RPC_TRANSPORT, node.GRPC_TRANSPORT} {
+		t.Run(transport, func(t *testing.T) {
+			t.Setenv("TRANSPORT", transport)
+			c := cluster.Start(t, 3, "-requests", "3")
+
+			// The node holding the lock has collected enough votes
+			waitForState(c, 0, func(state node.State) bool {
+				return slices.ContainsFunc(state.Resources, func(r node.ResourceState) bool { return r.InCS && len(r.VotesReceived) >= r.VotesNeeded })
+			})
+			c.WaitFor(0, "Time taken", time.Minute)
+
+			for i := 0; i < 3; i++ {
+				state := waitForState(c, i, func(state node.State) bool {
+					return len(state.Resources) == 1 && !state.Resources[0].Request && state.Resources[0].Votes == 1 && len(state.Resources[0].Queue) == 0
+				})
+				if state.ID != i || len(state.Network) != 2 || state.Clock == 0 || state.Resources[0].InCS || len(state.Resources[0].VotesReceived) != 0 {
+					t.Fatalf("unexpected state of node %d: %+v", i, state)
+				}
+			}
+			state := waitForState(c, 0, func(state node.State) bool { return !slices.Contains(state.Finished, false) })
+			if len(state.Finished) != 3 {
+				t.Fatalf("node 0 does not know about every node: %+v", state)
+			}
+		})
+	}
+}
+
+// The lease watcher and the handlers of the requests and releases change the votes concurrently. With readers
+// on and the first node that enters the critical section crashing, so that its lease expires, a cluster built with
+// the race detector must finish safely without reporting a race.
+func TestLeasesWithoutDataRace(t *testing.T) {
+	binary, err := cluster.Build(t.TempDir(), "-race")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The race detector writes its reports to a file of its own for every node
+	reports := t.TempDir()
+	t.Setenv("GORACE", "log_path=" + filepath.Join(reports, "race"))
+	c := cluster.StartBinary(t, binary, 5, "-requests", "4", "-readers", "1", "-ttl", "2")
+
+	// Node 0 collects the results, so one of the other nodes crashes
+	crashed := -1
+	for deadline := time.Now().Add(time.Minute); crashed < 0; time.Sleep(50 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("no node entered the critical section")
+		}
+		for i := 1; i < 5 && crashed < 0; i++ {
+			out, _ := os.ReadFile(c.Logs[i])
+			if strings.Contains(string(out), "Entering the critical section") {
+				c.Kill(i)
+				crashed = i
+			}
+		}
+	}
+
+	out := c.WaitFor(0, "Safety check", 2 * time.Minute)
+	if !strings.Contains(out, "Safety check passed") {
+		t.Fatalf("the critical section was shared unsafely, the output of node 0 was:\n%s", out)
+	}
+	races, _ := filepath.Glob(filepath.Join(reports, "race.*"))
+	for _, race := range races {
+		report, _ := os.ReadFile(race)
+		t.Fatalf("a node reported a data race:\n%s", report)
+	}
+}
+
+// Once the holder of the lock crashes, its lease expires at the voters: they take their votes back, vote for the
+// node waiting next and ignore a release of the crashed node that turns up later
+func TestLeaseExpiry(t *testing.T) {
+	c := cluster.Start(t, 5, "-requests", "0", "-ttl", "2")
+	for i := range c.Logs {
+		c.WaitFor(i, "Node will not request", time.Minute)
+	}
+
+	c.Command(1, "request")
+	c.WaitFor(1, "Holding resource-0", time.Minute)
+	holder := waitForState(c, 1, func(state node.State) bool { return len(state.Resources) == 1 && state.Resources[0].InCS })
+	c.Command(2, "request")
+	for _, i := range []int{0, 1, 3, 4} {
+		waitForState(c, i, func(state node.State) bool {
+			return len(state.Resources) == 1 && (state.Resources[0].PrevReq.ID == 2 || slices.ContainsFunc(state.Resources[0].Queue, func(p node.Pointer) bool { return p.ID == 2 }))
+		})
+	}
+
+	// The holder crashes without releasing, so only its lease can free the votes
+	c.Kill(1)
+	c.WaitFor(2, "Holding resource-0", time.Minute)
+	for _, i := range []int{0, 3, 4} {
+		c.WaitFor(i, "Lease of node 1 on resource-0 expired", time.Minute)
+		state := waitForState(c, i, func(state node.State) bool { return len(state.Resources) == 1 && state.Resources[0].PrevReq.ID == 2 })
+		if r := state.Resources[0]; r.Votes != 0 || len(r.Queue) != 0 {
+			t.Fatalf("unexpected vote of node %d after the lease of node 1 expired: %+v", i, r)
+		}
+	}
+
+	// A release of the old holder must not free the vote the new holder has
+	late := node.Message{Type: node.RELEASE, ID: 1, IP: c.Peers[1], ReqTime: holder.Resources[0].ReqTime, To: 3, Resource: "resource-0"}
+	var reply node.Message
+	err := c.Call(3, "Node.ReceiveMessage", late, &reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.WaitFor(3, "Ignoring the release of node 1", time.Minute)
+	state := waitForState(c, 3, func(state node.State) bool { return len(state.Resources) == 1 })
+	if r := state.Resources[0]; r.Votes != 0 || r.PrevReq.ID != 2 {
+		t.Fatalf("the late release of node 1 freed the vote of node 3: %+v", r)
+	}
+}
+
+// A request that gives up, because the lock is busy or the timeout runs out, must leave no queue entry and no vote
+// behind at any node, so that a later request still gets the lock
+func TestTryLockAndTimeoutLeaveNothingBehind(t *testing.T) {
+	t.Setenv("HTTP_PORT", "1")
+	c := cluster.Start(t, 5, "-requests", "0")
+	for i := range c.Logs {
+		c.WaitFor(i, "Node will not request", time.Minute)
+		c.WaitFor(i, "HTTP API is running", time.Minute)
+	}
+
+	c.Command(1, "request")
+	c.WaitFor(1, "Holding resource-0", time.Minute)
+
+	var res node.LockResponse
+	if status := c.HTTP(2, "POST", "/locks/resource-0/acquire", node.AcquireRequest{Try: true}, &res); status != http.StatusConflict {
+		t.Fatalf("TryLock of node 2 on a held lock answered %d: %+v", status, res)
+	}
+	// The voters that queue the request say so, so TryLock gives up without waiting for the other answers
+	c.WaitFor(2, "A node queued the request for resource-0", time.Minute)
+	if status := c.HTTP(3, "POST", "/locks/resource-0/acquire", node.AcquireRequest{Timeout: 1}, &res); status != http.StatusRequestTimeout {
+		t.Fatalf("acquisition of node 3 with a timeout on a held lock answered %d: %+v", status, res)
+	}
+
+	// Only the holder keeps a request and the votes
+	gaveUp := func(p node.Pointer) bool { return p.ID == 2 || p.ID == 3 }
+	for i := range c.Logs {
+		waitForState(c, i, func(state node.State) bool {
+			if len(state.Resources) != 1 {
+				return false
+			}
+			r := state.Resources[0]
+			return !slices.ContainsFunc(r.Queue, gaveUp) && !gaveUp(r.PrevReq) && !slices.ContainsFunc(r.ReadVotes, gaveUp) && (i == 1 || !r.Request && len(r.VotesReceived) == 0)
+		})
+	}
+
+	c.Command(1, "release")
+	c.WaitFor(1, "Released resource-0", time.Minute)
+	for i := range c.Logs {
+		waitForState(c, i, func(state node.State) bool { return len(state.Resources) == 1 && (i == 1 || state.Resources[0].Votes == 1) })
+	}
+	if status := c.HTTP(2, "POST", "/locks/resource-0/acquire", node.AcquireRequest{Try: true}, &res); status != http.StatusOK {
+		t.Fatalf("TryLock of node 2 on a free lock answered %d: %+v", status, res)
+	}
+	if status := c.HTTP(2, "POST", "/locks/resource-0/release", node.ReleaseRequest{Token: &res.Token}, &res); status != http.StatusOK {
+		t.Fatalf("release of node 2 answered %d: %+v", status, res)
+	}
+}
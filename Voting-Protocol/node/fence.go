@@ -0,0 +1,138 @@
+package node
+
+import (
+	"common/storage"
+	"fmt"
+)
+
+// Function to enter the critical section once the node holds enough votes. The fencing token is the largest vote
+// epoch among the votes, and it is recorded with the voters before the node enters. Any two sets of enough
+// votes share a voter, and that voter only votes for the next writer with a larger epoch, so the tokens of
+// writers always increase.
+func (n *Node) enter(name string) {
+	for {
+		n.Lock.Lock()
+		r := n.resource(name)
+		if !r.Request || len(r.VotesReceived) < n.votesNeeded(r) {
+			r.Entering = false
+			n.Lock.Unlock()
+			return
+		}
+		votes := append([]Pointer{}, r.VotesReceived...)
+		token := int64(0)
+		for _, vote := range votes {
+			token = max(token, vote.Epoch)
+		}
+		mode := r.Mode
+		reqTime := r.ReqTime
+		needed := n.votesNeeded(r)
+		certs := []VoteCertificate{}
+		for _, vote := range votes {
+			if cert, ok := r.Certificates[vote.ID]; ok && cert.ReqTime == reqTime {
+				certs = append(certs, cert)
+			}
+		}
+		n.Lock.Unlock()
+
+		// Readers do not write, so they do not need to record their token
+		acked, denied := len(votes), []Pointer{}
+		if mode == WRITE {
+			if signingKey != nil {
+				go n.sendProof(name, votes, reqTime, certs)
+			}
+			acked, denied = n.sendFence(name, votes, reqTime, token, certs, needed)
+		}
+
+		n.Lock.Lock()
+		for _, voter := range denied {
+			if Contains(r.VotesReceived, voter) {
+				r.VotesReceived = Remove(r.VotesReceived, voter)
+			}
+		}
+		if r.Request && acked >= needed {
+			fmt.Printf("[NODE-%d] Enough votes received for %s. Entering the critical section with the fencing token %d\n", n.ID, name, token)
+			r.InCS = true
+			r.Entering = false
+			r.Fence = token
+			r.Granted <- true
+			n.Lock.Unlock()
+			return
+		}
+		n.Lock.Unlock()
+	}
+}
+
+// Function to record the fencing token with the voters. Returns as soon as enough voters have recorded the token,
+// so a voter that does not answer cannot hold the node up. Returns the number of voters that recorded the token and
+// the voters that no longer hold their vote for the node.
+func (n *Node) sendFence(name string, votes []Pointer, reqTime int, token int64, certs []VoteCertificate, needed int) (int, []Pointer) {
+	type result struct {
+		vote Pointer
+		ok bool
+	}
+	results := make(chan result, len(votes))
+
+	for _, vote := range votes {
+		n.Lock.Lock()
+		n.Clock++
+		msg := Message{Type: FENCE, ID: n.ID, IP: n.IP, ReqTime: reqTime, Fence: token, Resource: name, Clock: n.Clock, Certificates: certs}
+		n.Lock.Unlock()
+
+		go func() {
+			reply, err := CallByRPC(vote.IP, "Node.ReceiveMessage", msg)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while sending the fencing token to node %d: %s\n", n.ID, vote.ID, err)
+			}
+			results <- result{vote: vote, ok: err == nil && reply.Type == ACK}
+		}()
+	}
+
+	acked := 0
+	denied := []Pointer{}
+	for range votes {
+		res := <-results
+		if !res.ok {
+			denied = append(denied, res.vote)
+		} else if acked++; acked >= needed {
+			break
+		}
+	}
+	return acked, denied
+}
+
+// Function to send the certificates of the votes of a writer to the nodes that did not vote for it, so that every
+// node can find voters that voted for two writers with the same vote epoch
+func (n *Node) sendProof(name string, votes []Pointer, reqTime int, certs []VoteCertificate) {
+	for i, IP := range n.Network {
+		if Contains(votes, Pointer{ID: i, IP: IP}) {
+			continue
+		}
+		n.Lock.Lock()
+		n.Clock++
+		msg := Message{Type: PROOF, ID: n.ID, IP: n.IP, ReqTime: reqTime, Resource: name, Clock: n.Clock, Certificates: certs}
+		n.Lock.Unlock()
+
+		go func() {
+			_, err := CallByRPC(IP, "Node.ReceiveMessage", msg)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while sending the vote certificates to node %d: %s\n", n.ID, i, err)
+			}
+		}()
+	}
+}
+
+// Function to write to the fenced storage server with the fencing token of a held resource. Returns false if
+// the server rejected the token as stale.
+func (n *Node) WriteStorage(name string, data string) (bool, error) {
+	n.Lock.Lock()
+	token := n.resource(name).Fence
+	n.Lock.Unlock()
+
+	// The server is dialed like a peer, so it is reached over TLS and on Unix domain sockets as well
+	client, err := dial(n.Storage)
+	if err != nil {
+		return false, fmt.Errorf("error in dialing: %s", err)
+	}
+	defer client.Close()
+	return storage.Send(client, storage.Write{Resource: name, Token: token, Node: n.ID, Data: data})
+}
@@ -0,0 +1,388 @@
+package node
+
+import (
+	"common/admin"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+
+	votingv1 "voting_protocol/gen/voting/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	RPC_TRANSPORT = "rpc" // gob-encoded net/rpc, a new connection for every call
+	GRPC_TRANSPORT = "grpc" // protobuf over gRPC, one stream per peer
+)
+
+// Transport used by every node of the network, set from the TRANSPORT environment variable
+var Transport = RPC_TRANSPORT
+
+// Open gRPC streams to the peers, by IP
+var (
+	peers = make(map[string]*peer)
+	peersLock sync.Mutex
+)
+
+// Stream to a peer that carries every call to it
+type peer struct {
+	conn *grpc.ClientConn
+	stream votingv1.NodeService_ConnectClient
+	sendLock sync.Mutex
+	lock sync.Mutex
+	nextID uint64
+	pending map[uint64]chan *votingv1.ConnectResponse // Calls waiting for their result
+	err error // Set once the stream broke
+}
+
+// Server side of the stream of a peer
+type grpcServer struct {
+	votingv1.UnimplementedNodeServiceServer
+	n *Node
+}
+
+// Function to serve gRPC on the connections of gRPC clients
+func (n *Node) startGRPCServer(listener net.Listener) {
+	options := []grpc.ServerOption{}
+	if serverTLS != nil {
+		options = append(options, grpc.Creds(securedConns{}))
+	}
+	server := grpc.NewServer(options...)
+	votingv1.RegisterNodeServiceServer(server, &grpcServer{n: n})
+
+	fmt.Printf("[NODE-%d] Node is running on %s over gRPC\n", n.ID, n.IP)
+	err := server.Serve(listener)
+	if err != nil {
+		fmt.Printf("[NODE-%d] gRPC server stopped: %s\n", n.ID, err)
+	}
+}
+
+// Credentials of the gRPC server for connections whose TLS handshake was already done when they were told apart
+// from the net/rpc connections
+type securedConns struct{}
+
+func (securedConns) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return nil, nil, fmt.Errorf("connection from %s does not use TLS", conn.RemoteAddr())
+	}
+	return conn, credentials.TLSInfo{State: tlsConn.ConnectionState(), CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.PrivacyAndIntegrity}}, nil
+}
+
+func (securedConns) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, fmt.Errorf("only used by the server")
+}
+
+func (securedConns) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "tls", SecurityVersion: "1.3"}
+}
+
+func (c securedConns) Clone() credentials.TransportCredentials {
+	return c
+}
+
+func (securedConns) OverrideServerName(string) error {
+	return nil
+}
+
+// Function to handle the calls of a peer. Every call runs in its own goroutine, like with net/rpc, since a
+// handler may itself call other nodes.
+func (s *grpcServer) Connect(stream votingv1.NodeService_ConnectServer) error {
+	var sender *admin.Identity // nil if mutual TLS is off
+	if serverTLS != nil {
+		peer, err := grpcPeerID(stream.Context())
+		if err != nil {
+			return err
+		}
+		sender = &peer
+	}
+
+	var sendLock sync.Mutex
+	for {
+		call, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+
+		go func() {
+			res := &votingv1.ConnectResponse{CallId: call.CallId}
+			message := fromProto(call.Message)
+			var reply Message
+			var err error
+			if sender != nil {
+				err = s.n.checkSender(*sender, call.Method, message)
+				if err != nil {
+					fmt.Printf("[NODE-%d] Rejected %s from %s: %s\n", s.n.ID, call.Method, *sender, err)
+				}
+			}
+			if err == nil {
+				reply, err = s.n.dispatch(call.Method, message)
+			}
+			if err != nil {
+				res.Error = err.Error()
+			} else {
+				res.Reply = toProto(reply)
+			}
+
+			sendLock.Lock()
+			defer sendLock.Unlock()
+			err = stream.Send(res)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while answering %s: %s\n", s.n.ID, call.Method, err)
+			}
+		}()
+	}
+}
+
+// Function to call an RPC method of the node or of its admin service by its name, like net/rpc does
+func (n *Node) dispatch(method string, message Message) (Message, error) {
+	service, name, _ := strings.Cut(method, ".")
+	var receiver reflect.Value
+	switch service {
+	case "Node":
+		receiver = reflect.ValueOf(n)
+	case admin.SERVICE:
+		receiver = reflect.ValueOf(&Admin{n: n})
+	default:
+		return Message{}, fmt.Errorf("unknown service %s", service)
+	}
+	fn := receiver.MethodByName(name)
+	if !fn.IsValid() {
+		return Message{}, fmt.Errorf("unknown method %s", method)
+	}
+	handler, ok := fn.Interface().(func(Message, *Message) error)
+	if !ok {
+		return Message{}, fmt.Errorf("method %s does not reply with a Message, so it is only served over net/rpc", method)
+	}
+
+	var reply Message
+	err := handler(message, &reply)
+	return reply, err
+}
+
+// Function to call a method of another node over its gRPC stream, opening the stream on first use
+func callByGRPC(IP string, method string, message Message) (Message, error) {
+	p, err := getPeer(IP)
+	if err != nil {
+		return Message{}, fmt.Errorf("error in dialing: %s", err)
+	}
+
+	p.lock.Lock()
+	if p.err != nil {
+		p.lock.Unlock()
+		return Message{}, fmt.Errorf("error in calling %s: %s", method, p.err)
+	}
+	p.nextID++
+	callID := p.nextID
+	result := make(chan *votingv1.ConnectResponse, 1)
+	p.pending[callID] = result
+	p.lock.Unlock()
+
+	p.sendLock.Lock()
+	err = p.stream.Send(&votingv1.ConnectRequest{CallId: callID, Method: method, Message: toProto(message)})
+	p.sendLock.Unlock()
+	if err != nil {
+		p.fail(err)
+		return Message{}, fmt.Errorf("error in calling %s: %s", method, err)
+	}
+
+	res, ok := <-result
+	if !ok {
+		p.lock.Lock()
+		defer p.lock.Unlock()
+		return Message{}, fmt.Errorf("error in calling %s: %s", method, p.err)
+	}
+	if res.Error != "" {
+		return Message{}, fmt.Errorf("error in calling %s: %s", method, res.Error)
+	}
+	return fromProto(res.Reply), nil
+}
+
+// Function to get the open stream to a peer, or open a new one if there is none or the last one broke
+func getPeer(IP string) (*peer, error) {
+	peersLock.Lock()
+	defer peersLock.Unlock()
+
+	if p, ok := peers[IP]; ok {
+		p.lock.Lock()
+		broken := p.err != nil
+		p.lock.Unlock()
+		if !broken {
+			return p, nil
+		}
+	}
+
+	creds := insecure.NewCredentials()
+	if clientTLS != nil {
+		creds = credentials.NewTLS(clientTLS)
+	}
+	conn, err := grpc.NewClient(IP, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	stream, err := votingv1.NewNodeServiceClient(conn).Connect(context.Background())
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	p := &peer{conn: conn, stream: stream, pending: make(map[uint64]chan *votingv1.ConnectResponse)}
+	peers[IP] = p
+	go p.receive()
+	return p, nil
+}
+
+// Function to hand the results coming back on the stream to the waiting calls
+func (p *peer) receive() {
+	for {
+		res, err := p.stream.Recv()
+		if err != nil {
+			p.fail(err)
+			return
+		}
+
+		p.lock.Lock()
+		result, ok := p.pending[res.CallId]
+		delete(p.pending, res.CallId)
+		p.lock.Unlock()
+		if ok {
+			result <- res
+		}
+	}
+}
+
+// Function to mark the stream as broken and fail every call still waiting on it
+func (p *peer) fail(err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.err != nil {
+		return
+	}
+	p.err = err
+	for callID, result := range p.pending {
+		close(result)
+		delete(p.pending, callID)
+	}
+	p.conn.Close()
+}
+
+var messageTypes = map[string]votingv1.MessageType{
+	REQUEST: votingv1.MessageType_MESSAGE_TYPE_REQUEST,
+	VOTE: votingv1.MessageType_MESSAGE_TYPE_VOTE,
+	RELEASE: votingv1.MessageType_MESSAGE_TYPE_RELEASE,
+	RESCIND_VOTE: votingv1.MessageType_MESSAGE_TYPE_RESCIND_VOTE,
+	ACK: votingv1.MessageType_MESSAGE_TYPE_ACK,
+	DENY: votingv1.MessageType_MESSAGE_TYPE_DENY,
+	CANCEL: votingv1.MessageType_MESSAGE_TYPE_CANCEL,
+	RENEW: votingv1.MessageType_MESSAGE_TYPE_RENEW,
+	FENCE: votingv1.MessageType_MESSAGE_TYPE_FENCE,
+	PROOF: votingv1.MessageType_MESSAGE_TYPE_PROOF,
+}
+
+var modes = map[string]votingv1.Mode{
+	READ: votingv1.Mode_MODE_READ,
+	WRITE: votingv1.Mode_MODE_WRITE,
+}
+
+var policies = map[string]votingv1.Policy{
+	FAIR: votingv1.Policy_POLICY_FAIR,
+	WRITER_PREFERENCE: votingv1.Policy_POLICY_WRITER_PREFERENCE,
+}
+
+// Function to convert a message to its protobuf form
+func toProto(message Message) *votingv1.Message {
+	return &votingv1.Message{
+		Type: messageTypes[message.Type],
+		Id: int64(message.ID),
+		Ip: message.IP,
+		ReqTime: int64(message.ReqTime),
+		Clock: int64(message.Clock),
+		NumRequests: int64(message.NumRequests),
+		K: int64(message.K),
+		Mode: modes[message.Mode],
+		NumReaders: int64(message.NumReaders),
+		Policy: policies[message.Policy],
+		Timeout: int64(message.Timeout),
+		Ttl: int64(message.TTL),
+		Fence: message.Fence,
+		Resource: message.Resource,
+		NumResources: int64(message.NumResources),
+		ResourcesPerRequest: int64(message.ResourcesPerRequest),
+		Signer: int64(message.Signer),
+		Signature: message.Signature,
+		Certificates: certificatesToProto(message.Certificates),
+		Secret: message.Secret,
+		To: int64(message.To),
+	}
+}
+
+// Function to convert a message from its protobuf form
+func fromProto(message *votingv1.Message) Message {
+	if message == nil {
+		return Message{}
+	}
+	return Message{
+		Type: lookup(messageTypes, message.Type),
+		ID: int(message.Id),
+		IP: message.Ip,
+		ReqTime: int(message.ReqTime),
+		Clock: int(message.Clock),
+		NumRequests: int(message.NumRequests),
+		K: int(message.K),
+		Mode: lookup(modes, message.Mode),
+		NumReaders: int(message.NumReaders),
+		Policy: lookup(policies, message.Policy),
+		Timeout: int(message.Timeout),
+		TTL: int(message.Ttl),
+		Fence: message.Fence,
+		Resource: message.Resource,
+		NumResources: int(message.NumResources),
+		ResourcesPerRequest: int(message.ResourcesPerRequest),
+		Signer: int(message.Signer),
+		Signature: message.Signature,
+		Certificates: certificatesFromProto(message.Certificates),
+		Secret: message.Secret,
+		To: int(message.To),
+	}
+}
+
+// Function to convert vote certificates to their protobuf form
+func certificatesToProto(certs []VoteCertificate) []*votingv1.VoteCertificate {
+	if certs == nil {
+		return nil
+	}
+	converted := make([]*votingv1.VoteCertificate, 0, len(certs))
+	for _, cert := range certs {
+		converted = append(converted, &votingv1.VoteCertificate{Voter: int64(cert.Voter), Candidate: int64(cert.Candidate), ReqTime: int64(cert.ReqTime), Mode: modes[cert.Mode], Resource: cert.Resource, Epoch: cert.Epoch, Signature: cert.Signature})
+	}
+	return converted
+}
+
+// Function to convert vote certificates from their protobuf form
+func certificatesFromProto(certs []*votingv1.VoteCertificate) []VoteCertificate {
+	if certs == nil {
+		return nil
+	}
+	converted := make([]VoteCertificate, 0, len(certs))
+	for _, cert := range certs {
+		converted = append(converted, VoteCertificate{Voter: int(cert.Voter), Candidate: int(cert.Candidate), ReqTime: int(cert.ReqTime), Mode: lookup(modes, cert.Mode), Resource: cert.Resource, Epoch: cert.Epoch, Signature: cert.Signature})
+	}
+	return converted
+}
+
+// Function to find the string form of an enum value, or the empty string if it is unspecified
+func lookup[E comparable](values map[string]E, value E) string {
+	for name, v := range values {
+		if v == value {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,69 @@
+package node
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Function to send a request to the HTTP API and decode the answer
+func post(t *testing.T, server *httptest.Server, path string, body string) (int, LockResponse) {
+	res, err := http.Post(server.URL + path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	var reply LockResponse
+	err = json.NewDecoder(res.Body).Decode(&reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, reply
+}
+
+// Only the fencing token of the entry releases a lock the node holds
+func TestHTTPRelease(t *testing.T) {
+	n := &Node{Network: map[int]string{}, K: 1, Mode: WRITE, Policy: FAIR}
+	server := httptest.NewServer(n.httpHandler())
+	defer server.Close()
+
+	cases := []struct {
+		path string
+		body string
+		status int
+	}{
+		{"/locks/resource-0/release", `{"token": 7}`, http.StatusConflict}, // Not held
+		{"/locks/resource-0/acquire", `{"mode": "exclusive"}`, http.StatusBadRequest},
+		{"/locks/resource-0/release", `{}`, http.StatusBadRequest}, // No token
+		{"/locks/resource-0/release", `not json`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		if status, reply := post(t, server, c.path, c.body); status != c.status {
+			t.Errorf("%s with %s answered %d, want %d: %+v", c.path, c.body, status, c.status, reply)
+		}
+	}
+
+	// The node entered the critical section with the fencing token 7
+	n.Lock.Lock()
+	r := n.resource("resource-0")
+	r.Request, r.InCS, r.Fence = true, true, 7
+	n.Lock.Unlock()
+
+	if status, reply := post(t, server, "/locks/resource-0/release", `{"token": 6}`); status != http.StatusConflict {
+		t.Fatalf("release with a stale token answered %d: %+v", status, reply)
+	}
+	n.Lock.Lock()
+	held := r.InCS
+	n.Lock.Unlock()
+	if !held {
+		t.Fatalf("a release with a stale token released the lock")
+	}
+	if status, reply := post(t, server, "/locks/resource-0/release", `{"token": 7}`); status != http.StatusOK {
+		t.Fatalf("release with the fencing token answered %d: %+v", status, reply)
+	}
+	if status, reply := post(t, server, "/locks/resource-0/release", `{"token": 7}`); status != http.StatusConflict {
+		t.Fatalf("second release answered %d: %+v", status, reply)
+	}
+}
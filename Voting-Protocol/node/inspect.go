@@ -0,0 +1,78 @@
+package node
+
+import (
+	"common/inspect"
+	"fmt"
+	"strings"
+)
+
+// Function to fill in the part of the state shown by the console and the dashboard
+func summarize(state *State) {
+	state.Phase = inspect.IDLE
+	for _, r := range state.Resources {
+		if r.InCS {
+			state.Phase = inspect.IN_CS
+		} else if r.Request && state.Phase == inspect.IDLE {
+			state.Phase = inspect.REQUESTING
+		}
+	}
+	state.Status = statusLines(*state)
+	state.Queues = queueLines(*state)
+	state.Votes = voteLines(*state)
+}
+
+// Function to describe the requests and locks of a node
+func statusLines(state State) []string {
+	lines := []string{}
+	for _, r := range state.Resources {
+		switch {
+		case r.InCS:
+			lines = append(lines, fmt.Sprintf("%s: holding for %s access with fencing token %d", r.Name, r.Mode, r.Fence))
+		case r.Request:
+			lines = append(lines, fmt.Sprintf("%s: requesting %s access with timestamp %d", r.Name, r.Mode, r.ReqTime))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: idle", r.Name))
+		}
+	}
+	return lines
+}
+
+// Function to describe the requests waiting for the vote of a node on every resource
+func queueLines(state State) []string {
+	lines := []string{}
+	for _, r := range state.Resources {
+		requests := []string{}
+		for _, request := range r.Queue {
+			requests = append(requests, describe(request))
+		}
+		lines = append(lines, fmt.Sprintf("Queue of %s: [%s]", r.Name, strings.Join(requests, ", ")))
+	}
+	return lines
+}
+
+// Function to describe who holds the vote of a node and the votes received for its requests
+func voteLines(state State) []string {
+	lines := []string{}
+	for _, r := range state.Resources {
+		switch {
+		case r.Votes > 0:
+			lines = append(lines, fmt.Sprintf("Vote on %s: free", r.Name))
+		case len(r.ReadVotes) > 0:
+			readers := []string{}
+			for _, reader := range r.ReadVotes {
+				readers = append(readers, describe(reader))
+			}
+			lines = append(lines, fmt.Sprintf("Vote on %s: shared by [%s]", r.Name, strings.Join(readers, ", ")))
+		default:
+			lines = append(lines, fmt.Sprintf("Vote on %s: given to %s in epoch %d", r.Name, describe(r.PrevReq), r.Epoch))
+		}
+		if r.Request {
+			voters := []int{}
+			for _, voter := range r.VotesReceived {
+				voters = append(voters, voter.ID)
+			}
+			lines = append(lines, fmt.Sprintf("Votes for %s: %d of %d needed, from %v", r.Name, len(r.VotesReceived), r.VotesNeeded, voters))
+		}
+	}
+	return lines
+}
@@ -0,0 +1,131 @@
+package node
+
+import (
+	"container/heap"
+	"fmt"
+	"time"
+)
+
+const (
+	// Extra time a node waits after the lease of another node should have expired, to make up for clocks that run at slightly different speeds
+	DEFAULT_SKEW_MARGIN = 500 * time.Millisecond
+	// How often a node looks for expired leases
+	LEASE_CHECK_INTERVAL = 500 * time.Millisecond
+)
+
+// Lease of another node on its request for a resource
+type Lease struct {
+	ReqTime int // Timestamp of the leased request
+	Expiry time.Time // Time after which the request is treated as released
+}
+
+// Function to renew the lease on a request of the node until the request is granted and released or cancelled.
+// The node counts its own lease from the moment it sends the renewal, so it always expires before the lease
+// seen by the voters.
+func (n *Node) renewLease(name string, reqTime int) {
+	for {
+		n.Lock.Lock()
+		r := n.resource(name)
+		if !r.Request || r.ReqTime != reqTime {
+			n.Lock.Unlock()
+			return
+		}
+		r.LeaseExpiry = time.Now().Add(n.TTL)
+		n.Clock++
+		msg := Message{Type: RENEW, ID: n.ID, IP: n.IP, ReqTime: reqTime, TTL: int(n.TTL / time.Second), Resource: name, Clock: n.Clock}
+		n.Lock.Unlock()
+
+		for i := range n.Network {
+			go func() {
+				_, err := CallByRPC(n.Network[i], "Node.ReceiveMessage", msg)
+				if err != nil {
+					fmt.Printf("[NODE-%d] Error occurred while renewing the lease on %s with node %d: %s\n", n.ID, name, i, err)
+				}
+			}()
+		}
+		time.Sleep(n.TTL / 3)
+	}
+}
+
+// Function to extend the lease of another node on its request. Must be called with the lock held.
+func (n *Node) extendLease(r *Resource, message Message) {
+	if message.TTL <= 0 || message.ID == n.ID {
+		return
+	}
+	if ended, ok := r.Cancelled[message.ID]; ok && ended >= message.ReqTime {
+		// The request was cancelled or its lease expired, so a late renewal does not bring it back
+		return
+	}
+	r.Leases[message.ID] = Lease{ReqTime: message.ReqTime, Expiry: time.Now().Add(time.Duration(message.TTL) * time.Second + n.SkewMargin)}
+}
+
+// Function to check the leases of the requesting nodes and treat every expired one as a release
+func (n *Node) watchLeases() {
+	for {
+		time.Sleep(LEASE_CHECK_INTERVAL)
+
+		n.Lock.Lock()
+		votes := []Ballot{}
+		for _, r := range n.Resources {
+			for ID, lease := range r.Leases {
+				if time.Now().After(lease.Expiry) && n.expireLease(r, ID, lease) {
+					votes = append(votes, n.grantNext(r)...)
+				}
+			}
+		}
+		n.Lock.Unlock()
+
+		// Send the votes for the next requests outside of the lock, like after a release
+		n.sendVotes(votes)
+	}
+}
+
+// Function to treat an expired lease as a release. The request is removed from the queue and the vote given
+// to it is taken back. Returns true if the vote was taken back. Must be called with the lock held.
+func (n *Node) expireLease(r *Resource, ID int, lease Lease) bool {
+	delete(r.Leases, ID)
+	r.Cancelled[ID] = lease.ReqTime
+
+	removed := false
+	for i := 0; i < r.Queue.Len(); i++ {
+		waiting := (*r.Queue)[i]
+		if waiting.ID == ID && waiting.ReqTime <= lease.ReqTime {
+			heap.Remove(r.Queue, i)
+			removed = true
+			i--
+		}
+	}
+
+	freed := false
+	for _, reader := range r.ReadVotes {
+		if reader.ID == ID && reader.ReqTime <= lease.ReqTime {
+			r.ReadVotes = Remove(r.ReadVotes, reader)
+			freed = true
+			break
+		}
+	}
+	if r.Votes == 0 && r.PrevReq.ID == ID && r.PrevReq.ReqTime <= lease.ReqTime {
+		r.Votes = 1
+		r.PrevReq = Pointer{}
+		freed = true
+	}
+	if removed || freed {
+		fmt.Printf("[NODE-%d] Lease of node %d on %s expired. Treating its request as released\n", n.ID, ID, r.Name)
+	}
+
+	// The bootstrap node stops counting a crashed node as inside the critical section
+	if n.ID == 0 {
+		n.notifyExited(ID, r.Name)
+		if ID < len(n.Finished) {
+			n.Finished[ID] = true
+		}
+	}
+	return freed
+}
+
+// Function to get the time until which the node may use a resource it holds. The zero time is returned if leases are off.
+func (n *Node) LeaseExpiry(name string) time.Time {
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	return n.resource(name).LeaseExpiry
+}
@@ -0,0 +1,27 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// How often TryLock checks whether the fencing round of a request that holds enough votes has ended
+const TRY_LOCK_POLL = 50 * time.Millisecond
+
+// Error returned by a try to acquire a lock that is held or requested by another node
+var ErrBusy = errors.New("the lock is busy")
+
+// Function to acquire the lock on a resource, giving up after the timeout
+func (n *Node) LockWithTimeout(name string, mode string, timeout time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return n.AcquireContext(ctx, name, mode)
+}
+
+// Function to acquire the lock on a resource only if it is granted right away. Returns false as soon as a node
+// queues the request instead of voting for it, in which case the request is cancelled.
+func (n *Node) TryLock(name string, mode string) (int64, bool) {
+	token, err := n.acquire(context.Background(), name, mode, true)
+	return token, err == nil
+}
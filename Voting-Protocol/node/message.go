@@ -7,4 +7,19 @@ type Message struct {
 	ReqTime int
 	Clock int
 	NumRequests int
+	K int // Number of nodes that can be in the critical section at the same time
+	Mode string // READ or WRITE access
+	NumReaders int // Number of the requesting nodes that request for READ access
+	Policy string // FAIR or WRITER_PREFERENCE
+	Timeout int // Seconds a requesting node waits for the lock before giving up, 0 to wait forever
+	TTL int // Seconds a request stays valid without being renewed, 0 to turn leases off
+	Fence int64 // Vote epoch carried by a vote, or the fencing token recorded with a voter
+	Resource string // Name of the resource the message is about
+	To int // ID of the node a VOTE or RELEASE is meant for, so that it cannot be replayed to another node
+	NumResources int // Number of resources the requesting nodes are spread over
+	ResourcesPerRequest int // Number of resources each request takes at once
+	Signer int // ID of the node that signed the message
+	Signature []byte // Ed25519 signature of the signer over the other fields, empty if signing is off
+	Certificates []VoteCertificate // Certificate of a vote, or the certificates of the votes of a writer sent with its fencing token
+	Secret string // Shared secret of the admin service, only set on admin calls
 }
\ No newline at end of file
@@ -1,11 +1,21 @@
 package node
 
 import (
+	"common/address"
+	"common/admin"
+	"common/inspect"
+	"common/mux"
+	"common/safety"
+	"common/trace"
 	"container/heap"
+	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/rpc"
 	"os"
+	"slices"
 	"sync"
 	"time"
 )
@@ -13,249 +23,765 @@ import (
 type Node struct {
 	ID    int
 	IP    string
-	VotesReceived []Pointer // List of nodes that have voted for the node
-	Votes int // Number of votes the node has to send to other nodes
+	Bind string // Address the node listens on, IP if empty
+	Bootstrap string // Address of the bootstrap node
+	Resources map[string]*Resource // State of the lock on every resource the node has seen
+	Requested []string // Resources requested by the node
+	K int // Number of nodes that can be in the critical section at the same time
 	Finished []bool
-	PrevReq Pointer
-	Queue *PriorityQueue // contains all the nodes that have requested for the critical section after the vote from the node was sent to another node.
+	Mode string // READ or WRITE access requested by the node
+	Policy string // FAIR or WRITER_PREFERENCE
+	Timeout time.Duration // How long the node waits for the lock before giving up, 0 to wait forever
+	TTL time.Duration // How long a request stays valid without being renewed, 0 to turn leases off
+	SkewMargin time.Duration // Extra time the node waits before treating the lease of another node as expired
+	Storage string // Address of the fenced storage server written to by writers in the critical section, empty for none
 	Network map[int]string // Contains the list of nodes in the network
 	Clock int
 	Request bool // whether the node should request for the critical section
-	isFinished bool
-	ReqTime int
+	Equivocate bool // Votes for two writers at the same time, to test the detection of equivocating voters
+	Safety safety.Checker // Only used by the bootstrap node
+	Equivocators map[int]bool // Voters that were proven to vote for two writers with the same vote epoch, only used by the bootstrap node
+	Tracer trace.Tracer
+	Gate inspect.Gate // Holds back the messages from other nodes while the node is paused and keeps track of the messages in flight
 	Lock sync.Mutex
 }
 
 const (
-	LOCALHOST = "127.0.0.1:"
 	ACK = "ACK"
 	DENY = "DENY"
 	VOTE = "VOTE"
 	REQUEST = "REQUEST"	
 	RESCIND_VOTE = "RESCIND_VOTE"
 	RELEASE = "RELEASE"
+	CANCEL = "CANCEL"
+	RENEW = "RENEW"
+	FENCE = "FENCE"
+	PROOF = "PROOF"
+	READ = "READ"
+	WRITE = "WRITE"
+	FAIR = "FAIR"
+	WRITER_PREFERENCE = "WRITER_PREFERENCE"
+)
+
+// Delays that slow the protocol down so that its output can be followed
+var (
+	MessageDelay = 1 * time.Second // Time a node waits before handling a message
+	CSDuration = 2 * time.Second // Time a node spends in the critical section
 )
 
 // Function to start the RPC server
 func (n *Node) StartRPCServer() {
 	rpc.Register(n)
+	rpc.RegisterName(admin.SERVICE, &Admin{n: n})
 
-	listener, err := net.Listen("tcp", n.IP)
+	listener, err := address.Listen(n.listenAddress())
 	if err != nil {
 		fmt.Printf("[NODE-%d] could not start listening: %s\n", n.ID, err)
 		os.Exit(1)
 	}
 	defer listener.Close()
+	if Transport == GRPC_TRANSPORT {
+		// The node keeps serving net/rpc on the same address, for the dashboard and the calls whose reply is not a Message
+		grpcConns, rpcConns := mux.Split(listener, serverTLS)
+		go n.startGRPCServer(grpcConns)
+		listener = rpcConns
+	} else if serverTLS != nil {
+		listener = tls.NewListener(listener, serverTLS)
+	}
 
 	fmt.Printf("[NODE-%d] Node is running on %s\n", n.ID, n.IP)
 
 	for {
 		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
 		if err != nil {
 			fmt.Printf("[NODE-%d] accept error: %s\n", n.ID, err)
 			continue
 		}
-		go rpc.ServeConn(conn)
+		go n.serveConn(conn)
 	}
 }
 
-func (n *Node) StartRequestProcess(message Message, reply *Message) error {
+func (n *Node) startRequestProcess(message Message, reply *Message) error {
+	if err := n.verify("StartRequestProcess", message); err != nil {
+		return err
+	}
 	if n.Request {
+		ctx := context.Background()
+		if n.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, n.Timeout)
+			defer cancel()
+		}
+
+		_, err := n.AcquireAllContext(ctx, n.Requested, n.Mode)
+		if err == context.DeadlineExceeded {
+			fmt.Printf("[NODE-%d] Gave up on %v after waiting for %v\n", n.ID, n.Requested, n.Timeout)
+			n.Request = false
+			_, err = CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID})
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		n.CriticalSection(n.Requested...)
+		n.ReleaseAll(n.Requested)
+		n.Request = false // Reset the flag
+	}
+	return nil
+}
 
+// Function to acquire the lock on a resource. Blocks until the node holds enough votes and returns the fencing token of the entry.
+func (n *Node) Acquire(name string, mode string) (int64, error) {
+	return n.AcquireContext(context.Background(), name, mode)
+}
+
+// Function to acquire the lock on a resource until the node holds enough votes or the context is done. A cancelled
+// request is removed from the queues of the other nodes and the votes received for it are returned.
+func (n *Node) AcquireContext(ctx context.Context, name string, mode string) (int64, error) {
+	return n.acquire(ctx, name, mode, false)
+}
+
+// Function to request the lock on a resource until the node holds enough votes or the context is done. With try, the
+// request is cancelled and ErrBusy is returned as soon as a node queues it instead of voting, or if the node does not
+// hold enough votes once every node has answered.
+func (n *Node) acquire(ctx context.Context, name string, mode string, try bool) (int64, error) {
+	n.Lock.Lock()
+	r := n.resource(name)
+	if r.Request {
+		n.Lock.Unlock()
+		return 0, fmt.Errorf("[NODE-%d] Already requesting %s", n.ID, name)
+	}
+	r.Request = true
+	r.Mode = mode
+	r.Granted = make(chan bool, 1)
+	granted := r.Granted
+	n.trace(WAIT, name)
+
+	n.Clock++
+	r.ReqTime = n.Clock
+	reqTime := r.ReqTime
+	n.Lock.Unlock()
+
+	if n.TTL > 0 {
+		go n.renewLease(name, reqTime)
+	}
+
+	// Send a CS request to all the nodes in the network
+	queued := make(chan bool, len(n.Network))
+	for i := range n.Network {
+		n.Lock.Lock()
 		n.Clock++
-		n.ReqTime = n.Clock
-
-		// Send a CS request to all the nodes in the network
-		for i := range n.Network {
-			// concurrently start requesting the critical section
-			go func() {
-				fmt.Printf("[NODE-%d] Sending a request to node %d\n", n.ID, i)
-				_, err := CallByRPC(n.Network[i], "Node.ReceiveMessage", Message{Type: REQUEST, ID: n.ID, IP: n.IP, ReqTime: n.ReqTime, Clock: n.Clock})
-				if err != nil {
-					fmt.Printf("[NODE-%d] Error occurred while sending a request to node %d: %s\n", n.ID, i, err)
-				}
-			}()
-			n.Clock++
+		msg := Message{Type: REQUEST, ID: n.ID, IP: n.IP, ReqTime: reqTime, Mode: mode, TTL: int(n.TTL / time.Second), Resource: name, Clock: n.Clock}
+		n.Lock.Unlock()
+
+		// concurrently start requesting the critical section
+		go func() {
+			fmt.Printf("[NODE-%d] Sending a request for %s to node %d\n", n.ID, name, i)
+			reply, err := CallByRPC(n.Network[i], "Node.ReceiveMessage", msg)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while sending a request to node %d: %s\n", n.ID, i, err)
+			}
+			queued <- reply.Type == DENY
+		}()
+	}
+
+	if try && !n.tryGranted(name, granted, queued) {
+		if n.cancel(name) {
+			return 0, ErrBusy
+		}
+		// The lock was granted before the request could be cancelled
+	}
+
+	select {
+	case <-granted:
+	case <-ctx.Done():
+		if n.cancel(name) {
+			return 0, ctx.Err()
+		}
+		// The lock was granted before the request could be cancelled
+	}
+	n.trace(ACQUIRED, name)
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	return r.Fence, nil
+}
+
+// Function to find out if a request is granted without waiting for other requests. A node votes before it answers
+// the request, so once every node has answered, the node either holds enough votes or is busy with the fencing round.
+func (n *Node) tryGranted(name string, granted chan bool, queued chan bool) bool {
+	for range n.Network {
+		if <-queued {
+			fmt.Printf("[NODE-%d] A node queued the request for %s, so the lock is busy\n", n.ID, name)
+			return false
+		}
+	}
+	for {
+		n.Lock.Lock()
+		r := n.resource(name)
+		inCS, entering := r.InCS, r.Entering
+		n.Lock.Unlock()
+		if inCS {
+			return true
 		}
+		if !entering {
+			return false
+		}
+		select {
+		case <-granted:
+			// Put the grant back for the caller
+			granted <- true
+			return true
+		case <-time.After(TRY_LOCK_POLL):
+		}
+	}
+}
+
+// Function to withdraw a request that has not been granted yet. Returns false if the lock was already granted.
+func (n *Node) cancel(name string) bool {
+	n.Lock.Lock()
+	r := n.resource(name)
+	if r.InCS {
+		n.Lock.Unlock()
+		return false
 	}
+	r.Request = false
+	reqTime := r.ReqTime
+	n.Lock.Unlock()
+
+	fmt.Printf("[NODE-%d] Cancelling the request for %s with timestamp %d\n", n.ID, name, reqTime)
+	n.trace(CANCELLED, name)
+
+	// Remove the request from the queues of the other nodes
+	for i := range n.Network {
+		n.Lock.Lock()
+		n.Clock++
+		msg := Message{Type: CANCEL, ID: n.ID, IP: n.IP, ReqTime: reqTime, Resource: name, Clock: n.Clock}
+		n.Lock.Unlock()
+
+		go func() {
+			_, err := CallByRPC(n.Network[i], "Node.ReceiveMessage", msg)
+			if err != nil {
+				fmt.Printf("[NODE-%d] Error occurred while sending a cancel to node %d: %s\n", n.ID, i, err)
+			}
+		}()
+	}
+
+	// Return the votes received so far. Votes that are still on their way are returned as soon as they arrive.
+	n.sendRelease(name)
+	return true
+}
+
+// Function to release the lock on a resource and return the votes
+func (n *Node) Release(name string) {
+	n.Lock.Lock()
+	n.release(n.resource(name))
+	n.Lock.Unlock()
+
+	n.sendRelease(name)
+}
+
+// Function to release the lock on a resource only if the node holds it with the fencing token. The token is checked
+// and the request reset under one lock, so that a client that lost the lock cannot release the entry of a newer holder.
+func (n *Node) releaseIfFence(name string, token int64) error {
+	n.Lock.Lock()
+	r := n.resource(name)
+	if !r.InCS {
+		n.Lock.Unlock()
+		return fmt.Errorf("node %d does not hold %s", n.ID, name)
+	}
+	if r.Fence != token {
+		n.Lock.Unlock()
+		return fmt.Errorf("%s is not held with the fencing token %d", name, token)
+	}
+	n.release(r)
+	n.Lock.Unlock()
+
+	n.sendRelease(name)
 	return nil
 }
 
+// Function to reset the node's request for a resource it releases, warning if its lease expired before.
+// Must be called with the lock held.
+func (n *Node) release(r *Resource) {
+	if !r.LeaseExpiry.IsZero() && time.Now().After(r.LeaseExpiry) {
+		fmt.Printf("[NODE-%d] WARNING: the lease on %s expired before the release, so other nodes may have entered the critical section\n", n.ID, r.Name)
+	}
+	if r.InCS {
+		n.trace(RELEASED, r.Name)
+	}
+	r.Request = false
+	r.InCS = false
+	r.LeaseExpiry = time.Time{}
+}
+
 // Dummy critical section function
-func (n *Node) CriticalSection() {
+func (n *Node) CriticalSection(names ...string) {
+	// Notify Bootstrap node when entering the critical section
+	for _, name := range names {
+		n.Lock.Lock()
+		mode := n.resource(name).Mode
+		n.Lock.Unlock()
+
+		_, err := CallByRPC(n.Bootstrap, "Node.NotifyEntered", Message{ID: n.ID, Mode: mode, Resource: name})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+		}
+	}
+
 	// Simulate entering the critical section
-	fmt.Printf("[NODE-%d] Entering the critical section\n", n.ID)
-	time.Sleep(2 * time.Second)
-	fmt.Printf("[NODE-%d] Completed the critical section\n", n.ID)
+	fmt.Printf("[NODE-%d] Entering the critical section of %v for %s access\n", n.ID, names, n.Mode)
+	time.Sleep(CSDuration)
+
+	// Writers write to the fenced storage server at the end of the critical section
+	for _, name := range names {
+		n.Lock.Lock()
+		writer := n.resource(name).Mode == WRITE
+		n.Lock.Unlock()
+		if n.Storage == "" || !writer {
+			continue
+		}
+		_, err := n.WriteStorage(name, fmt.Sprintf("written by node %d", n.ID))
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while writing to the storage server: %s\n", n.ID, err)
+		}
+	}
+	fmt.Printf("[NODE-%d] Completed the critical section of %v\n", n.ID, names)
 
 	// Notify Bootstrap node when the critical section is completed
+	n.Lock.Lock()
 	n.Clock++
-	n.Request = false // Reset the flag
-	n.isFinished = true
+	n.Lock.Unlock()
 
-	_, err := CallByRPC(LOCALHOST + "8000", "Node.NotifyFinished", Message{ID: n.ID})
-	if err != nil {
-		fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+	for _, name := range names {
+		_, err := CallByRPC(n.Bootstrap, "Node.NotifyFinished", Message{ID: n.ID, Resource: name})
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while notifying the bootstrap node: %s\n", n.ID, err)
+		}
 	}
 }
 
 func (n *Node)NotifyFinished(message Message, reply *Message) error {
-	n.Finished[message.ID] = true
+	if err := n.verify("NotifyFinished", message); err != nil {
+		return err
+	}
+	n.notifyExited(message.ID, message.Resource)
+	n.Lock.Lock()
+	if message.ID < len(n.Finished) {
+		n.Finished[message.ID] = true
+	}
+	n.Lock.Unlock()
 	return nil
 }
 
 // Handle the different types of messages
 func (n *Node)ReceiveMessage(message Message, reply *Message) error {
-	n.Clock = max(n.Clock, message.Clock) + 1
+	if err := n.verify("ReceiveMessage", message); err != nil {
+		return err
+	}
+	if (message.Type == VOTE || message.Type == RELEASE) && message.To != n.ID {
+		fmt.Printf("[NODE-%d] Rejected a %s of node %d meant for node %d\n", n.ID, message.Type, message.ID, message.To)
+		return fmt.Errorf("%s of node %d is meant for node %d, not node %d", message.Type, message.ID, message.To, n.ID)
+	}
+	n.deliver(message.Type, message)
 
-	time.Sleep(1 * time.Second)
+	n.Lock.Lock()
+	n.Clock = max(n.Clock, message.Clock) + 1
+	r := n.resource(message.Resource)
+	n.Lock.Unlock()
 
 	switch message.Type {
 	case REQUEST:
-		fmt.Printf("[NODE-%d] Received a request from node %d\n", n.ID, message.ID)
-		if n.Votes > 0 {
-
-			n.Votes-- // Voting for the requesting node
-			n.PrevReq = Pointer{ID: message.ID, IP: message.IP, ReqTime: message.ReqTime}
-
-			n.Clock++
-			fmt.Printf("[NODE-%d] Sending a vote to node %d\n", n.ID, message.ID)
-			_, err := CallByRPC(message.IP, "Node.ReceiveMessage", Message{Type: VOTE, ID: n.ID, IP: n.IP, Clock: n.Clock})
-			if err != nil {
-				fmt.Printf("[NODE-%d] Error occurred while sending a vote to node %d: %s\n", n.ID, message.ID, err)
-			}
+		n.Lock.Lock()
+		ended, cancelled := r.Cancelled[message.ID]
+		cancelled = cancelled && ended >= message.ReqTime
+		if cancelled {
+			n.Lock.Unlock()
+			// The cancel overtook the request or the lease of the request already expired
+			fmt.Printf("[NODE-%d] Ignoring the ended request for %s from node %d\n", n.ID, message.Resource, message.ID)
+			break
+		}
+		n.extendLease(r, message)
+
+		request := Pointer{ID: message.ID, IP: message.IP, ReqTime: message.ReqTime, Mode: message.Mode, Rank: n.rank(message.Mode)}
+		fmt.Printf("[NODE-%d] Received a %s request for %s from node %d\n", n.ID, message.Mode, message.Resource, message.ID)
+		if n.Equivocate && request.Mode == WRITE && r.Votes == 0 {
+			vote := n.equivocate(r, request)
+			n.Lock.Unlock()
+			n.sendVotes([]Ballot{vote})
 			return nil
-		} else {
-			heap.Push(n.Queue, Pointer{ID: message.ID, IP: message.IP, ReqTime: message.ReqTime})
-			fmt.Printf("[NODE-%d] Added node %d to the queue. New Queue: %v\n", n.ID, message.ID, n.Queue)
-			
-			if n.PrevReq.ReqTime > message.ReqTime {
-				n.RescindVote(message)
-
-			} else if n.PrevReq.ReqTime == message.ReqTime {
-				if n.PrevReq.ID > message.ID {
-					n.RescindVote(message)
+		}
+		if n.canVote(r, request) {
+			vote := n.vote(r, request)
+			n.Lock.Unlock()
+			n.sendVotes([]Ballot{vote})
+			return nil
+		}
+		heap.Push(r.Queue, request)
+		fmt.Printf("[NODE-%d] Added node %d to the queue of %s. New Queue: %v\n", n.ID, message.ID, r.Name, r.Queue)
+		// Tell the requesting node that it has to wait for the vote, so that a TryLock can give up right away
+		*reply = Message{Type: DENY}
+
+		rescind := []Pointer{}
+		if r.Votes == 0 {
+			if n.precedes(request, r.PrevReq) {
+				rescind = append(rescind, r.PrevReq)
+			}
+		} else if request.Mode == WRITE {
+			// Take back the votes of the readers that came after the writer so that it does not wait for them
+			for _, reader := range r.ReadVotes {
+				if n.precedes(request, reader) {
+					rescind = append(rescind, reader)
 				}
-			} 
+			}
 		}
+		n.Lock.Unlock()
+
+		// The holders are asked outside of the lock, since taking a vote back releases it to the node itself
+		for _, holder := range rescind {
+			n.RescindVote(r, holder, message)
+		}
+		return nil
 	
 		// For some reason the releasing of all the votes is not taking place, moreover, the addition of another vote messes it up
 		// Probably some lock issue
 		// Maybe make the release a go routine
 	case VOTE:
 
-		n.VotesReceived = append(n.VotesReceived, Pointer{ID: message.ID, IP: message.IP})
-		fmt.Printf("[NODE-%d] Received a vote from node %d. Votes received: %v\n", n.ID, message.ID, n.VotesReceived)
-
-		// Check if the node has received a majority of the votes
 		n.Lock.Lock()
-		if !n.isFinished && (len(n.VotesReceived) >= ((len(n.Network) + 1) / 2) + 1) { // Majority of the votes received
-			fmt.Printf("[NODE-%d] Majority of the votes received. Entering the critical section\n", n.ID)
-			n.CriticalSection() // Execute the CS
-
-			// concurrently release all the votes
-			go n.sendRelease()
-		} else {
-			if n.isFinished { // Send release to all the incoming votes which are not yet released after execution of CS
-				go n.sendRelease()
+		if r.Request {
+			var err error
+			if message.ReqTime != r.ReqTime {
+				err = fmt.Errorf("vote of node %d was cast for request %d, not for request %d", message.ID, message.ReqTime, r.ReqTime)
+			} else {
+				err = n.checkVote(r, message)
 			}
+			if err != nil {
+				fmt.Printf("[NODE-%d] Rejected the vote of node %d for %s: %s\n", n.ID, message.ID, r.Name, err)
+				n.Clock++
+				release := Message{Type: RELEASE, ID: n.ID, IP: n.IP, ReqTime: message.ReqTime, To: message.ID, Resource: r.Name, Clock: n.Clock}
+				n.Lock.Unlock()
+
+				// Give the vote back so that the voter does not wait for a release that never comes
+				go func() {
+					_, err := CallByRPC(message.IP, "Node.ReceiveMessage", release)
+					if err != nil {
+						fmt.Printf("[NODE-%d] Error occurred while sending a release to node %d: %s\n", n.ID, message.ID, err)
+					}
+				}()
+				*reply = Message{Type: DENY}
+				return nil
+			}
+			if len(message.Certificates) == 1 {
+				r.Certificates[message.ID] = message.Certificates[0]
+			}
+		}
+		r.VotesReceived = append(r.VotesReceived, Pointer{ID: message.ID, IP: message.IP, ReqTime: message.ReqTime, Epoch: message.Fence})
+		fmt.Printf("[NODE-%d] Received a vote for %s from node %d. Votes received: %v\n", n.ID, r.Name, message.ID, r.VotesReceived)
+
+		if !r.Request { // Send release to the incoming votes which are not yet released after the resource was released
+			go n.sendRelease(r.Name)
+		} else if !r.InCS && !r.Entering && len(r.VotesReceived) >= n.votesNeeded(r) {
+			// Check if the node has received enough votes
+			r.Entering = true
+			go n.enter(r.Name)
 		}
 		n.Lock.Unlock()	
 
 	case RELEASE:
 
 		fmt.Printf("[NODE-%d] Received a release from node %d\n", n.ID, message.ID)
-		n.Votes = 1
-		n.PrevReq = Pointer{}
-
-		if n.Queue.Len() > 0 {
-			n.Votes-- // Voting for the requesting node
-			n.PrevReq = heap.Pop(n.Queue).(Pointer)
-			n.Clock++
+		n.Lock.Lock()
+		reader := slices.IndexFunc(r.ReadVotes, func(p Pointer) bool { return p.ID == message.ID && p.ReqTime == message.ReqTime })
+		if reader >= 0 {
+			r.ReadVotes = slices.Delete(r.ReadVotes, reader, reader + 1)
+		} else if r.Votes == 0 && r.PrevReq.ID == message.ID && r.PrevReq.ReqTime == message.ReqTime {
+			// Only the holder frees the vote, since the vote is taken back without a release once the lease of the holder expires
+			r.Votes = 1
+			r.PrevReq = Pointer{}
+		} else {
+			// The release belongs to a vote the node has already taken back, so it must not free the current vote
+			fmt.Printf("[NODE-%d] Ignoring the release of node %d for %s with timestamp %d, which matches no vote of the node\n", n.ID, message.ID, r.Name, message.ReqTime)
+		}
+		votes := n.grantNext(r)
+		n.Lock.Unlock()
+		n.sendVotes(votes)
+	
+	case RENEW:
+		n.Lock.Lock()
+		n.extendLease(r, message)
+		n.Lock.Unlock()
 
-			fmt.Printf("[NODE-%d] Sending a vote to node %d\n", n.ID, n.PrevReq.ID)
-			_, err := CallByRPC(n.PrevReq.IP, "Node.ReceiveMessage", Message{Type: VOTE, ID: n.ID, IP: n.IP, Clock: n.Clock})
-			if err != nil {
-				fmt.Printf("[NODE-%d] Error occurred while sending a vote to node %d: %s\n", n.ID, n.PrevReq.ID, err)
+	case CANCEL:
+		n.Lock.Lock()
+		r.Cancelled[message.ID] = message.ReqTime
+		delete(r.Leases, message.ID)
+		for i := 0; i < r.Queue.Len(); i++ {
+			waiting := (*r.Queue)[i]
+			if waiting.ID == message.ID && waiting.ReqTime == message.ReqTime {
+				heap.Remove(r.Queue, i)
+				break
 			}
 		}
-	
+		fmt.Printf("[NODE-%d] Node %d cancelled its request for %s. New Queue: %v\n", n.ID, message.ID, message.Resource, r.Queue)
+		n.Lock.Unlock()
+
+	case FENCE:
+		n.Lock.Lock()
+		defer n.Lock.Unlock()
+
+		if r.Votes != 0 || r.PrevReq.ID != message.ID {
+			// The vote was taken back after the lease of the node expired
+			fmt.Printf("[NODE-%d] Node %d no longer holds the vote for %s. Denying its fencing token %d\n", n.ID, message.ID, r.Name, message.Fence)
+			*reply = Message{Type: DENY}
+			return nil
+		}
+		if publicKeys != nil && n.checkProof(r, message.ID, message.ReqTime, message.Certificates) < n.writeVotesNeeded() {
+			fmt.Printf("[NODE-%d] Node %d did not prove that it holds enough votes for %s. Denying its fencing token %d\n", n.ID, message.ID, r.Name, message.Fence)
+			*reply = Message{Type: DENY}
+			return nil
+		}
+		r.Epoch = max(r.Epoch, message.Fence)
+
+	case PROOF:
+		n.Lock.Lock()
+		n.checkProof(r, message.ID, message.ReqTime, message.Certificates)
+		n.Lock.Unlock()
+
 	case RESCIND_VOTE:
 		fmt.Printf("[NODE-%d] Received a rescind vote from node %d\n", n.ID, message.ID)
+
+		n.Lock.Lock()
+		defer n.Lock.Unlock()
 	
-		if n.isFinished {
+		if r.InCS || r.Entering {
 			fmt.Printf("[NODE-%d] The node %d has already entered the critical section. Sending a DENY message for the rescind request\n", n.ID, message.ID)
 			*reply = Message{Type: DENY}
 			return nil
 		}
 
 		element := Pointer{ID: message.ID, IP: message.IP}
-		if !Contains(n.VotesReceived, element) {
+		if !Contains(r.VotesReceived, element) {
 			fmt.Printf("[NODE-%d] Current node does not contain the node %d in the votes received list\n", n.ID, message.ID)
 			*reply = Message{Type: DENY}
 			return nil
 		}
 		
 		// Remove the node from the votes received slice
-		n.VotesReceived = Remove(n.VotesReceived, element)	
-		fmt.Printf("[NODE-%d] Removed node %d from the votes received list. New list: %v\n", n.ID, message.ID, n.VotesReceived)	
+		r.VotesReceived = Remove(r.VotesReceived, element)	
+		fmt.Printf("[NODE-%d] Removed node %d from the votes received list of %s. New list: %v\n", n.ID, message.ID, r.Name, r.VotesReceived)	
 	}
 	*reply = Message{Type: ACK} 
 	return nil
 }
 
+// Number of votes needed to enter the critical section. Each of the N nodes has one vote, so with more
+// than N / (k + 1) votes needed, at most k nodes can collect enough votes at the same time.
+// With k = 1 this is a majority of the votes.
+// Readers need enough votes to overlap with every writer's votes, so a reader and a writer never hold
+// enough votes at the same time.
+func (n *Node) votesNeeded(r *Resource) int {
+	writeVotes := n.writeVotesNeeded()
+	if r.Mode == READ {
+		return len(n.Network) + 1 - writeVotes + 1
+	}
+	return writeVotes
+}
+
+// Number of votes a writer needs to enter the critical section
+func (n *Node) writeVotesNeeded() int {
+	return (len(n.Network) + 1) / (n.K + 1) + 1
+}
+
+// Function to check if a request can be voted for right away. Readers share the vote unless a writer
+// that should go first is waiting, while writers need the vote for themselves.
+func (n *Node) canVote(r *Resource, request Pointer) bool {
+	if r.Votes == 0 {
+		return false
+	}
+	if request.Mode == WRITE {
+		return len(r.ReadVotes) == 0
+	}
+	for _, waiting := range *r.Queue {
+		if waiting.Mode == WRITE && n.precedes(waiting, request) {
+			return false
+		}
+	}
+	return true
+}
+
+// Vote of the node for a request. It is cast under the lock and sent once the lock is released, since the
+// requesting node may be waiting for the lock of this node at the same time.
+type Ballot struct {
+	Request Pointer
+	Message Message
+}
+
+// Function to vote for the requests at the head of the queue. Consecutive readers all get the vote.
+// Returns the votes to send once the lock is released. Must be called with the lock held.
+func (n *Node) grantNext(r *Resource) []Ballot {
+	votes := []Ballot{}
+	for r.Queue.Len() > 0 && r.Votes > 0 {
+		head := r.Queue.Peek().(Pointer)
+		if head.Mode == WRITE && len(r.ReadVotes) > 0 {
+			break
+		}
+		heap.Pop(r.Queue)
+		votes = append(votes, n.vote(r, head))
+	}
+	return votes
+}
+
+// Function to give the vote of the node to a request. Returns the vote to send once the lock is released.
+// Must be called with the lock held.
+func (n *Node) vote(r *Resource, request Pointer) Ballot {
+	if request.Mode == READ {
+		r.ReadVotes = append(r.ReadVotes, request)
+	} else {
+		r.Votes-- // Voting for the requesting node
+		r.PrevReq = request
+		r.Epoch++
+	}
+
+	n.Clock++
+	fmt.Printf("[NODE-%d] Sending a vote for %s to node %d\n", n.ID, r.Name, request.ID)
+	return Ballot{Request: request, Message: Message{Type: VOTE, ID: n.ID, IP: n.IP, ReqTime: request.ReqTime, To: request.ID, Fence: r.Epoch, Resource: r.Name, Clock: n.Clock, Certificates: n.certify(r, request)}}
+}
+
+// Function to send the votes cast by the node. Must be called without the lock held.
+func (n *Node) sendVotes(votes []Ballot) {
+	for _, vote := range votes {
+		_, err := CallByRPC(vote.Request.IP, "Node.ReceiveMessage", vote.Message)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Error occurred while sending a vote to node %d: %s\n", n.ID, vote.Request.ID, err)
+		}
+	}
+}
+
+// Function to vote for a writer while the vote of the node is held by another writer, with the same vote epoch.
+// Only used to test the detection of equivocating voters. Must be called with the lock held.
+func (n *Node) equivocate(r *Resource, request Pointer) Ballot {
+	n.Clock++
+	fmt.Printf("[NODE-%d] Equivocating: sending a second vote for %s with epoch %d to node %d\n", n.ID, r.Name, r.Epoch, request.ID)
+	return Ballot{Request: request, Message: Message{Type: VOTE, ID: n.ID, IP: n.IP, ReqTime: request.ReqTime, To: request.ID, Fence: r.Epoch, Resource: r.Name, Clock: n.Clock, Certificates: n.certify(r, request)}}
+}
+
+// Function to check if the first request is served before the second one under the policy of the lock
+func (n *Node) precedes(a Pointer, b Pointer) bool {
+	if a.Rank != b.Rank {
+		return a.Rank < b.Rank
+	}
+	if a.ReqTime != b.ReqTime {
+		return a.ReqTime < b.ReqTime
+	}
+	return a.ID < b.ID
+}
+
+// Readers are ranked after writers when the lock prefers writers
+func (n *Node) rank(mode string) int {
+	if n.Policy == WRITER_PREFERENCE && mode == READ {
+		return 1
+	}
+	return 0
+}
+
 // Function to add a new node to the network
-func (n *Node)AddNode(message Message, reply *Message) error {
+func (n *Node)addNode(message Message, reply *Message) error {
+	if err := n.verify("AddNode", message); err != nil {
+		return err
+	}
 	n.Network[message.ID] = message.IP
 	*reply = Message{Type: ACK}
 	return nil
 }
 
-func (n *Node) sendRelease() {
-	votesList := n.VotesReceived // create a copy so that any changes in length do not affect the loop
-	n.VotesReceived = []Pointer{} // Reset the votes received list
+// Function to return all the votes received for a resource
+func (n *Node) sendRelease(name string) {
+	n.Lock.Lock()
+	r := n.resource(name)
+	votesList := r.VotesReceived // create a copy so that any changes in length do not affect the loop
+	r.VotesReceived = []Pointer{} // Reset the votes received list
+	n.Lock.Unlock()
+
 	for i := range votesList {
+		n.Lock.Lock()
 		n.Clock++
-		fmt.Printf("[NODE-%d] Sending a release to node %d\n", n.ID, votesList[i].ID)
-		_, err := CallByRPC(votesList[i].IP, "Node.ReceiveMessage", Message{Type: RELEASE, ID: n.ID, Clock: n.Clock})
+		msg := Message{Type: RELEASE, ID: n.ID, IP: n.IP, ReqTime: votesList[i].ReqTime, To: votesList[i].ID, Resource: name, Clock: n.Clock}
+		n.Lock.Unlock()
+
+		fmt.Printf("[NODE-%d] Sending a release for %s to node %d\n", n.ID, name, votesList[i].ID)
+		_, err := CallByRPC(votesList[i].IP, "Node.ReceiveMessage", msg)
 		if err != nil {
 			fmt.Printf("[NODE-%d] Error occurred while sending a release to node %d: %s\n", n.ID, votesList[i].ID, err)
 		}
 	}
 }
 
-// Function to rescind the vote
-func (n *Node)RescindVote(message Message) {
+// Function to rescind the vote given to a holder. Must be called without the lock held.
+func (n *Node)RescindVote(r *Resource, holder Pointer, message Message) {
 
-	fmt.Printf("[NODE-%d] Sending a rescind vote to node %d to vote for node %d instead.\n", n.ID, n.PrevReq.ID, message.ID)
+	fmt.Printf("[NODE-%d] Sending a rescind vote to node %d to vote for node %d instead.\n", n.ID, holder.ID, message.ID)
 
+	n.Lock.Lock()
 	n.Clock++
-	reply, err := CallByRPC(n.PrevReq.IP, "Node.ReceiveMessage", Message{Type: RESCIND_VOTE, ID: n.ID, IP: n.IP, Clock: n.Clock})
+	rescind := Message{Type: RESCIND_VOTE, ID: n.ID, IP: n.IP, Resource: r.Name, Clock: n.Clock}
+	n.Lock.Unlock()
+	reply, err := CallByRPC(holder.IP, "Node.ReceiveMessage", rescind)
 	if err != nil {
 		fmt.Printf("[NODE-%d] Error occurred while sending a rescind vote to node %d: %s\n", n.ID, message.ID, err)
 	}
 
 	if reply.Type == ACK {// If the previous node has accepted the RESCIND_VOTE message
 		
-		// Store a copy of the previous request
-		prevRequest := n.PrevReq
+		n.Lock.Lock()
 		n.Clock++
-		_, err := CallByRPC(n.IP, "Node.ReceiveMessage", Message{Type: RELEASE, ID: n.PrevReq.ID, IP: n.PrevReq.IP, Clock: n.Clock})
+		release := Message{Type: RELEASE, ID: holder.ID, IP: holder.IP, ReqTime: holder.ReqTime, To: n.ID, Resource: r.Name, Clock: n.Clock}
+		n.Lock.Unlock()
+		_, err := CallByRPC(n.IP, "Node.ReceiveMessage", release)
 		if err != nil {
 			fmt.Printf("[NODE-%d] Error occurred while sending a release to node %d: %s\n", n.ID, message.ID, err)
 		}
 
-		// Add the PrevReq to the queue after the release message has been sent
-		heap.Push(n.Queue, Pointer{ID: prevRequest.ID, IP: prevRequest.IP, ReqTime: prevRequest.ReqTime})
-		fmt.Printf("[NODE-%d] Added node %d to the queue. New Queue: %v\n", n.ID, prevRequest.ID, n.Queue)
+		// Add the holder to the queue after the release message has been sent
+		n.Lock.Lock()
+		heap.Push(r.Queue, holder)
+		fmt.Printf("[NODE-%d] Added node %d to the queue of %s. New Queue: %v\n", n.ID, holder.ID, r.Name, r.Queue)
+		n.Lock.Unlock()
 	}
 }
 
 // Function to decide whether the node requests for vote or not
-func (n *Node) SetRequesting(message Message, reply *Message) error {
+func (n *Node) setRequesting(message Message, reply *Message) error {
+	if err := n.verify("SetRequesting", message); err != nil {
+		return err
+	}
+	n.K = max(message.K, 1)
+	n.Policy = FAIR
+	if message.Policy == WRITER_PREFERENCE {
+		n.Policy = WRITER_PREFERENCE
+	}
+	n.Timeout = time.Duration(message.Timeout) * time.Second
+	n.TTL = time.Duration(message.TTL) * time.Second
+	if n.TTL > 0 {
+		go n.watchLeases()
+	}
+	n.Mode = WRITE
+	if n.ID < message.NumReaders {
+		n.Mode = READ
+	}
+
+	numResources := max(message.NumResources, 1)
+	n.Requested = []string{}
+	for i := 0; i < min(max(message.ResourcesPerRequest, 1), numResources); i++ {
+		n.Requested = append(n.Requested, ResourceName((n.ID + i) % numResources))
+	}
+
 	n.Request = n.ID < message.NumRequests
 	if n.Request {
-		fmt.Printf("[NODE-%d] Node will request for the critical section\n", n.ID)
+		fmt.Printf("[NODE-%d] Node will request for %s access to %v\n", n.ID, n.Mode, n.Requested)
 	} else {
 		fmt.Printf("[NODE-%d] Node will not request for the critical section\n", n.ID)
 	}
@@ -266,7 +792,16 @@ func (n *Node) SetRequesting(message Message, reply *Message) error {
 
 // Utility function to call RPC methods
 func CallByRPC(IP string, method string, message Message) (Message, error) {
-	client, err := rpc.Dial("tcp", IP)
+	if admin.IsAdmin(method) {
+		message.Secret = admin.Secret
+	}
+	if signingKey != nil {
+		sign(&message)
+	}
+	if Transport == GRPC_TRANSPORT {
+		return callByGRPC(IP, method, message)
+	}
+	client, err := dial(IP)
 	if err != nil {
 		return Message{}, fmt.Errorf("error in dialing: %s", err)
 	}
@@ -300,4 +835,12 @@ func Contains(slice []Pointer, element Pointer) bool {
 		}
 	}
 	return false
-}
\ No newline at end of file
+}
+
+// Function to get the address the node listens on
+func (n *Node) listenAddress() string {
+	if n.Bind != "" {
+		return n.Bind
+	}
+	return n.IP
+}
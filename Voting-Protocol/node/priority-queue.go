@@ -4,6 +4,9 @@ type Pointer struct {
 	ID int
 	IP string
 	ReqTime int
+	Mode string // READ or WRITE access
+	Rank int // Requests with a lower rank are served first
+	Epoch int64 // Vote epoch of a received vote
 }
 
 type PriorityQueue []Pointer
@@ -12,6 +15,9 @@ type PriorityQueue []Pointer
 func (pq PriorityQueue) Len() int { return len(pq) }
 
 func (pq PriorityQueue) Less(i, j int) bool {
+	if pq[i].Rank != pq[j].Rank {
+		return pq[i].Rank < pq[j].Rank
+	}
 	if pq[i].ReqTime == pq[j].ReqTime {
 		return pq[i].ID < pq[j].ID
 	}
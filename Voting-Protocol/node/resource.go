@@ -0,0 +1,91 @@
+package node
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// State of the lock on a single named resource. Every node has a separate vote for every resource,
+// so requests for different resources never wait for each other.
+type Resource struct {
+	Name string
+	VotesReceived []Pointer // List of nodes that have voted for the node's request
+	Votes int // 1 if the node's vote for the resource is free
+	PrevReq Pointer // Writer that holds the vote of the node
+	ReadVotes []Pointer // Readers that share the vote of the node
+	Queue *PriorityQueue // contains all the nodes that have requested for the resource after the vote from the node was sent to another node.
+	Mode string // READ or WRITE access requested by the node
+	Request bool // If the node is requesting the resource
+	InCS bool // If the node holds the resource
+	ReqTime int // Request timestamp
+	Granted chan bool // Signalled once the node holds the resource
+	Entering bool // If the node holds enough votes and is recording its fencing token with the voters
+	Fence int64 // Fencing token of the current entry into the critical section
+	Epoch int64 // Vote epoch of the node, incremented every time it votes for a writer
+	LeaseExpiry time.Time // Time until which the node may use the resource, zero if leases are off
+	Leases map[int]Lease // Leases of the requesting nodes on their requests
+	Cancelled map[int]int // Timestamp of the last cancelled or expired request of each node
+	Certificates map[int]VoteCertificate // Certificates of the votes received for the node's requests, by voter
+	VoteLog map[int]map[int64]VoteCertificate // Certificates of the writer votes seen from each voter, by vote epoch
+}
+
+// Name of the i-th resource used by the bootstrap workload
+func ResourceName(i int) string {
+	return fmt.Sprintf("resource-%d", i)
+}
+
+// Function to get the state of a resource, creating it on first use. Must be called with the lock held.
+func (n *Node) resource(name string) *Resource {
+	if n.Resources == nil {
+		n.Resources = make(map[string]*Resource)
+	}
+
+	r, ok := n.Resources[name]
+	if !ok {
+		queue := make(PriorityQueue, 0)
+		heap.Init(&queue)
+		r = &Resource{Name: name, VotesReceived: []Pointer{}, Votes: 1, Queue: &queue, Mode: WRITE, Leases: make(map[int]Lease), Cancelled: make(map[int]int), Certificates: make(map[int]VoteCertificate), VoteLog: make(map[int]map[int64]VoteCertificate)}
+		n.Resources[name] = r
+	}
+	return r
+}
+
+// Function to acquire the locks on a set of resources. The locks are always taken in the order of their names,
+// so two nodes can never wait for each other's locks in a cycle.
+func (n *Node) AcquireAll(names []string, mode string) (map[string]int64, error) {
+	return n.AcquireAllContext(context.Background(), names, mode)
+}
+
+// Function to acquire the locks on a set of resources in order until they are all granted or the context is done.
+// The locks that were already granted are released if the context is done. Returns the fencing token of every resource.
+func (n *Node) AcquireAllContext(ctx context.Context, names []string, mode string) (map[string]int64, error) {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	sorted = slices.Compact(sorted)
+
+	tokens := make(map[string]int64)
+	for i, name := range sorted {
+		token, err := n.AcquireContext(ctx, name, mode)
+		if err != nil {
+			n.ReleaseAll(sorted[:i])
+			return nil, err
+		}
+		tokens[name] = token
+	}
+	return tokens, nil
+}
+
+// Function to release the locks on a set of resources in the reverse order of acquisition
+func (n *Node) ReleaseAll(names []string) {
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	sorted = slices.Compact(sorted)
+
+	for i := len(sorted) - 1; i >= 0; i-- {
+		n.Release(sorted[i])
+	}
+}
@@ -0,0 +1,44 @@
+package node
+
+import (
+	"common/safety"
+	"fmt"
+	"sort"
+)
+
+// Function to record that a node entered the critical section
+func (n *Node) NotifyEntered(message Message, reply *Message) error {
+	if err := n.verify("NotifyEntered", message); err != nil {
+		return err
+	}
+	group := safety.WRITER
+	if message.Mode == READ {
+		group = READ
+	}
+	err := n.Safety.Enter(n.K, message.Resource, message.ID, group)
+	if err != nil {
+		fmt.Printf("[NODE-%d] SAFETY VIOLATION: %s\n", n.ID, err)
+	}
+	return nil
+}
+
+// Function to record that a node left the critical section
+func (n *Node) notifyExited(ID int, resource string) {
+	n.Safety.Exit(resource, ID)
+}
+
+// Function to print the result of the safety check
+func (n *Node) PrintSafetyCheck() {
+	fmt.Println(n.Safety.Result(n.K))
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	if len(n.Equivocators) > 0 {
+		IDs := make([]int, 0, len(n.Equivocators))
+		for ID := range n.Equivocators {
+			IDs = append(IDs, ID)
+		}
+		sort.Ints(IDs)
+		fmt.Printf("Equivocating voters detected: %v\n", IDs)
+	}
+}
@@ -0,0 +1,293 @@
+package node
+
+import (
+	"common/admin"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Extensions of the files holding the Ed25519 keys of a node
+const (
+	SIGNING_KEY_EXT = ".ed25519"
+	PUBLIC_KEY_EXT = ".ed25519.pub"
+)
+
+// Signing keys of the node and public keys of every node, nil if signing is off
+var (
+	signingKey ed25519.PrivateKey
+	signerID int
+	publicKeys map[int]ed25519.PublicKey
+)
+
+// Signed statement of a voter that it voted for a request. A voter gives every writer it votes for a new vote
+// epoch, so two certificates of the same voter with the same epoch for different writers prove that it voted twice.
+type VoteCertificate struct {
+	Voter int
+	Candidate int // ID of the node the vote was cast for
+	ReqTime int // Timestamp of the request the vote was cast for
+	Mode string
+	Resource string
+	Epoch int64 // Vote epoch of the voter
+	Signature []byte
+}
+
+// Function to turn on signing. The node signs every message with its own key and only accepts messages that are
+// signed with the key of the node they claim to come from.
+func (n *Node) LoadSigningKeys(dir string) error {
+	name := admin.CERT_PREFIX + strconv.Itoa(n.ID)
+	data, err := os.ReadFile(filepath.Join(dir, name + SIGNING_KEY_EXT))
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("no key found in %s", name + SIGNING_KEY_EXT)
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return fmt.Errorf("%s is not an Ed25519 key", name + SIGNING_KEY_EXT)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, admin.CERT_PREFIX + "*" + PUBLIC_KEY_EXT))
+	if err != nil {
+		return err
+	}
+	keys := make(map[int]ed25519.PublicKey)
+	for _, file := range files {
+		ID, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), admin.CERT_PREFIX), PUBLIC_KEY_EXT))
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return fmt.Errorf("no key found in %s", file)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return err
+		}
+		public, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return fmt.Errorf("%s is not an Ed25519 key", file)
+		}
+		keys[ID] = public
+	}
+
+	signingKey = private
+	signerID = n.ID
+	publicKeys = keys
+	fmt.Printf("[NODE-%d] Signing is on with the public keys of %d nodes\n", n.ID, len(keys))
+	return nil
+}
+
+// Function to sign an outgoing message
+func sign(message *Message) {
+	message.Signer = signerID
+	message.Signature = ed25519.Sign(signingKey, message.signedBytes())
+}
+
+// Function to check that a message is signed by the node it claims to come from. A node may send messages in the
+// name of another node to itself.
+func (n *Node) verify(method string, message Message) error {
+	if publicKeys == nil {
+		return nil
+	}
+
+	var err error
+	key, ok := publicKeys[message.Signer]
+	switch {
+	case len(message.Signature) == 0:
+		err = fmt.Errorf("message from node %d is not signed", message.ID)
+	case !ok:
+		err = fmt.Errorf("no public key for node %d", message.Signer)
+	case !ed25519.Verify(key, message.signedBytes(), message.Signature):
+		err = fmt.Errorf("invalid signature of node %d", message.Signer)
+	case message.ID != message.Signer && message.Signer != n.ID:
+		err = fmt.Errorf("node %d signed a message of node %d", message.Signer, message.ID)
+	}
+	if err != nil {
+		fmt.Printf("[NODE-%d] Rejected %s %s: %s\n", n.ID, method, message.Type, err)
+	}
+	return err
+}
+
+// Function to issue the certificate of a vote for a request. Must be called with the lock held.
+func (n *Node) certify(r *Resource, request Pointer) []VoteCertificate {
+	if signingKey == nil {
+		return nil
+	}
+	cert := VoteCertificate{Voter: n.ID, Candidate: request.ID, ReqTime: request.ReqTime, Mode: request.Mode, Resource: r.Name, Epoch: r.Epoch}
+	cert.Signature = ed25519.Sign(signingKey, cert.signedBytes())
+	return []VoteCertificate{cert}
+}
+
+// Function to check the signature of a vote certificate
+func verifyCertificate(cert VoteCertificate) error {
+	key, ok := publicKeys[cert.Voter]
+	if !ok {
+		return fmt.Errorf("no public key for node %d", cert.Voter)
+	}
+	if !ed25519.Verify(key, cert.signedBytes(), cert.Signature) {
+		return fmt.Errorf("invalid certificate of node %d", cert.Voter)
+	}
+	return nil
+}
+
+// Function to check the certificate of a vote received by the node. Returns an error if the vote was not cast for
+// the current request of the node. Must be called with the lock held.
+func (n *Node) checkVote(r *Resource, message Message) error {
+	if publicKeys == nil {
+		return nil
+	}
+	if len(message.Certificates) != 1 {
+		return fmt.Errorf("vote of node %d has no certificate", message.ID)
+	}
+	cert := message.Certificates[0]
+	err := verifyCertificate(cert)
+	if err != nil {
+		return err
+	}
+	if cert.Voter != message.ID || cert.Candidate != n.ID || cert.ReqTime != r.ReqTime || cert.Resource != r.Name || cert.Epoch != message.Fence {
+		return fmt.Errorf("certificate of node %d was not issued for request %d of node %d", message.ID, r.ReqTime, n.ID)
+	}
+	n.recordVotes(r, message.Certificates)
+	return nil
+}
+
+// Function to count the voters that certified a writer's request, after checking the certificates for
+// equivocation. Must be called with the lock held.
+func (n *Node) checkProof(r *Resource, writer int, reqTime int, certs []VoteCertificate) int {
+	n.recordVotes(r, certs)
+
+	voters := make(map[int]bool)
+	for _, cert := range certs {
+		if verifyCertificate(cert) == nil && cert.Candidate == writer && cert.ReqTime == reqTime && cert.Resource == r.Name && cert.Mode == WRITE {
+			voters[cert.Voter] = true
+		}
+	}
+	return len(voters)
+}
+
+// Function to record the certificates of writer votes and report every voter that voted for two writers with
+// the same epoch. Must be called with the lock held.
+func (n *Node) recordVotes(r *Resource, certs []VoteCertificate) {
+	for _, cert := range certs {
+		if cert.Mode != WRITE || cert.Resource != r.Name || verifyCertificate(cert) != nil {
+			continue
+		}
+		if r.VoteLog[cert.Voter] == nil {
+			r.VoteLog[cert.Voter] = make(map[int64]VoteCertificate)
+		}
+		seen, ok := r.VoteLog[cert.Voter][cert.Epoch]
+		if !ok {
+			r.VoteLog[cert.Voter][cert.Epoch] = cert
+			continue
+		}
+		if seen.Candidate != cert.Candidate || seen.ReqTime != cert.ReqTime {
+			fmt.Printf("[NODE-%d] Node %d voted for node %d and node %d with epoch %d of %s. Reporting it to the bootstrap node\n", n.ID, cert.Voter, seen.Candidate, cert.Candidate, cert.Epoch, r.Name)
+			n.Clock++
+			go n.reportEquivocation(Message{ID: n.ID, IP: n.IP, Resource: r.Name, Clock: n.Clock, Certificates: []VoteCertificate{seen, cert}})
+		}
+	}
+}
+
+// Function to send the proof of an equivocation to the bootstrap node
+func (n *Node) reportEquivocation(message Message) {
+	_, err := CallByRPC(n.Bootstrap, "Node.ReportEquivocation", message)
+	if err != nil {
+		fmt.Printf("[NODE-%d] Error occurred while reporting an equivocation: %s\n", n.ID, err)
+	}
+}
+
+// Function to record a voter that was proven to vote for two writers with the same epoch. Only used by the bootstrap node.
+func (n *Node) ReportEquivocation(message Message, reply *Message) error {
+	err := n.verify("ReportEquivocation", message)
+	if err != nil {
+		return err
+	}
+	if len(message.Certificates) != 2 {
+		return fmt.Errorf("an equivocation is proven by two certificates")
+	}
+	a, b := message.Certificates[0], message.Certificates[1]
+	if verifyCertificate(a) != nil || verifyCertificate(b) != nil || a.Voter != b.Voter || a.Epoch != b.Epoch || a.Resource != b.Resource || (a.Candidate == b.Candidate && a.ReqTime == b.ReqTime) {
+		return fmt.Errorf("the certificates do not prove an equivocation")
+	}
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	if n.Equivocators == nil {
+		n.Equivocators = make(map[int]bool)
+	}
+	if !n.Equivocators[a.Voter] {
+		fmt.Printf("[NODE-%d] EQUIVOCATION: node %d voted for node %d and node %d with epoch %d of %s (reported by node %d)\n", n.ID, a.Voter, a.Candidate, b.Candidate, a.Epoch, a.Resource, message.ID)
+	}
+	n.Equivocators[a.Voter] = true
+	return nil
+}
+
+// Function to get the bytes covered by the signature of a message. Every field except the signature is covered.
+func (m Message) signedBytes() []byte {
+	b := []byte("message")
+	b = appendString(b, m.Type)
+	b = appendInt(b, int64(m.ID))
+	b = appendString(b, m.IP)
+	b = appendInt(b, int64(m.ReqTime))
+	b = appendInt(b, int64(m.Clock))
+	b = appendInt(b, int64(m.NumRequests))
+	b = appendInt(b, int64(m.K))
+	b = appendString(b, m.Mode)
+	b = appendInt(b, int64(m.NumReaders))
+	b = appendString(b, m.Policy)
+	b = appendInt(b, int64(m.Timeout))
+	b = appendInt(b, int64(m.TTL))
+	b = appendInt(b, m.Fence)
+	b = appendString(b, m.Resource)
+	b = appendInt(b, int64(m.To))
+	b = appendInt(b, int64(m.NumResources))
+	b = appendInt(b, int64(m.ResourcesPerRequest))
+	b = appendInt(b, int64(m.Signer))
+	b = appendInt(b, int64(len(m.Certificates)))
+	for _, cert := range m.Certificates {
+		b = append(b, cert.signedBytes()...)
+		b = appendString(b, string(cert.Signature))
+	}
+	b = appendString(b, m.Secret)
+	return b
+}
+
+// Function to get the bytes covered by the signature of a vote certificate
+func (c VoteCertificate) signedBytes() []byte {
+	b := []byte("vote")
+	b = appendInt(b, int64(c.Voter))
+	b = appendInt(b, int64(c.Candidate))
+	b = appendInt(b, int64(c.ReqTime))
+	b = appendString(b, c.Mode)
+	b = appendString(b, c.Resource)
+	b = appendInt(b, c.Epoch)
+	return b
+}
+
+func appendInt(b []byte, v int64) []byte {
+	return binary.BigEndian.AppendUint64(b, uint64(v))
+}
+
+// Strings are prefixed with their length, so that no two messages have the same bytes
+func appendString(b []byte, s string) []byte {
+	b = appendInt(b, int64(len(s)))
+	return append(b, s...)
+}
@@ -0,0 +1,213 @@
+package node
+
+import (
+	"common/address"
+	"crypto/ed25519"
+	"net/rpc"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Function to turn on signing for the node self with new keys for the given nodes, and turn it off again at the end of the test
+func useKeys(t *testing.T, self int, IDs ...int) map[int]ed25519.PrivateKey {
+	keys := make(map[int]ed25519.PrivateKey)
+	publics := make(map[int]ed25519.PublicKey)
+	for _, ID := range IDs {
+		public, private, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[ID], publics[ID] = private, public
+	}
+	signingKey, signerID, publicKeys = keys[self], self, publics
+	t.Cleanup(func() {
+		signingKey, signerID, publicKeys = nil, 0, nil
+	})
+	return keys
+}
+
+// Function to sign a message as the node signer with the given key
+func signAs(key ed25519.PrivateKey, signer int, message Message) Message {
+	message.Signer = signer
+	message.Signature = ed25519.Sign(key, message.signedBytes())
+	return message
+}
+
+// Function to sign a vote certificate with the given key
+func certifyAs(key ed25519.PrivateKey, cert VoteCertificate) VoteCertificate {
+	cert.Signature = ed25519.Sign(key, cert.signedBytes())
+	return cert
+}
+
+// A message is only accepted with a valid signature of the node it claims to come from
+func TestVerifyRejectsForgedMessages(t *testing.T) {
+	keys := useKeys(t, 1, 1, 2, 3)
+	_, unknown, _ := ed25519.GenerateKey(nil)
+	n := &Node{ID: 1}
+	vote := Message{Type: VOTE, ID: 2, To: 1, Resource: "resource-0", Fence: 4}
+
+	tampered := signAs(keys[2], 2, vote)
+	tampered.Fence = 5
+	cases := []struct {
+		name string
+		message Message
+		allowed bool
+	}{
+		{"a message signed by its sender", signAs(keys[2], 2, vote), true},
+		{"a message of the node to itself", signAs(keys[1], 1, vote), true},
+		{"an unsigned message", vote, false},
+		{"a message signed with the key of another node", signAs(keys[3], 2, vote), false},
+		{"a message changed after signing", tampered, false},
+		{"a message signed by another node", signAs(keys[3], 3, vote), false},
+		{"a message signed with an unknown key", signAs(unknown, 9, vote), false},
+	}
+	for _, c := range cases {
+		err := n.verify("ReceiveMessage", c.message)
+		if (err == nil) != c.allowed {
+			t.Errorf("%s: verify = %v, want allowed %v", c.name, err, c.allowed)
+		}
+	}
+}
+
+// A vote is only counted with a valid certificate of the voter for the current request of the node
+func TestCheckVoteRejectsForgedCertificates(t *testing.T) {
+	keys := useKeys(t, 1, 1, 2, 3)
+	n := &Node{ID: 1}
+	r := n.resource("resource-0")
+	r.ReqTime = 5
+	vote := Message{Type: VOTE, ID: 2, To: 1, Resource: r.Name, Fence: 4}
+	cert := VoteCertificate{Voter: 2, Candidate: 1, ReqTime: 5, Mode: WRITE, Resource: r.Name, Epoch: 4}
+
+	forged := func(change func(c *VoteCertificate)) []VoteCertificate {
+		c := cert
+		change(&c)
+		return []VoteCertificate{certifyAs(keys[2], c)}
+	}
+	cases := []struct {
+		name string
+		certs []VoteCertificate
+	}{
+		{"no certificate", nil},
+		{"a certificate signed with the key of another node", []VoteCertificate{certifyAs(keys[3], cert)}},
+		{"a certificate of another voter", []VoteCertificate{certifyAs(keys[3], VoteCertificate{Voter: 3, Candidate: 1, ReqTime: 5, Mode: WRITE, Resource: r.Name, Epoch: 4})}},
+		{"a certificate for another node", forged(func(c *VoteCertificate) { c.Candidate = 3 })},
+		{"a certificate for an older request", forged(func(c *VoteCertificate) { c.ReqTime = 4 })},
+		{"a certificate for another resource", forged(func(c *VoteCertificate) { c.Resource = "resource-1" })},
+		{"a certificate with another epoch", forged(func(c *VoteCertificate) { c.Epoch = 3 })},
+		{"two certificates", []VoteCertificate{certifyAs(keys[2], cert), certifyAs(keys[2], cert)}},
+	}
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	for _, c := range cases {
+		vote.Certificates = c.certs
+		if err := n.checkVote(r, vote); err == nil {
+			t.Errorf("%s: the vote was accepted", c.name)
+		}
+	}
+	vote.Certificates = []VoteCertificate{certifyAs(keys[2], cert)}
+	if err := n.checkVote(r, vote); err != nil {
+		t.Errorf("the certified vote was rejected: %s", err)
+	}
+}
+
+// Only the valid certificates of distinct voters for the writer's request count towards its proof
+func TestCheckProofCountsOnlyValidCertificates(t *testing.T) {
+	keys := useKeys(t, 1, 1, 2, 3, 4, 5)
+	n := &Node{ID: 1}
+	r := n.resource("resource-0")
+	cert := func(voter int, key int, candidate int, mode string) VoteCertificate {
+		return certifyAs(keys[key], VoteCertificate{Voter: voter, Candidate: candidate, ReqTime: 5, Mode: mode, Resource: r.Name, Epoch: int64(voter)})
+	}
+	certs := []VoteCertificate{
+		cert(3, 3, 2, WRITE),
+		cert(3, 3, 2, WRITE), // The same vote twice
+		cert(4, 3, 2, WRITE), // Forged by node 3
+		cert(5, 5, 2, READ),
+		cert(1, 1, 4, WRITE), // For another writer
+	}
+
+	n.Lock.Lock()
+	defer n.Lock.Unlock()
+	if voters := n.checkProof(r, 2, 5, certs); voters != 1 {
+		t.Errorf("checkProof counted %d voters, want 1", voters)
+	}
+}
+
+// Two valid certificates of a voter with the same epoch for different writers are reported to the bootstrap node,
+// which records the voter. Certificates that do not prove it are refused.
+func TestEquivocationIsReported(t *testing.T) {
+	keys := useKeys(t, 4, 0, 1, 2, 3, 4)
+	sock := address.UNIX_PREFIX + filepath.Join(t.TempDir(), "0.sock")
+	bootstrap := &Node{ID: 0, IP: sock}
+	server := rpc.NewServer()
+	server.Register(bootstrap)
+	listener, err := address.Listen(sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go server.Accept(listener)
+
+	first := VoteCertificate{Voter: 3, Candidate: 1, ReqTime: 5, Mode: WRITE, Resource: "resource-0", Epoch: 7}
+	second := first
+	second.Candidate, second.ReqTime = 2, 6
+	later := first
+	later.Epoch = 8
+
+	report := Message{ID: 4, Resource: "resource-0"}
+	cases := []struct {
+		name string
+		certs []VoteCertificate
+	}{
+		{"one certificate", []VoteCertificate{certifyAs(keys[3], first)}},
+		{"the same vote twice", []VoteCertificate{certifyAs(keys[3], first), certifyAs(keys[3], first)}},
+		{"votes with different epochs", []VoteCertificate{certifyAs(keys[3], first), certifyAs(keys[3], later)}},
+		{"a forged second vote", []VoteCertificate{certifyAs(keys[3], first), certifyAs(keys[2], second)}},
+	}
+	for _, c := range cases {
+		report.Certificates = c.certs
+		if err := bootstrap.ReportEquivocation(signAs(keys[4], 4, report), &Message{}); err == nil {
+			t.Errorf("%s: the report was accepted", c.name)
+		}
+	}
+	report.Certificates = []VoteCertificate{certifyAs(keys[3], first), certifyAs(keys[3], second)}
+	if err := bootstrap.ReportEquivocation(report, &Message{}); err == nil {
+		t.Errorf("an unsigned report was accepted")
+	}
+	if len(bootstrap.Equivocators) != 0 {
+		t.Fatalf("voters were recorded without a proof: %v", bootstrap.Equivocators)
+	}
+
+	// Node 4 sees a forged second vote of node 3, then a real one
+	n := &Node{ID: 4, Bootstrap: sock}
+	r := n.resource("resource-0")
+	n.Lock.Lock()
+	n.checkProof(r, 1, 5, []VoteCertificate{certifyAs(keys[3], first)})
+	n.checkProof(r, 2, 6, []VoteCertificate{certifyAs(keys[2], second)})
+	n.Lock.Unlock()
+	time.Sleep(100 * time.Millisecond)
+	bootstrap.Lock.Lock()
+	recorded := len(bootstrap.Equivocators)
+	bootstrap.Lock.Unlock()
+	if recorded != 0 {
+		t.Fatalf("a forged vote was reported as an equivocation")
+	}
+
+	n.Lock.Lock()
+	n.checkProof(r, 2, 6, []VoteCertificate{certifyAs(keys[3], second)})
+	n.Lock.Unlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		bootstrap.Lock.Lock()
+		equivocator := bootstrap.Equivocators[3]
+		bootstrap.Lock.Unlock()
+		if equivocator {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the bootstrap node did not record the equivocation of node 3")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
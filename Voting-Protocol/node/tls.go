@@ -0,0 +1,188 @@
+package node
+
+import (
+	"bufio"
+	"common/address"
+	"common/admin"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"net"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"google.golang.org/grpc/credentials"
+	grpcpeer "google.golang.org/grpc/peer"
+)
+
+// TLS settings of the node, nil if mutual TLS is off
+var (
+	serverTLS *tls.Config
+	clientTLS *tls.Config
+)
+
+// Methods that only read the state of the node, so any caller with a verified certificate may use them
+var readOnly = map[string]bool{
+	"Node.GetState": true,
+}
+
+// Function to turn on mutual TLS. The node presents the certificate for its ID, and only accepts
+// connections from nodes with a certificate signed by the same CA.
+func (n *Node) LoadTLS(dir string) error {
+	caPEM, err := os.ReadFile(filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		return err
+	}
+	ca := x509.NewCertPool()
+	if !ca.AppendCertsFromPEM(caPEM) {
+		return fmt.Errorf("no certificate found in %s", filepath.Join(dir, "ca.crt"))
+	}
+
+	name := admin.CERT_PREFIX + strconv.Itoa(n.ID)
+	cert, err := tls.LoadX509KeyPair(filepath.Join(dir, name + ".crt"), filepath.Join(dir, name + ".key"))
+	if err != nil {
+		return err
+	}
+
+	serverTLS = &tls.Config{Certificates: []tls.Certificate{cert}, ClientCAs: ca, ClientAuth: tls.RequireAndVerifyClientCert, MinVersion: tls.VersionTLS13}
+	clientTLS = &tls.Config{Certificates: []tls.Certificate{cert}, RootCAs: ca, MinVersion: tls.VersionTLS13}
+	fmt.Printf("[NODE-%d] Mutual TLS is on with the certificate of %s\n", n.ID, name)
+	return nil
+}
+
+// Function to check that a message comes from the node it claims to come from, and that admin calls come from
+// a caller whose role allows them
+func (n *Node) checkSender(peer admin.Identity, method string, message Message) error {
+	if peer.ID == n.ID {
+		// Calls of the node to itself
+		return nil
+	}
+	if admin.IsAdmin(method) {
+		return admin.CheckRole(peer, method, message.ID, selfService)
+	}
+	if readOnly[method] {
+		return nil
+	}
+	if peer.Role == admin.OPERATOR_ROLE {
+		// An operator tool has no node ID, so it may only read the state and make admin calls
+		return fmt.Errorf("%s may not call %s", peer, method)
+	}
+	if message.ID != peer.ID {
+		return fmt.Errorf("%s claims to be node %d", peer, message.ID)
+	}
+	return nil
+}
+
+// Function to serve the RPC calls on a connection. With mutual TLS, every call is checked against the
+// certificate of the peer.
+func (n *Node) serveConn(conn net.Conn) {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		rpc.ServeConn(conn)
+		return
+	}
+
+	err := tlsConn.HandshakeContext(context.Background())
+	if err != nil {
+		fmt.Printf("[NODE-%d] Rejected a connection from %s: %s\n", n.ID, conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+	peer, err := admin.Peer(tlsConn.ConnectionState())
+	if err != nil {
+		fmt.Printf("[NODE-%d] Rejected a connection from %s: %s\n", n.ID, conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+
+	buf := bufio.NewWriter(conn)
+	rpc.ServeCodec(&authCodec{n: n, peer: peer, rwc: conn, dec: gob.NewDecoder(conn), enc: gob.NewEncoder(buf), encBuf: buf})
+}
+
+// Function to dial another node, over TLS if mutual TLS is on
+func dial(IP string) (*rpc.Client, error) {
+	conn, err := address.Dial(IP, 0)
+	if err != nil {
+		return nil, err
+	}
+	if clientTLS == nil {
+		return rpc.NewClient(conn), nil
+	}
+	config := clientTLS.Clone()
+	config.ServerName = address.ServerName(IP)
+	tlsConn := tls.Client(conn, config)
+	err = tlsConn.Handshake()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return rpc.NewClient(tlsConn), nil
+}
+
+// Function to get the admin.Identity of the peer of a gRPC stream
+func grpcPeerID(ctx context.Context) (admin.Identity, error) {
+	p, ok := grpcpeer.FromContext(ctx)
+	if !ok {
+		return admin.Identity{}, fmt.Errorf("unknown peer")
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return admin.Identity{}, fmt.Errorf("peer did not use TLS")
+	}
+	return admin.Peer(info.State)
+}
+
+// Gob codec of net/rpc that rejects the calls whose message does not come from the authenticated peer
+type authCodec struct {
+	n *Node
+	peer admin.Identity // Identity in the certificate of the peer
+	method string // Method of the call being read
+	rwc io.ReadWriteCloser
+	dec *gob.Decoder
+	enc *gob.Encoder
+	encBuf *bufio.Writer
+}
+
+func (c *authCodec) ReadRequestHeader(r *rpc.Request) error {
+	err := c.dec.Decode(r)
+	c.method = r.ServiceMethod
+	return err
+}
+
+func (c *authCodec) ReadRequestBody(body any) error {
+	err := c.dec.Decode(body)
+	if err != nil {
+		return err
+	}
+	if message, ok := body.(*Message); ok {
+		err = c.n.checkSender(c.peer, c.method, *message)
+		if err != nil {
+			fmt.Printf("[NODE-%d] Rejected %s from %s: %s\n", c.n.ID, c.method, c.peer, err)
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *authCodec) WriteResponse(r *rpc.Response, body any) error {
+	err := c.enc.Encode(r)
+	if err == nil {
+		err = c.enc.Encode(body)
+	}
+	if err == nil {
+		err = c.encBuf.Flush()
+	}
+	if err != nil {
+		c.Close()
+	}
+	return err
+}
+
+func (c *authCodec) Close() error {
+	return c.rwc.Close()
+}